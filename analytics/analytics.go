@@ -0,0 +1,357 @@
+// Package analytics provides rollups of time entries: totals grouped by
+// day, week, month, project, client, tag and billable flag.
+//
+// Time based groupings use the configured location for bucketing, and
+// time entries which span midnight are split between the days (weeks,
+// months) they cover. Running time entries count until the configured
+// current time.
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// Grouping defines how time entries are grouped.
+type Grouping string
+
+// The available groupings.
+const (
+	ByDay      Grouping = "day"
+	ByWeek     Grouping = "week"
+	ByMonth    Grouping = "month"
+	ByProject  Grouping = "project"
+	ByClient   Grouping = "client"
+	ByTag      Grouping = "tag"
+	ByBillable Grouping = "billable"
+)
+
+// Groupings returns all available groupings.
+func Groupings() []Grouping {
+	return []Grouping{ByDay, ByWeek, ByMonth, ByProject, ByClient, ByTag, ByBillable}
+}
+
+// ParseGrouping returns the grouping with the given name.
+func ParseGrouping(name string) (Grouping, error) {
+	for _, grouping := range Groupings() {
+		if string(grouping) == name {
+			return grouping, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown grouping %q (available: day, week, month, project, client, tag, billable)", name)
+}
+
+// The labels of time entries without project, client or tags.
+const (
+	NoProject = "(no project)"
+	NoClient  = "(no client)"
+	NoTag     = "(no tag)"
+)
+
+// Options contains the settings for the rollups.
+type Options struct {
+	// Location is used for bucketing by day, week and month. Defaults to time.Local.
+	Location *time.Location
+
+	// WeekStart contains the first day of the week. Defaults to Monday.
+	WeekStart *time.Weekday
+
+	// Projects and Clients are used for resolving the names
+	// and the client of the projects of the time entries.
+	Projects []model.Project
+	Clients  []model.Client
+
+	// Now is used as the end of running time entries. Defaults to the current time.
+	Now time.Time
+}
+
+// Total contains the tracked time of a single group.
+type Total struct {
+	// Key identifies the group (e.g. "2016-09-06" or a project ID).
+	Key string
+
+	// Label contains the display name of the group.
+	Label string
+
+	// Duration contains the tracked time.
+	Duration time.Duration
+
+	// Billable contains the billable part of the tracked time.
+	Billable time.Duration
+
+	// Entries contains the number of time entries which contributed to the group.
+	Entries int
+}
+
+// Summary contains the totals of all groupings.
+type Summary struct {
+	Total    Total
+	Days     []Total
+	Weeks    []Total
+	Months   []Total
+	Projects []Total
+	Clients  []Total
+	Tags     []Total
+	Billable []Total
+}
+
+// Summarize returns the totals of all groupings for the given time entries.
+func Summarize(timeEntries []model.TimeEntry, options Options) Summary {
+	analyzer := newAnalyzer(options)
+
+	return Summary{
+		Total:    analyzer.sum(timeEntries),
+		Days:     analyzer.group(timeEntries, ByDay),
+		Weeks:    analyzer.group(timeEntries, ByWeek),
+		Months:   analyzer.group(timeEntries, ByMonth),
+		Projects: analyzer.group(timeEntries, ByProject),
+		Clients:  analyzer.group(timeEntries, ByClient),
+		Tags:     analyzer.group(timeEntries, ByTag),
+		Billable: analyzer.group(timeEntries, ByBillable),
+	}
+}
+
+// Sum returns the total of all given time entries.
+func Sum(timeEntries []model.TimeEntry, options Options) Total {
+	return newAnalyzer(options).sum(timeEntries)
+}
+
+// Group returns the totals of the given time entries grouped by the given grouping.
+// Time based groups are sorted chronologically, all others by duration (longest first).
+// Time entries with several tags count for each of their tags.
+func Group(timeEntries []model.TimeEntry, grouping Grouping, options Options) []Total {
+	return newAnalyzer(options).group(timeEntries, grouping)
+}
+
+// analyzer contains the resolved options of a rollup.
+type analyzer struct {
+	location  *time.Location
+	weekStart time.Weekday
+	now       time.Time
+	projects  map[int]model.Project
+	clients   map[int]model.Client
+}
+
+// newAnalyzer creates a new analyzer for the given options.
+func newAnalyzer(options Options) *analyzer {
+	analyzer := &analyzer{
+		location:  options.Location,
+		weekStart: time.Monday,
+		now:       options.Now,
+		projects:  make(map[int]model.Project),
+		clients:   make(map[int]model.Client),
+	}
+
+	if analyzer.location == nil {
+		analyzer.location = time.Local
+	}
+
+	if options.WeekStart != nil {
+		analyzer.weekStart = *options.WeekStart
+	}
+
+	if analyzer.now.IsZero() {
+		analyzer.now = time.Now()
+	}
+
+	for _, project := range options.Projects {
+		analyzer.projects[project.ID] = project
+	}
+
+	for _, client := range options.Clients {
+		analyzer.clients[client.ID] = client
+	}
+
+	return analyzer
+}
+
+// sum returns the total of the given time entries.
+func (analyzer *analyzer) sum(timeEntries []model.TimeEntry) Total {
+	total := Total{Key: "total", Label: "Total"}
+	for _, timeEntry := range timeEntries {
+		duration := analyzer.duration(timeEntry)
+		total.Duration += duration
+		if timeEntry.Billable {
+			total.Billable += duration
+		}
+
+		total.Entries++
+	}
+
+	return total
+}
+
+// group returns the totals of the given time entries for the given grouping.
+func (analyzer *analyzer) group(timeEntries []model.TimeEntry, grouping Grouping) []Total {
+	totals := make(map[string]*Total)
+	add := func(key, label string, duration time.Duration, billable bool, entries map[string]bool) {
+		total, exists := totals[key]
+		if !exists {
+			total = &Total{Key: key, Label: label}
+			totals[key] = total
+		}
+
+		total.Duration += duration
+		if billable {
+			total.Billable += duration
+		}
+
+		if !entries[key] {
+			entries[key] = true
+			total.Entries++
+		}
+	}
+
+	for _, timeEntry := range timeEntries {
+		entries := make(map[string]bool)
+		switch grouping {
+		case ByDay, ByWeek, ByMonth:
+			for _, segment := range analyzer.split(timeEntry) {
+				key, label := analyzer.bucket(segment.start, grouping)
+				add(key, label, segment.duration, timeEntry.Billable, entries)
+			}
+
+		default:
+			duration := analyzer.duration(timeEntry)
+			for _, group := range analyzer.groups(timeEntry, grouping) {
+				add(group[0], group[1], duration, timeEntry.Billable, entries)
+			}
+		}
+	}
+
+	var result []Total
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+
+	switch grouping {
+	case ByDay, ByWeek, ByMonth:
+		sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	default:
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Duration != result[j].Duration {
+				return result[i].Duration > result[j].Duration
+			}
+
+			return result[i].Label < result[j].Label
+		})
+	}
+
+	return result
+}
+
+// groups returns the key and label of the groups of the given
+// time entry for the given non time based grouping.
+func (analyzer *analyzer) groups(timeEntry model.TimeEntry, grouping Grouping) [][2]string {
+	switch grouping {
+	case ByProject:
+		project, exists := analyzer.projects[timeEntry.Pid]
+		if timeEntry.Pid == 0 {
+			return [][2]string{{"0", NoProject}}
+		}
+
+		if !exists {
+			project.Name = fmt.Sprintf("Project %d", timeEntry.Pid)
+		}
+
+		return [][2]string{{strconv.Itoa(timeEntry.Pid), project.Name}}
+
+	case ByClient:
+		clientID := analyzer.projects[timeEntry.Pid].ClientID
+		client, exists := analyzer.clients[clientID]
+		if clientID == 0 {
+			return [][2]string{{"0", NoClient}}
+		}
+
+		if !exists {
+			client.Name = fmt.Sprintf("Client %d", clientID)
+		}
+
+		return [][2]string{{strconv.Itoa(clientID), client.Name}}
+
+	case ByTag:
+		if len(timeEntry.Tags) == 0 {
+			return [][2]string{{"", NoTag}}
+		}
+
+		var groups [][2]string
+		for _, tag := range timeEntry.Tags {
+			groups = append(groups, [2]string{tag, tag})
+		}
+
+		return groups
+
+	case ByBillable:
+		if timeEntry.Billable {
+			return [][2]string{{"billable", "Billable"}}
+		}
+
+		return [][2]string{{"non-billable", "Non-billable"}}
+	}
+
+	return nil
+}
+
+// segment contains the part of a time entry within a single day.
+type segment struct {
+	start    time.Time
+	duration time.Duration
+}
+
+// split splits the given time entry at midnight in the analyzer's location.
+func (analyzer *analyzer) split(timeEntry model.TimeEntry) []segment {
+	start := timeEntry.Start.In(analyzer.location)
+	stop := start.Add(analyzer.duration(timeEntry))
+
+	var segments []segment
+	for start.Before(stop) {
+		year, month, day := start.Date()
+		midnight := time.Date(year, month, day+1, 0, 0, 0, 0, analyzer.location)
+		end := stop
+		if midnight.Before(stop) {
+			end = midnight
+		}
+
+		segments = append(segments, segment{start: start, duration: end.Sub(start)})
+		start = end
+	}
+
+	return segments
+}
+
+// bucket returns the key and label of the day, week or month of the given time.
+func (analyzer *analyzer) bucket(t time.Time, grouping Grouping) (string, string) {
+	year, month, day := t.Date()
+	switch grouping {
+	case ByWeek:
+		offset := (int(t.Weekday()) - int(analyzer.weekStart) + 7) % 7
+		weekStart := time.Date(year, month, day-offset, 0, 0, 0, 0, analyzer.location)
+		return weekStart.Format("2006-01-02"), "Week of " + weekStart.Format("2006-01-02")
+
+	case ByMonth:
+		monthStart := time.Date(year, month, 1, 0, 0, 0, 0, analyzer.location)
+		return monthStart.Format("2006-01"), monthStart.Format("January 2006")
+	}
+
+	return t.Format("2006-01-02"), t.Format("Mon 2006-01-02")
+}
+
+// duration returns the duration of the given time entry.
+// Running time entries count until now.
+func (analyzer *analyzer) duration(timeEntry model.TimeEntry) time.Duration {
+	if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() {
+		if analyzer.now.Before(timeEntry.Start) {
+			return 0
+		}
+
+		return analyzer.now.Sub(timeEntry.Start)
+	}
+
+	return timeEntry.Stop.Sub(timeEntry.Start)
+}
@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+var berlin, _ = time.LoadLocation("Europe/Berlin")
+
+// testEntry returns a stopped time entry starting at the given time in Berlin.
+func testEntry(pid int, start time.Time, duration time.Duration, billable bool, tags ...string) model.TimeEntry {
+	return model.TimeEntry{Pid: pid, Start: start, Stop: start.Add(duration), Duration: int(duration.Seconds()), Billable: billable, Tags: tags}
+}
+
+// testOptions returns options with a project of a client and a project without client.
+func testOptions() Options {
+	return Options{
+		Location: berlin,
+		Projects: []model.Project{{ID: 1, ClientID: 10, Name: "Website"}, {ID: 2, Name: "Internal"}},
+		Clients:  []model.Client{{ID: 10, Name: "Acme"}},
+		Now:      time.Date(2016, 9, 30, 12, 0, 0, 0, berlin),
+	}
+}
+
+func Test_Group_ByDay_EntrySpanningMidnightIsSplit(t *testing.T) {
+	// arrange
+	timeEntries := []model.TimeEntry{
+		testEntry(1, time.Date(2016, 9, 6, 22, 0, 0, 0, berlin), 3*time.Hour, true),
+	}
+
+	// act
+	totals := Group(timeEntries, ByDay, testOptions())
+
+	// assert
+	if len(totals) != 2 || totals[0].Key != "2016-09-06" || totals[0].Duration != 2*time.Hour || totals[1].Key != "2016-09-07" || totals[1].Duration != time.Hour {
+		t.Fail()
+		t.Logf("Group should have split the entry at midnight: %+v", totals)
+	}
+}
+
+func Test_Group_ByDay_BucketsUseTheLocation(t *testing.T) {
+	// arrange
+	// 23:30 UTC is 01:30 on the next day in Berlin (CEST)
+	timeEntries := []model.TimeEntry{
+		testEntry(1, time.Date(2016, 9, 6, 23, 30, 0, 0, time.UTC), time.Hour, false),
+	}
+
+	// act
+	totals := Group(timeEntries, ByDay, testOptions())
+
+	// assert
+	if len(totals) != 1 || totals[0].Key != "2016-09-07" {
+		t.Fail()
+		t.Logf("Group should have bucketed the entry on the local day: %+v", totals)
+	}
+}
+
+func Test_Group_ByWeek_WeeksStartOnMonday(t *testing.T) {
+	// arrange
+	timeEntries := []model.TimeEntry{
+		testEntry(1, time.Date(2016, 9, 4, 10, 0, 0, 0, berlin), time.Hour, false), // Sunday
+		testEntry(1, time.Date(2016, 9, 5, 10, 0, 0, 0, berlin), time.Hour, false), // Monday
+		testEntry(1, time.Date(2016, 9, 11, 10, 0, 0, 0, berlin), time.Hour, false),
+	}
+
+	// act
+	totals := Group(timeEntries, ByWeek, testOptions())
+
+	// assert
+	if len(totals) != 2 || totals[0].Key != "2016-08-29" || totals[1].Key != "2016-09-05" || totals[1].Duration != 2*time.Hour || totals[1].Entries != 2 {
+		t.Fail()
+		t.Logf("Group should have grouped the entries by weeks starting on Monday: %+v", totals)
+	}
+}
+
+func Test_Group_ByMonth_EntrySpanningMonthEndIsSplit(t *testing.T) {
+	// arrange
+	timeEntries := []model.TimeEntry{
+		testEntry(1, time.Date(2016, 9, 30, 23, 0, 0, 0, berlin), 2*time.Hour, false),
+	}
+
+	// act
+	totals := Group(timeEntries, ByMonth, testOptions())
+
+	// assert
+	if len(totals) != 2 || totals[0].Key != "2016-09" || totals[1].Key != "2016-10" || totals[0].Entries != 1 || totals[1].Label != "October 2016" {
+		t.Fail()
+		t.Logf("Group should have split the entry between the months: %+v", totals)
+	}
+}
+
+func Test_Group_ByProjectAndClient_NamesAreResolved(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, berlin)
+	timeEntries := []model.TimeEntry{
+		testEntry(1, start, 2*time.Hour, true),
+		testEntry(2, start, time.Hour, false),
+		testEntry(0, start, 30*time.Minute, false),
+	}
+
+	// act
+	projects := Group(timeEntries, ByProject, testOptions())
+	clients := Group(timeEntries, ByClient, testOptions())
+
+	// assert
+	if len(projects) != 3 || projects[0].Label != "Website" || projects[0].Billable != 2*time.Hour || projects[2].Label != NoProject {
+		t.Fail()
+		t.Logf("Group should have returned the totals per project: %+v", projects)
+	}
+
+	if len(clients) != 2 || clients[0].Label != "Acme" || clients[1].Label != NoClient || clients[1].Duration != 90*time.Minute {
+		t.Fail()
+		t.Logf("Group should have returned the totals per client: %+v", clients)
+	}
+}
+
+func Test_Group_ByTag_EntriesCountForEachTag(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, berlin)
+	timeEntries := []model.TimeEntry{
+		testEntry(1, start, 2*time.Hour, false, "design", "review"),
+		testEntry(1, start, time.Hour, false, "design"),
+		testEntry(1, start, time.Hour, false),
+	}
+
+	// act
+	totals := Group(timeEntries, ByTag, testOptions())
+
+	// assert
+	if len(totals) != 3 || totals[0].Key != "design" || totals[0].Duration != 3*time.Hour || totals[1].Key != "review" || totals[2].Label != NoTag {
+		t.Fail()
+		t.Logf("Group should have returned the totals per tag: %+v", totals)
+	}
+}
+
+func Test_Summarize_RunningEntryCountsUntilNow(t *testing.T) {
+	// arrange
+	options := testOptions()
+	start := options.Now.Add(-90 * time.Minute)
+	timeEntries := []model.TimeEntry{
+		{Pid: 1, Start: start, Duration: -int(start.Unix()), Billable: true},
+		testEntry(2, time.Date(2016, 9, 6, 8, 0, 0, 0, berlin), time.Hour, false),
+	}
+
+	// act
+	summary := Summarize(timeEntries, options)
+
+	// assert
+	if summary.Total.Duration != 150*time.Minute || summary.Total.Billable != 90*time.Minute || summary.Total.Entries != 2 {
+		t.Fail()
+		t.Logf("Summarize should have counted the running entry until now: %+v", summary.Total)
+	}
+
+	if len(summary.Billable) != 2 || summary.Billable[0].Key != "billable" {
+		t.Fail()
+		t.Logf("Summarize should have grouped the entries by billable flag: %+v", summary.Billable)
+	}
+}
+
+func Test_ParseGrouping(t *testing.T) {
+	if grouping, err := ParseGrouping("week"); err != nil || grouping != ByWeek {
+		t.Fail()
+		t.Logf("ParseGrouping should have returned the week grouping (Error: %v)", err)
+	}
+
+	if _, err := ParseGrouping("year"); err == nil {
+		t.Fail()
+		t.Logf("ParseGrouping should have rejected the unknown grouping")
+	}
+}
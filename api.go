@@ -21,19 +21,41 @@ const pauseBetweenRequests = time.Millisecond * 1000
 
 // NewAPI create a new instance of the Toggl API.
 func NewAPI(baseURL, token string) model.TogglAPI {
-	restAPI := &togglRESTAPIClient{
-		baseURL:              baseURL,
-		token:                token,
-		pauseBetweenRequests: pauseBetweenRequests,
+	return NewAPIWithRequester(NewRESTRequester(baseURL, token), nil)
+}
+
+// NewAPIInLocation create a new instance of the Toggl API which uses the
+// given reporting time zone for the dates of time entry queries and for
+// the times of returned time entries. If no location is given the time
+// zone of the Toggl profile of the user is used.
+func NewAPIInLocation(baseURL, token string, location *time.Location) (model.TogglAPI, error) {
+	restAPI := NewRESTRequester(baseURL, token)
+	if location == nil {
+		userLocation, locationError := GetUserLocation(&UserAPI{restAPI})
+		if locationError != nil {
+			return nil, locationError
+		}
+
+		location = userLocation
 	}
 
+	return NewAPIWithRequester(restAPI, location), nil
+}
+
+// NewAPIWithRequester create a new instance of the Toggl API which sends
+// all requests with the given requester (e.g. a dry-run requester). If a
+// location is given it is used as the reporting time zone.
+func NewAPIWithRequester(requester RESTRequester, location *time.Location) model.TogglAPI {
 	dateFormatter := date.NewISO8601Formatter()
+	if location != nil {
+		dateFormatter = date.NewISO8601FormatterInLocation(location)
+	}
 
 	return &API{
-		&WorkspaceAPI{restAPI},
-		&ProjectAPI{restAPI},
-		&TimeEntryAPI{restAPI, dateFormatter},
-		&ClientAPI{restAPI},
+		&WorkspaceAPI{requester},
+		&ProjectAPI{requester},
+		&TimeEntryAPI{requester, dateFormatter, location},
+		&ClientAPI{requester},
 	}
 }
 
@@ -0,0 +1,176 @@
+// Package backup provides functions for saving all workspaces, clients,
+// projects and time entries of a Toggl account to a portable archive
+// and for restoring an archive into a workspace.
+//
+// Archives are gzip-compressed JSON documents with a format version.
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// Version contains the archive format version written by this package.
+const Version = 1
+
+// DefaultStart contains the default start of the backed up history.
+// Toggl has been launched in 2006.
+var DefaultStart = time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DefaultChunk contains the default period of time entries
+// which are fetched with a single request.
+const DefaultChunk = 30 * 24 * time.Hour
+
+// Archive contains the backed up data of a Toggl account.
+type Archive struct {
+	Version     int               `json:"version"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Workspaces  []model.Workspace `json:"workspaces"`
+	Clients     []model.Client    `json:"clients"`
+	Projects    []model.Project   `json:"projects"`
+	TimeEntries []model.TimeEntry `json:"time_entries"`
+}
+
+// Options contains the settings for creating an archive.
+type Options struct {
+	// Start contains the start of the time entry history.
+	// Defaults to DefaultStart.
+	Start time.Time
+
+	// End contains the end of the time entry history.
+	// Defaults to the current time.
+	End time.Time
+
+	// Chunk contains the period of time entries which are
+	// fetched with a single request. Defaults to DefaultChunk.
+	Chunk time.Duration
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Create fetches all workspaces, clients, projects and the time entries
+// between the start and end of the given options from the given API.
+// The time entries are fetched in chunks because the Toggl API limits
+// the number of time entries returned by a single request.
+func Create(api model.TogglAPI, options Options) (Archive, error) {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	if options.Start.IsZero() {
+		options.Start = DefaultStart
+	}
+
+	if options.End.IsZero() {
+		options.End = options.Now()
+	}
+
+	if options.Chunk <= 0 {
+		options.Chunk = DefaultChunk
+	}
+
+	archive := Archive{
+		Version:   Version,
+		CreatedAt: options.Now(),
+		Start:     options.Start,
+		End:       options.End,
+	}
+
+	workspaces, workspacesError := api.GetWorkspaces()
+	if workspacesError != nil {
+		return Archive{}, errors.Wrap(workspacesError, "Failed to fetch the workspaces")
+	}
+
+	archive.Workspaces = workspaces
+
+	clients, clientsError := api.GetClients()
+	if clientsError != nil {
+		return Archive{}, errors.Wrap(clientsError, "Failed to fetch the clients")
+	}
+
+	archive.Clients = clients
+
+	for _, workspace := range workspaces {
+		projects, projectsError := api.GetProjects(workspace.ID)
+		if projectsError != nil {
+			return Archive{}, errors.Wrap(projectsError, fmt.Sprintf("Failed to fetch the projects of workspace %d", workspace.ID))
+		}
+
+		archive.Projects = append(archive.Projects, projects...)
+	}
+
+	// time entries starting exactly at a chunk boundary are returned twice
+	seen := make(map[int]bool)
+	for start := options.Start; start.Before(options.End); start = start.Add(options.Chunk) {
+		end := start.Add(options.Chunk)
+		if end.After(options.End) {
+			end = options.End
+		}
+
+		timeEntries, timeEntriesError := api.GetTimeEntries(start, end)
+		if timeEntriesError != nil {
+			return Archive{}, errors.Wrap(timeEntriesError, fmt.Sprintf("Failed to fetch the time entries between %s and %s", start.Format(time.RFC3339), end.Format(time.RFC3339)))
+		}
+
+		for _, timeEntry := range timeEntries {
+			if seen[timeEntry.ID] {
+				continue
+			}
+
+			seen[timeEntry.ID] = true
+			archive.TimeEntries = append(archive.TimeEntries, timeEntry)
+		}
+	}
+
+	sort.SliceStable(archive.TimeEntries, func(i, j int) bool {
+		return archive.TimeEntries[i].Start.Before(archive.TimeEntries[j].Start)
+	})
+
+	return archive, nil
+}
+
+// Write writes the given archive as gzip-compressed JSON to the given writer.
+func Write(w io.Writer, archive Archive) error {
+	compressor := gzip.NewWriter(w)
+	if err := json.NewEncoder(compressor).Encode(archive); err != nil {
+		return errors.Wrap(err, "Failed to write the archive")
+	}
+
+	if err := compressor.Close(); err != nil {
+		return errors.Wrap(err, "Failed to write the archive")
+	}
+
+	return nil
+}
+
+// Read reads a gzip-compressed JSON archive from the given reader.
+// Returns an error if the archive has been written by a newer version.
+func Read(r io.Reader) (Archive, error) {
+	decompressor, gzipError := gzip.NewReader(r)
+	if gzipError != nil {
+		return Archive{}, errors.Wrap(gzipError, "The archive is not compressed with gzip")
+	}
+
+	defer decompressor.Close()
+
+	var archive Archive
+	if err := json.NewDecoder(decompressor).Decode(&archive); err != nil {
+		return Archive{}, errors.Wrap(err, "Failed to read the archive")
+	}
+
+	if archive.Version < 1 || archive.Version > Version {
+		return Archive{}, fmt.Errorf("Unsupported archive version %d (supported: 1 to %d)", archive.Version, Version)
+	}
+
+	return archive, nil
+}
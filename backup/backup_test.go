@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// newSourceAPI returns an in-memory API with a client,
+// a project and time entries spread over two years.
+func newSourceAPI() *togglapitest.API {
+	api := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Work"}, model.Workspace{ID: 2, Name: "Private"})
+	client, _ := api.CreateClient(model.Client{WorkspaceID: 1, Name: "Acme"})
+	project, _ := api.CreateProject(model.Project{WorkspaceID: 1, ClientID: client.ID, Name: "Website"})
+	api.CreateProject(model.Project{WorkspaceID: 2, Name: "Garden"})
+
+	for _, start := range []time.Time{
+		time.Date(2015, 3, 2, 8, 0, 0, 0, time.UTC),
+		time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC),
+	} {
+		api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: project.ID, Start: start, Stop: start.Add(time.Hour), Description: "Design"})
+	}
+
+	start := time.Date(2016, 9, 7, 8, 0, 0, 0, time.UTC)
+	api.CreateTimeEntry(model.TimeEntry{Wid: 2, Start: start, Stop: start.Add(time.Hour), Description: "Mowing"})
+	return api
+}
+
+func Test_Create_AllHistoryIsFetchedInChunks(t *testing.T) {
+	// arrange
+	api := newSourceAPI()
+	now := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// act
+	archive, err := Create(api, Options{
+		Start: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+		Now:   func() time.Time { return now },
+	})
+
+	// assert
+	if err != nil || archive.Version != Version || len(archive.Workspaces) != 2 || len(archive.Clients) != 1 || len(archive.Projects) != 2 || len(archive.TimeEntries) != 3 {
+		t.Fail()
+		t.Logf("Create should have archived all records (Archive: %+v, Error: %v)", archive, err)
+	}
+
+	getTimeEntriesCalls := 0
+	for _, call := range api.Calls {
+		if call == "GetTimeEntries" {
+			getTimeEntriesCalls++
+		}
+	}
+
+	if getTimeEntriesCalls < 2 {
+		t.Fail()
+		t.Logf("Create should have fetched the time entries in chunks but called GetTimeEntries %d times", getTimeEntriesCalls)
+	}
+}
+
+func Test_Create_TimeEntryAtChunkBoundary_TimeEntryIsArchivedOnce(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start.Add(24 * time.Hour), Stop: start.Add(25 * time.Hour)})
+
+	// act
+	archive, err := Create(api, Options{Start: start, End: start.Add(48 * time.Hour), Chunk: 24 * time.Hour})
+
+	// assert
+	if err != nil || len(archive.TimeEntries) != 1 {
+		t.Fail()
+		t.Logf("Create should have archived the time entry once but archived %d time entries (Error: %v)", len(archive.TimeEntries), err)
+	}
+}
+
+func Test_WriteRead_ArchiveIsRestoredUnchanged(t *testing.T) {
+	// arrange
+	archive, _ := Create(newSourceAPI(), Options{Start: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)})
+	buffer := &bytes.Buffer{}
+
+	// act
+	writeError := Write(buffer, archive)
+	readArchive, readError := Read(buffer)
+
+	// assert
+	if writeError != nil || readError != nil || len(readArchive.TimeEntries) != 3 || readArchive.TimeEntries[0].Description != "Design" {
+		t.Fail()
+		t.Logf("Read should have returned the written archive (Write error: %v, Read error: %v)", writeError, readError)
+	}
+}
+
+func Test_Read_NewerVersion_ErrorIsReturned(t *testing.T) {
+	// arrange
+	buffer := &bytes.Buffer{}
+	compressor := gzip.NewWriter(buffer)
+	compressor.Write([]byte(`{"version": 99}`))
+	compressor.Close()
+
+	// act
+	_, err := Read(buffer)
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), "99") {
+		t.Fail()
+		t.Logf("Read should have rejected the unsupported version (Error: %v)", err)
+	}
+}
+
+func Test_Read_UncompressedInput_ErrorIsReturned(t *testing.T) {
+	// act
+	_, err := Read(strings.NewReader(`{"version": 1}`))
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("Read should have rejected the uncompressed archive")
+	}
+}
+
+func Test_Restore_RecordsAreCreatedWithRemappedIDs(t *testing.T) {
+	// arrange
+	archive, _ := Create(newSourceAPI(), Options{Start: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)})
+	target := togglapitest.NewAPI(model.Workspace{ID: 7, Name: "Restored"})
+	target.CreateClient(model.Client{WorkspaceID: 7, Name: "acme"})
+
+	// act
+	result, err := Restore(target, archive, RestoreOptions{WorkspaceID: 7, SourceWorkspaceID: 1})
+
+	// assert
+	if err != nil || result.ReusedClients != 1 || result.CreatedProjects != 1 || result.CreatedTimeEntries != 2 {
+		t.Fatalf("Restore should have restored the records of workspace 1 (Result: %+v, Error: %v)", result, err)
+	}
+
+	project := target.Projects[0]
+	if project.WorkspaceID != 7 || project.ClientID != target.Clients[0].ID {
+		t.Fail()
+		t.Logf("Restore should have assigned the project to the existing client in the target workspace: %+v", project)
+	}
+
+	for _, timeEntry := range target.TimeEntries {
+		if timeEntry.Wid != 7 || timeEntry.Pid != project.ID {
+			t.Fail()
+			t.Logf("Restore should have assigned the time entry to the restored project: %+v", timeEntry)
+		}
+	}
+}
+
+func Test_Restore_ProjectsWithSameNameOfDifferentClients_ProjectsAreKeptApart(t *testing.T) {
+	// arrange
+	archive := Archive{
+		Version: Version,
+		Clients: []model.Client{{ID: 1, WorkspaceID: 1, Name: "Acme"}, {ID: 2, WorkspaceID: 1, Name: "Globex"}},
+		Projects: []model.Project{
+			{ID: 10, WorkspaceID: 1, ClientID: 1, Name: "Website"},
+			{ID: 11, WorkspaceID: 1, ClientID: 2, Name: "Website"},
+		},
+	}
+
+	target := togglapitest.NewAPI(model.Workspace{ID: 7})
+	acme, _ := target.CreateClient(model.Client{WorkspaceID: 7, Name: "Acme"})
+	existing, _ := target.CreateProject(model.Project{WorkspaceID: 7, ClientID: acme.ID, Name: "Website"})
+
+	// act
+	result, err := Restore(target, archive, RestoreOptions{WorkspaceID: 7})
+
+	// assert
+	if err != nil || result.ReusedProjects != 1 || result.CreatedProjects != 1 || result.ProjectIDs[10] != existing.ID || result.ProjectIDs[11] == existing.ID {
+		t.Fail()
+		t.Logf("Restore should have reused the project of Acme and created the one of Globex (Result: %+v, Error: %v)", result, err)
+	}
+}
+
+func Test_Restore_RunningTimeEntry_TimeEntryIsSkipped(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	archive := Archive{
+		Version:     Version,
+		TimeEntries: []model.TimeEntry{{ID: 5, Wid: 1, Start: start, Duration: -int(start.Unix())}},
+	}
+
+	target := togglapitest.NewAPI(model.Workspace{ID: 7})
+
+	// act
+	result, err := Restore(target, archive, RestoreOptions{WorkspaceID: 7})
+
+	// assert
+	if err != nil || result.SkippedTimeEntries != 1 || len(target.TimeEntries) != 0 {
+		t.Fail()
+		t.Logf("Restore should have skipped the running time entry (Result: %+v, Error: %v)", result, err)
+	}
+}
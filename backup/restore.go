@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// RestoreOptions contains the settings for restoring an archive.
+type RestoreOptions struct {
+	// WorkspaceID contains the ID of the workspace the
+	// clients, projects and time entries are restored into.
+	WorkspaceID int
+
+	// SourceWorkspaceID restricts the restore to the records of
+	// the archived workspace with the given ID. Zero restores
+	// the records of all archived workspaces.
+	SourceWorkspaceID int
+}
+
+// RestoreResult contains the outcome of a restore.
+type RestoreResult struct {
+	// CreatedClients, CreatedProjects and CreatedTimeEntries contain
+	// the number of records created in the target workspace.
+	CreatedClients     int
+	CreatedProjects    int
+	CreatedTimeEntries int
+
+	// ReusedClients and ReusedProjects contain the number of archived
+	// records which matched an existing record of the same name.
+	ReusedClients  int
+	ReusedProjects int
+
+	// SkippedTimeEntries contains the number of running time entries
+	// which have not been restored.
+	SkippedTimeEntries int
+
+	// ClientIDs, ProjectIDs and TimeEntryIDs map the archived IDs to the IDs
+	// in the target workspace.
+	ClientIDs    map[int]int
+	ProjectIDs   map[int]int
+	TimeEntryIDs map[int]int
+}
+
+// Restore recreates the clients, projects and time entries of the given
+// archive in the target workspace of the given options. Clients (same
+// name) and projects (same name and client) which already exist in the
+// target workspace are reused. The archived IDs are mapped to the new
+// IDs. The result contains the records restored before an error occurred.
+func Restore(api model.TogglAPI, archive Archive, options RestoreOptions) (RestoreResult, error) {
+	result := RestoreResult{
+		ClientIDs:    make(map[int]int),
+		ProjectIDs:   make(map[int]int),
+		TimeEntryIDs: make(map[int]int),
+	}
+
+	if options.WorkspaceID == 0 {
+		return result, fmt.Errorf("No target workspace specified")
+	}
+
+	if err := restoreClients(api, archive, options, &result); err != nil {
+		return result, err
+	}
+
+	if err := restoreProjects(api, archive, options, &result); err != nil {
+		return result, err
+	}
+
+	return result, restoreTimeEntries(api, archive, options, &result)
+}
+
+// restoreClients creates the archived clients which do not exist in the target workspace.
+func restoreClients(api model.TogglAPI, archive Archive, options RestoreOptions, result *RestoreResult) error {
+	existingClients, clientsError := api.GetClients()
+	if clientsError != nil {
+		return errors.Wrap(clientsError, "Failed to fetch the clients of the target workspace")
+	}
+
+	clientIDs := make(map[string]int)
+	for _, client := range existingClients {
+		if client.WorkspaceID == options.WorkspaceID {
+			clientIDs[strings.ToLower(client.Name)] = client.ID
+		}
+	}
+
+	for _, client := range archive.Clients {
+		if !options.includes(client.WorkspaceID) {
+			continue
+		}
+
+		if id, exists := clientIDs[strings.ToLower(client.Name)]; exists {
+			result.ClientIDs[client.ID] = id
+			result.ReusedClients++
+			continue
+		}
+
+		archivedID := client.ID
+		client.ID = 0
+		client.WorkspaceID = options.WorkspaceID
+		createdClient, createError := api.CreateClient(client)
+		if createError != nil {
+			return errors.Wrap(createError, fmt.Sprintf("Failed to restore client %q", client.Name))
+		}
+
+		clientIDs[strings.ToLower(client.Name)] = createdClient.ID
+		result.ClientIDs[archivedID] = createdClient.ID
+		result.CreatedClients++
+	}
+
+	return nil
+}
+
+// projectKey identifies a project in the target workspace. Different
+// clients can have projects with the same name.
+type projectKey struct {
+	clientID int
+	name     string
+}
+
+// restoreProjects creates the archived projects which do not exist in the target workspace.
+func restoreProjects(api model.TogglAPI, archive Archive, options RestoreOptions, result *RestoreResult) error {
+	existingProjects, projectsError := api.GetProjects(options.WorkspaceID)
+	if projectsError != nil {
+		return errors.Wrap(projectsError, "Failed to fetch the projects of the target workspace")
+	}
+
+	projectIDs := make(map[projectKey]int)
+	for _, project := range existingProjects {
+		projectIDs[projectKey{project.ClientID, strings.ToLower(project.Name)}] = project.ID
+	}
+
+	for _, project := range archive.Projects {
+		if !options.includes(project.WorkspaceID) {
+			continue
+		}
+
+		archivedID := project.ID
+		project.ClientID = result.ClientIDs[project.ClientID]
+		key := projectKey{project.ClientID, strings.ToLower(project.Name)}
+		if id, exists := projectIDs[key]; exists {
+			result.ProjectIDs[archivedID] = id
+			result.ReusedProjects++
+			continue
+		}
+
+		project.ID = 0
+		project.WorkspaceID = options.WorkspaceID
+		createdProject, createError := api.CreateProject(project)
+		if createError != nil {
+			return errors.Wrap(createError, fmt.Sprintf("Failed to restore project %q", project.Name))
+		}
+
+		projectIDs[key] = createdProject.ID
+		result.ProjectIDs[archivedID] = createdProject.ID
+		result.CreatedProjects++
+	}
+
+	return nil
+}
+
+// restoreTimeEntries creates the archived time entries in the target workspace.
+func restoreTimeEntries(api model.TogglAPI, archive Archive, options RestoreOptions, result *RestoreResult) error {
+	for _, timeEntry := range archive.TimeEntries {
+		if !options.includes(timeEntry.Wid) {
+			continue
+		}
+
+		if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() {
+			result.SkippedTimeEntries++
+			continue
+		}
+
+		archivedID := timeEntry.ID
+		timeEntry.ID = 0
+		timeEntry.Wid = options.WorkspaceID
+		timeEntry.Pid = result.ProjectIDs[timeEntry.Pid]
+		createdTimeEntry, createError := api.CreateTimeEntry(timeEntry)
+		if createError != nil {
+			return errors.Wrap(createError, fmt.Sprintf("Failed to restore time entry %d", archivedID))
+		}
+
+		result.TimeEntryIDs[archivedID] = createdTimeEntry.ID
+		result.CreatedTimeEntries++
+	}
+
+	return nil
+}
+
+// includes returns true if the records of the archived
+// workspace with the given ID must be restored.
+func (options RestoreOptions) includes(workspaceID int) bool {
+	return options.SourceWorkspaceID == 0 || options.SourceWorkspaceID == workspaceID
+}
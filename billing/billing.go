@@ -0,0 +1,356 @@
+// Package billing computes billable amounts from tracked time.
+//
+// Hourly rates can be defined per workspace, client, project and user;
+// the most specific matching rate is used for each time entry. The
+// tracked time is rounded per time entry or per day and the amounts are
+// returned as line items which reference the IDs of the source time
+// entries. Amounts are calculated in minor currency units (e.g. cents).
+package billing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// Rate defines an hourly rate. The rate applies to all time entries
+// which match all of its non-zero scope IDs; a rate without scope
+// IDs applies to all time entries.
+type Rate struct {
+	WorkspaceID int `json:"workspace_id,omitempty"`
+	ClientID    int `json:"client_id,omitempty"`
+	ProjectID   int `json:"project_id,omitempty"`
+	UserID      int `json:"user_id,omitempty"`
+
+	// Hourly contains the hourly rate in minor currency units (e.g. cents).
+	Hourly int64 `json:"hourly"`
+
+	// Currency contains the ISO 4217 currency code (e.g. "EUR").
+	Currency string `json:"currency"`
+}
+
+// specificity returns a score which is higher for more specific rates.
+// Project rates beat client rates, which beat user and workspace rates.
+func (rate Rate) specificity() int {
+	score := 0
+	if rate.ProjectID != 0 {
+		score += 8
+	}
+
+	if rate.ClientID != 0 {
+		score += 4
+	}
+
+	if rate.UserID != 0 {
+		score += 2
+	}
+
+	if rate.WorkspaceID != 0 {
+		score++
+	}
+
+	return score
+}
+
+// matches returns true if the rate applies to a time entry with the given IDs.
+func (rate Rate) matches(workspaceID, clientID, projectID, userID int) bool {
+	return (rate.WorkspaceID == 0 || rate.WorkspaceID == workspaceID) &&
+		(rate.ClientID == 0 || rate.ClientID == clientID) &&
+		(rate.ProjectID == 0 || rate.ProjectID == projectID) &&
+		(rate.UserID == 0 || rate.UserID == userID)
+}
+
+// RoundingMode defines the direction of the rounding.
+type RoundingMode string
+
+// The available rounding modes.
+const (
+	NoRounding RoundingMode = ""
+	Up         RoundingMode = "up"
+	Down       RoundingMode = "down"
+	Nearest    RoundingMode = "nearest"
+)
+
+// Rounding defines how tracked time is rounded before it is billed.
+type Rounding struct {
+	Mode RoundingMode
+
+	// Increment contains the rounding increment (e.g. 6 or 15 minutes).
+	Increment time.Duration
+
+	// PerDay rounds the daily total of a project instead of every time entry.
+	PerDay bool
+}
+
+// ParseRounding parses a rounding like "up/15m", "nearest/6m" or
+// "down/30m". A "/day" suffix (e.g. "up/15m/day") rounds per day.
+// An empty value disables the rounding.
+func ParseRounding(value string) (Rounding, error) {
+	if value == "" {
+		return Rounding{}, nil
+	}
+
+	parts := strings.Split(value, "/")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "day") {
+		return Rounding{}, fmt.Errorf("%q is not a valid rounding (e.g. up/15m, nearest/6m or down/30m/day)", value)
+	}
+
+	mode := RoundingMode(parts[0])
+	if mode != Up && mode != Down && mode != Nearest {
+		return Rounding{}, fmt.Errorf("Unknown rounding mode %q (available: up, down, nearest)", parts[0])
+	}
+
+	increment, incrementError := time.ParseDuration(parts[1])
+	if incrementError != nil || increment <= 0 {
+		return Rounding{}, fmt.Errorf("%q is not a valid rounding increment (e.g. 15m)", parts[1])
+	}
+
+	return Rounding{Mode: mode, Increment: increment, PerDay: len(parts) == 3}, nil
+}
+
+// Round rounds the given duration.
+func (rounding Rounding) Round(duration time.Duration) time.Duration {
+	if rounding.Mode == NoRounding || rounding.Increment <= 0 {
+		return duration
+	}
+
+	remainder := duration % rounding.Increment
+	if remainder == 0 {
+		return duration
+	}
+
+	switch rounding.Mode {
+	case Up:
+		return duration - remainder + rounding.Increment
+	case Nearest:
+		if remainder*2 >= rounding.Increment {
+			return duration - remainder + rounding.Increment
+		}
+	}
+
+	return duration - remainder
+}
+
+// Options contains the settings for calculating the line items.
+type Options struct {
+	// Rates contains the available hourly rates.
+	Rates []Rate
+
+	// Rounding defines how the tracked time is rounded.
+	Rounding Rounding
+
+	// Minimum contains the minimum billed duration of a time entry
+	// (or of a day if the rounding is per day).
+	Minimum time.Duration
+
+	// Projects is used for resolving the clients of the time entries.
+	Projects []model.Project
+
+	// Location is used for grouping the time entries by day. Defaults to time.Local.
+	Location *time.Location
+
+	// IncludeNonBillable also bills the time entries which are not billable.
+	IncludeNonBillable bool
+}
+
+// LineItem contains the billed time of a time entry or of
+// the time entries of a project on a single day.
+type LineItem struct {
+	// Date contains the day of the time entries.
+	Date time.Time
+
+	WorkspaceID int
+	ClientID    int
+	ProjectID   int
+	UserID      int
+
+	// Description contains the distinct descriptions of the time entries.
+	Description string
+
+	// Tracked contains the tracked time; Billed the rounded time.
+	Tracked time.Duration
+	Billed  time.Duration
+
+	// Rate contains the applied hourly rate.
+	Rate Rate
+
+	// Amount contains the billed amount in minor currency units.
+	Amount int64
+
+	// TimeEntryIDs contains the IDs of the source time entries.
+	TimeEntryIDs []int
+}
+
+// Bill contains the calculated line items.
+type Bill struct {
+	LineItems []LineItem
+
+	// Totals contains the total amount per currency.
+	Totals map[string]int64
+}
+
+// Calculate returns the line items for the given time entries. Running time
+// entries are skipped. Returns an error if no rate matches a time entry.
+func Calculate(timeEntries []model.TimeEntry, options Options) (Bill, error) {
+	location := options.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	clientIDs := make(map[int]int)
+	for _, project := range options.Projects {
+		clientIDs[project.ID] = project.ClientID
+	}
+
+	var lineItems []*LineItem
+	days := make(map[string]*LineItem)
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() || (!timeEntry.Billable && !options.IncludeNonBillable) {
+			continue
+		}
+
+		clientID := clientIDs[timeEntry.Pid]
+		rate, rateExists := findRate(options.Rates, timeEntry.Wid, clientID, timeEntry.Pid, timeEntry.Uid)
+		if !rateExists {
+			return Bill{}, fmt.Errorf("No rate defined for time entry %d (workspace %d, client %d, project %d, user %d)", timeEntry.ID, timeEntry.Wid, clientID, timeEntry.Pid, timeEntry.Uid)
+		}
+
+		year, month, day := timeEntry.Start.In(location).Date()
+		date := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+		var lineItem *LineItem
+		if options.Rounding.PerDay {
+			key := fmt.Sprintf("%s|%d|%d|%d|%d|%v", date.Format("2006-01-02"), timeEntry.Wid, clientID, timeEntry.Pid, timeEntry.Uid, rate)
+			lineItem = days[key]
+			if lineItem == nil {
+				lineItem = &LineItem{}
+				days[key] = lineItem
+				lineItems = append(lineItems, lineItem)
+			}
+		} else {
+			lineItem = &LineItem{}
+			lineItems = append(lineItems, lineItem)
+		}
+
+		lineItem.Date = date
+		lineItem.WorkspaceID = timeEntry.Wid
+		lineItem.ClientID = clientID
+		lineItem.ProjectID = timeEntry.Pid
+		lineItem.UserID = timeEntry.Uid
+		lineItem.Rate = rate
+		lineItem.Tracked += timeEntry.Stop.Sub(timeEntry.Start)
+		lineItem.TimeEntryIDs = append(lineItem.TimeEntryIDs, timeEntry.ID)
+		lineItem.Description = appendDescription(lineItem.Description, timeEntry.Description)
+	}
+
+	bill := Bill{Totals: make(map[string]int64)}
+	for _, lineItem := range lineItems {
+		lineItem.Billed = options.Rounding.Round(lineItem.Tracked)
+		if lineItem.Billed < options.Minimum {
+			lineItem.Billed = options.Minimum
+		}
+
+		lineItem.Amount = Amount(lineItem.Billed, lineItem.Rate.Hourly)
+		bill.Totals[lineItem.Rate.Currency] += lineItem.Amount
+		bill.LineItems = append(bill.LineItems, *lineItem)
+	}
+
+	sort.SliceStable(bill.LineItems, func(i, j int) bool {
+		return bill.LineItems[i].Date.Before(bill.LineItems[j].Date)
+	})
+
+	return bill, nil
+}
+
+// Amount returns the amount for the given duration and hourly rate
+// in minor currency units, rounded half up.
+func Amount(duration time.Duration, hourly int64) int64 {
+	seconds := int64(duration / time.Second)
+	return (seconds*hourly + 1800) / 3600
+}
+
+// minorUnits contains the number of decimals of the ISO 4217
+// currencies which do not have two decimals.
+var minorUnits = map[string]int{
+	"BHD": 3, "BIF": 0, "CLF": 4, "CLP": 0, "DJF": 0, "GNF": 0,
+	"IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "RWF": 0, "TND": 3,
+	"UGX": 0, "UYI": 0, "UYW": 4, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+}
+
+// MinorUnits returns the number of decimals of the given currency
+// (e.g. 2 for "EUR" and 0 for "JPY"). Unknown currencies have two.
+func MinorUnits(currency string) int {
+	if digits, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+
+	return 2
+}
+
+// ToMinorUnits converts the given amount in major currency units
+// (e.g. 95.5 EUR) to minor currency units (e.g. 9550), rounded half up.
+func ToMinorUnits(value float64, currency string) int64 {
+	return int64(math.Round(value * math.Pow10(MinorUnits(currency))))
+}
+
+// FormatAmount formats the given amount in minor currency units with
+// the decimals of the currency (e.g. "1234.50 EUR" or "1234 JPY").
+func FormatAmount(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := MinorUnits(currency)
+	if digits == 0 {
+		return strings.TrimSpace(fmt.Sprintf("%s%d %s", sign, amount, currency))
+	}
+
+	unit := int64(math.Pow10(digits))
+	return strings.TrimSpace(fmt.Sprintf("%s%d.%0*d %s", sign, amount/unit, digits, amount%unit, currency))
+}
+
+// findRate returns the most specific rate matching the given IDs.
+func findRate(rates []Rate, workspaceID, clientID, projectID, userID int) (Rate, bool) {
+	var match Rate
+	found := false
+	for _, rate := range rates {
+		if !rate.matches(workspaceID, clientID, projectID, userID) {
+			continue
+		}
+
+		if !found || rate.specificity() > match.specificity() {
+			match = rate
+			found = true
+		}
+	}
+
+	return match, found
+}
+
+// appendDescription adds the given description to the
+// given list of descriptions unless it is already listed.
+func appendDescription(descriptions, description string) string {
+	if description == "" {
+		return descriptions
+	}
+
+	for _, existing := range strings.Split(descriptions, "; ") {
+		if existing == description {
+			return descriptions
+		}
+	}
+
+	if descriptions == "" {
+		return description
+	}
+
+	return descriptions + "; " + description
+}
@@ -0,0 +1,227 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// testEntry returns a billable time entry of project 1 in workspace 1.
+func testEntry(id int, start time.Time, duration time.Duration) model.TimeEntry {
+	return model.TimeEntry{ID: id, Wid: 1, Pid: 1, Uid: 7, Start: start, Stop: start.Add(duration), Duration: int(duration.Seconds()), Billable: true}
+}
+
+// testOptions returns options with a workspace rate and a
+// project 1 (of client 10) in the given rounding.
+func testOptions(rounding Rounding) Options {
+	return Options{
+		Rates:    []Rate{{WorkspaceID: 1, Hourly: 10000, Currency: "EUR"}},
+		Rounding: rounding,
+		Projects: []model.Project{{ID: 1, ClientID: 10}},
+		Location: time.UTC,
+	}
+}
+
+func Test_Round(t *testing.T) {
+	inputs := []struct {
+		rounding Rounding
+		duration time.Duration
+		expected time.Duration
+	}{
+		{Rounding{}, 7 * time.Minute, 7 * time.Minute},
+		{Rounding{Mode: Up, Increment: 15 * time.Minute}, 16 * time.Minute, 30 * time.Minute},
+		{Rounding{Mode: Up, Increment: 15 * time.Minute}, 15 * time.Minute, 15 * time.Minute},
+		{Rounding{Mode: Down, Increment: 6 * time.Minute}, 11 * time.Minute, 6 * time.Minute},
+		{Rounding{Mode: Nearest, Increment: 30 * time.Minute}, 44 * time.Minute, 30 * time.Minute},
+		{Rounding{Mode: Nearest, Increment: 30 * time.Minute}, 45 * time.Minute, time.Hour},
+		{Rounding{Mode: Up, Increment: time.Minute}, 61 * time.Second, 2 * time.Minute},
+	}
+
+	for _, input := range inputs {
+		if result := input.rounding.Round(input.duration); result != input.expected {
+			t.Fail()
+			t.Logf("%+v.Round(%s) returned %s instead of %s", input.rounding, input.duration, result, input.expected)
+		}
+	}
+}
+
+func Test_ParseRounding(t *testing.T) {
+	inputs := []struct {
+		value    string
+		expected Rounding
+		valid    bool
+	}{
+		{"", Rounding{}, true},
+		{"up/15m", Rounding{Mode: Up, Increment: 15 * time.Minute}, true},
+		{"nearest/6m/day", Rounding{Mode: Nearest, Increment: 6 * time.Minute, PerDay: true}, true},
+		{"sideways/15m", Rounding{}, false},
+		{"up/never", Rounding{}, false},
+		{"up/15m/week", Rounding{}, false},
+	}
+
+	for _, input := range inputs {
+		rounding, err := ParseRounding(input.value)
+		if rounding != input.expected || (err == nil) != input.valid {
+			t.Fail()
+			t.Logf("ParseRounding(%q) returned %+v (Error: %v)", input.value, rounding, err)
+		}
+	}
+}
+
+func Test_Calculate_PerEntry_EachEntryIsRoundedAndReferenced(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	timeEntries := []model.TimeEntry{
+		testEntry(101, start, 10*time.Minute),
+		testEntry(102, start.Add(time.Hour), 50*time.Minute),
+	}
+
+	// act
+	bill, err := Calculate(timeEntries, testOptions(Rounding{Mode: Up, Increment: 15 * time.Minute}))
+
+	// assert
+	if err != nil || len(bill.LineItems) != 2 || bill.LineItems[0].Billed != 15*time.Minute || bill.LineItems[1].Billed != time.Hour {
+		t.Fatalf("Calculate should have rounded every entry (Bill: %+v, Error: %v)", bill, err)
+	}
+
+	if bill.LineItems[0].TimeEntryIDs[0] != 101 || bill.LineItems[0].Amount != 2500 || bill.Totals["EUR"] != 12500 {
+		t.Fail()
+		t.Logf("Calculate should have returned the amounts and entry IDs: %+v", bill)
+	}
+}
+
+func Test_Calculate_PerDay_DailyTotalIsRounded(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	timeEntries := []model.TimeEntry{
+		testEntry(101, start, 10*time.Minute),
+		testEntry(102, start.Add(time.Hour), 10*time.Minute),
+		testEntry(103, start.Add(24*time.Hour), 10*time.Minute),
+	}
+
+	// act
+	bill, err := Calculate(timeEntries, testOptions(Rounding{Mode: Up, Increment: 15 * time.Minute, PerDay: true}))
+
+	// assert
+	if err != nil || len(bill.LineItems) != 2 || bill.LineItems[0].Billed != 30*time.Minute || len(bill.LineItems[0].TimeEntryIDs) != 2 || bill.LineItems[1].Billed != 15*time.Minute {
+		t.Fail()
+		t.Logf("Calculate should have rounded the daily totals (Bill: %+v, Error: %v)", bill, err)
+	}
+}
+
+func Test_Calculate_MinimumIsApplied(t *testing.T) {
+	// arrange
+	options := testOptions(Rounding{})
+	options.Minimum = 15 * time.Minute
+	timeEntries := []model.TimeEntry{testEntry(101, time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC), 2*time.Minute)}
+
+	// act
+	bill, err := Calculate(timeEntries, options)
+
+	// assert
+	if err != nil || bill.LineItems[0].Billed != 15*time.Minute {
+		t.Fail()
+		t.Logf("Calculate should have billed the minimum duration (Bill: %+v, Error: %v)", bill, err)
+	}
+}
+
+func Test_Calculate_MostSpecificRateIsUsed(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	options := testOptions(Rounding{})
+	options.Rates = append(options.Rates,
+		Rate{ClientID: 10, Hourly: 12000, Currency: "EUR"},
+		Rate{ProjectID: 1, Hourly: 15000, Currency: "USD"},
+		Rate{ClientID: 10, UserID: 7, Hourly: 13000, Currency: "EUR"},
+	)
+
+	otherProject := testEntry(102, start, time.Hour)
+	otherProject.Pid = 2
+	options.Projects = append(options.Projects, model.Project{ID: 2, ClientID: 10})
+
+	timeEntries := []model.TimeEntry{testEntry(101, start, time.Hour), otherProject}
+
+	// act
+	bill, err := Calculate(timeEntries, options)
+
+	// assert
+	if err != nil || bill.LineItems[0].Rate.Hourly != 15000 || bill.LineItems[1].Rate.Hourly != 13000 || bill.Totals["USD"] != 15000 || bill.Totals["EUR"] != 13000 {
+		t.Fail()
+		t.Logf("Calculate should have used the most specific rates (Bill: %+v, Error: %v)", bill, err)
+	}
+}
+
+func Test_Calculate_NonBillableAndRunningEntriesAreSkipped(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	nonBillable := testEntry(101, start, time.Hour)
+	nonBillable.Billable = false
+	running := model.TimeEntry{ID: 102, Wid: 1, Start: start, Duration: -int(start.Unix()), Billable: true}
+
+	// act
+	bill, err := Calculate([]model.TimeEntry{nonBillable, running}, testOptions(Rounding{}))
+
+	// assert
+	if err != nil || len(bill.LineItems) != 0 {
+		t.Fail()
+		t.Logf("Calculate should have skipped the entries (Bill: %+v, Error: %v)", bill, err)
+	}
+}
+
+func Test_Calculate_NoMatchingRate_ErrorIsReturned(t *testing.T) {
+	// arrange
+	timeEntry := testEntry(101, time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC), time.Hour)
+	timeEntry.Wid = 2
+
+	// act
+	_, err := Calculate([]model.TimeEntry{timeEntry}, testOptions(Rounding{}))
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("Calculate should have returned an error for the entry without rate")
+	}
+}
+
+func Test_FormatAmount(t *testing.T) {
+	if formatted := FormatAmount(123405, "EUR"); formatted != "1234.05 EUR" {
+		t.Fail()
+		t.Logf("FormatAmount returned %q", formatted)
+	}
+
+	if formatted := FormatAmount(-50, ""); formatted != "-0.50" {
+		t.Fail()
+		t.Logf("FormatAmount returned %q", formatted)
+	}
+
+	if formatted := FormatAmount(123405, "JPY"); formatted != "123405 JPY" {
+		t.Fail()
+		t.Logf("FormatAmount returned %q", formatted)
+	}
+
+	if formatted := FormatAmount(1234050, "KWD"); formatted != "1234.050 KWD" {
+		t.Fail()
+		t.Logf("FormatAmount returned %q", formatted)
+	}
+}
+
+func Test_ToMinorUnits(t *testing.T) {
+	inputs := []struct {
+		Value    float64
+		Currency string
+		Expected int64
+	}{
+		{95.5, "EUR", 9550},
+		{95.5, "eur", 9550},
+		{9500, "JPY", 9500},
+		{1.2345, "KWD", 1235},
+		{10, "", 1000},
+	}
+
+	for _, input := range inputs {
+		if amount := ToMinorUnits(input.Value, input.Currency); amount != input.Expected {
+			t.Errorf("ToMinorUnits(%v, %q) returned %d instead of %d", input.Value, input.Currency, amount, input.Expected)
+		}
+	}
+}
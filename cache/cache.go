@@ -0,0 +1,296 @@
+// Package cache provides a caching layer for the Toggl API.
+//
+// The cached API implements model.TogglAPI and keeps workspaces, clients,
+// projects and time entries in a Store (e.g. a local JSON file) so repeated
+// calls do not hit the rate-limited Toggl API. Every resource type has its
+// own time-to-live and creating, updating or deleting entities through the
+// cached API invalidates the affected resources.
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// TTLs contains the time-to-live of each cached resource type.
+// A TTL of zero disables caching for the resource type.
+type TTLs struct {
+	Workspaces  time.Duration
+	Clients     time.Duration
+	Projects    time.Duration
+	TimeEntries time.Duration
+}
+
+// DefaultTTLs returns the default time-to-live of each resource type.
+func DefaultTTLs() TTLs {
+	return TTLs{
+		Workspaces:  time.Hour * 24,
+		Clients:     time.Hour,
+		Projects:    time.Hour,
+		TimeEntries: time.Minute * 5,
+	}
+}
+
+// The keys (and key prefixes) of the cached resources.
+const (
+	workspacesKey        = "workspaces"
+	clientsKey           = "clients"
+	projectsKeyPrefix    = "projects/"
+	timeEntriesKeyPrefix = "time_entries/"
+)
+
+// New creates a new cached Toggl API which stores the
+// responses of the given API in the given store.
+func New(api model.TogglAPI, store Store, ttls TTLs) *API {
+	return &API{
+		api:   api,
+		store: store,
+		ttls:  ttls,
+		now:   time.Now,
+	}
+}
+
+// API is a model.TogglAPI which caches the responses of another Toggl API.
+type API struct {
+	api   model.TogglAPI
+	store Store
+	ttls  TTLs
+	now   func() time.Time
+}
+
+// Refresh invalidates all cached resources so the next
+// calls fetch fresh data from the Toggl API.
+func (api *API) Refresh() error {
+	return api.store.DeletePrefix("")
+}
+
+// GetWorkspaces returns all workspaces for the current user.
+func (api *API) GetWorkspaces() ([]model.Workspace, error) {
+	var workspaces []model.Workspace
+	err := api.cached(workspacesKey, api.ttls.Workspaces, &workspaces, func() (err error) {
+		workspaces, err = api.api.GetWorkspaces()
+		return err
+	})
+
+	return workspaces, err
+}
+
+// CreateClient creates a new client and invalidates the cached clients.
+func (api *API) CreateClient(client model.Client) (model.Client, error) {
+	createdClient, createError := api.api.CreateClient(client)
+	if createError != nil {
+		return model.Client{}, createError
+	}
+
+	return createdClient, api.store.DeletePrefix(clientsKey)
+}
+
+// UpdateClient updates the given client and invalidates the cached clients.
+func (api *API) UpdateClient(client model.Client) (model.Client, error) {
+	updatedClient, updateError := api.api.UpdateClient(client)
+	if updateError != nil {
+		return model.Client{}, updateError
+	}
+
+	return updatedClient, api.store.DeletePrefix(clientsKey)
+}
+
+// PatchClient changes the given fields of a client and invalidates the cached clients.
+func (api *API) PatchClient(id int, changes model.ClientChanges) (model.Client, error) {
+	patchedClient, patchError := api.api.PatchClient(id, changes)
+	if patchError != nil {
+		return model.Client{}, patchError
+	}
+
+	return patchedClient, api.store.DeletePrefix(clientsKey)
+}
+
+// DeleteClient deletes the client with the given ID and invalidates the cached clients.
+func (api *API) DeleteClient(id int) error {
+	if err := api.api.DeleteClient(id); err != nil {
+		return err
+	}
+
+	return api.store.DeletePrefix(clientsKey)
+}
+
+// GetClients returns all clients.
+func (api *API) GetClients() ([]model.Client, error) {
+	var clients []model.Client
+	err := api.cached(clientsKey, api.ttls.Clients, &clients, func() (err error) {
+		clients, err = api.api.GetClients()
+		return err
+	})
+
+	return clients, err
+}
+
+// CreateProject creates a new project and invalidates
+// the cached projects of its workspace.
+func (api *API) CreateProject(project model.Project) (model.Project, error) {
+	createdProject, createError := api.api.CreateProject(project)
+	if createError != nil {
+		return model.Project{}, createError
+	}
+
+	return createdProject, api.store.DeletePrefix(projectsKey(project.WorkspaceID))
+}
+
+// UpdateProject updates the given project and invalidates
+// the cached projects of its workspace.
+func (api *API) UpdateProject(project model.Project) (model.Project, error) {
+	updatedProject, updateError := api.api.UpdateProject(project)
+	if updateError != nil {
+		return model.Project{}, updateError
+	}
+
+	return updatedProject, api.store.DeletePrefix(projectsKey(project.WorkspaceID))
+}
+
+// PatchProject changes the given fields of a project. The workspace of
+// the project is unknown, so the cached projects of all workspaces are
+// invalidated.
+func (api *API) PatchProject(id int, changes model.ProjectChanges) (model.Project, error) {
+	patchedProject, patchError := api.api.PatchProject(id, changes)
+	if patchError != nil {
+		return model.Project{}, patchError
+	}
+
+	return patchedProject, api.store.DeletePrefix(projectsKeyPrefix)
+}
+
+// DeleteProject deletes the project with the given ID.
+// The workspace of the project is unknown, so the cached
+// projects of all workspaces are invalidated.
+func (api *API) DeleteProject(id int) error {
+	if err := api.api.DeleteProject(id); err != nil {
+		return err
+	}
+
+	return api.store.DeletePrefix(projectsKeyPrefix)
+}
+
+// GetProjects returns all projects for the given workspace.
+func (api *API) GetProjects(workspaceID int) ([]model.Project, error) {
+	var projects []model.Project
+	err := api.cached(projectsKey(workspaceID), api.ttls.Projects, &projects, func() (err error) {
+		projects, err = api.api.GetProjects(workspaceID)
+		return err
+	})
+
+	return projects, err
+}
+
+// CreateTimeEntry creates a new time entry and invalidates the cached time entries.
+func (api *API) CreateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	return api.invalidateTimeEntries(api.api.CreateTimeEntry(timeEntry))
+}
+
+// GetTimeEntry returns the time entry with the given ID.
+// Single time entries are not cached.
+func (api *API) GetTimeEntry(id int) (model.TimeEntry, error) {
+	return api.api.GetTimeEntry(id)
+}
+
+// UpdateTimeEntry updates the given time entry and invalidates the cached time entries.
+func (api *API) UpdateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	return api.invalidateTimeEntries(api.api.UpdateTimeEntry(timeEntry))
+}
+
+// PatchTimeEntry changes the given fields of a time entry
+// and invalidates the cached time entries.
+func (api *API) PatchTimeEntry(id int, changes model.TimeEntryChanges) (model.TimeEntry, error) {
+	return api.invalidateTimeEntries(api.api.PatchTimeEntry(id, changes))
+}
+
+// DeleteTimeEntry deletes the time entry with the given ID
+// and invalidates the cached time entries.
+func (api *API) DeleteTimeEntry(id int) error {
+	if err := api.api.DeleteTimeEntry(id); err != nil {
+		return err
+	}
+
+	return api.store.DeletePrefix(timeEntriesKeyPrefix)
+}
+
+// StartTimeEntry starts a new time entry and invalidates the cached time entries.
+func (api *API) StartTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	return api.invalidateTimeEntries(api.api.StartTimeEntry(timeEntry))
+}
+
+// StopTimeEntry stops the given time entry and invalidates the cached time entries.
+func (api *API) StopTimeEntry(id int) (model.TimeEntry, error) {
+	return api.invalidateTimeEntries(api.api.StopTimeEntry(id))
+}
+
+// GetCurrentTimeEntry returns the running time entry.
+// The running time entry is not cached.
+func (api *API) GetCurrentTimeEntry() (model.TimeEntry, error) {
+	return api.api.GetCurrentTimeEntry()
+}
+
+// GetTimeEntries returns all time entries created between the given start and end date.
+func (api *API) GetTimeEntries(start, end time.Time) ([]model.TimeEntry, error) {
+	key := fmt.Sprintf("%s%d-%d", timeEntriesKeyPrefix, start.Unix(), end.Unix())
+
+	var timeEntries []model.TimeEntry
+	err := api.cached(key, api.ttls.TimeEntries, &timeEntries, func() (err error) {
+		if timeEntries, err = api.api.GetTimeEntries(start, end); err != nil {
+			return err
+		}
+
+		return api.evictTimeEntries()
+	})
+
+	return timeEntries, err
+}
+
+// cached reads the value with the given key from the store into the given
+// value pointer. If the value is missing or older than the given TTL the
+// fetch function is called for filling the value and the value is stored.
+func (api *API) cached(key string, ttl time.Duration, value interface{}, fetch func() error) error {
+	if ttl > 0 {
+		storedAt, found, getError := api.store.Get(key, value)
+		if getError == nil && found && api.now().Sub(storedAt) < ttl {
+			return nil
+		}
+	}
+
+	if fetchError := fetch(); fetchError != nil {
+		return fetchError
+	}
+
+	if ttl > 0 {
+		return api.store.Set(key, value)
+	}
+
+	return nil
+}
+
+// evictTimeEntries removes the cached time entries of all ranges which
+// have expired. Every range has its own key, so expired ranges would
+// otherwise accumulate in the store.
+func (api *API) evictTimeEntries() error {
+	if api.ttls.TimeEntries <= 0 {
+		return nil
+	}
+
+	return api.store.DeleteStoredBefore(timeEntriesKeyPrefix, api.now().Add(-api.ttls.TimeEntries))
+}
+
+// invalidateTimeEntries removes all cached time entries if the
+// given error is nil and returns the given time entry and error.
+func (api *API) invalidateTimeEntries(timeEntry model.TimeEntry, err error) (model.TimeEntry, error) {
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return timeEntry, api.store.DeletePrefix(timeEntriesKeyPrefix)
+}
+
+// projectsKey returns the key of the projects of the given workspace.
+func projectsKey(workspaceID int) string {
+	return fmt.Sprintf("%s%d", projectsKeyPrefix, workspaceID)
+}
@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// the cached API must implement the Toggl API
+var _ model.TogglAPI = &API{}
+
+// newTestCache creates a cached API for an in-memory API using a
+// file store in a temporary directory.
+func newTestCache(t *testing.T, ttls TTLs) (*API, *togglapitest.API, string) {
+	directory, err := ioutil.TempDir("", "togglapi-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(directory) })
+
+	api := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Acme"})
+	path := filepath.Join(directory, "cache.json")
+	return New(api, NewFileStore(path), ttls), api, path
+}
+
+// countCalls returns how often the given method has been called.
+func countCalls(api *togglapitest.API, method string) int {
+	count := 0
+	for _, call := range api.Calls {
+		if call == method {
+			count++
+		}
+	}
+
+	return count
+}
+
+func Test_GetWorkspaces_CalledTwice_APIIsCalledOnce(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, DefaultTTLs())
+
+	// act
+	cachedAPI.GetWorkspaces()
+	workspaces, err := cachedAPI.GetWorkspaces()
+
+	// assert
+	if err != nil || len(workspaces) != 1 || countCalls(api, "GetWorkspaces") != 1 {
+		t.Fail()
+		t.Logf("GetWorkspaces should have been served from the cache (Calls: %v, Error: %v)", api.Calls, err)
+	}
+}
+
+func Test_GetProjects_TTLExpired_APIIsCalledAgain(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, DefaultTTLs())
+	now := time.Now()
+	cachedAPI.now = func() time.Time { return now }
+
+	// act
+	cachedAPI.GetProjects(1)
+	now = now.Add(DefaultTTLs().Projects + time.Second)
+	cachedAPI.GetProjects(1)
+
+	// assert
+	if countCalls(api, "GetProjects") != 2 {
+		t.Fail()
+		t.Logf("GetProjects should have fetched the projects again after the TTL expired (Calls: %v)", api.Calls)
+	}
+}
+
+func Test_GetClients_ZeroTTL_CacheIsBypassed(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, TTLs{})
+
+	// act
+	cachedAPI.GetClients()
+	cachedAPI.GetClients()
+
+	// assert
+	if countCalls(api, "GetClients") != 2 {
+		t.Fail()
+		t.Logf("GetClients should not be cached with a TTL of zero (Calls: %v)", api.Calls)
+	}
+}
+
+func Test_CreateProject_CachedProjectsAreInvalidated(t *testing.T) {
+	// arrange
+	cachedAPI, _, _ := newTestCache(t, DefaultTTLs())
+	cachedAPI.GetProjects(1)
+
+	// act
+	cachedAPI.CreateProject(model.Project{WorkspaceID: 1, Name: "Website"})
+	projects, _ := cachedAPI.GetProjects(1)
+
+	// assert
+	if len(projects) != 1 {
+		t.Fail()
+		t.Logf("GetProjects should have returned the created project but returned %v", projects)
+	}
+}
+
+func Test_DeleteTimeEntry_CachedTimeEntriesAreInvalidated(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, DefaultTTLs())
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	created, _ := api.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour)})
+	cachedAPI.GetTimeEntries(start.Add(-time.Hour), start.Add(time.Hour))
+
+	// act
+	cachedAPI.DeleteTimeEntry(created.ID)
+	timeEntries, _ := cachedAPI.GetTimeEntries(start.Add(-time.Hour), start.Add(time.Hour))
+
+	// assert
+	if len(timeEntries) != 0 {
+		t.Fail()
+		t.Logf("GetTimeEntries should not return the deleted time entry")
+	}
+}
+
+func Test_PatchTimeEntry_CachedTimeEntriesAreInvalidated(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, DefaultTTLs())
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	created, _ := api.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour)})
+	cachedAPI.GetTimeEntries(start.Add(-time.Hour), start.Add(time.Hour))
+
+	// act
+	cachedAPI.PatchTimeEntry(created.ID, model.TimeEntryChanges{Description: model.String("Review")})
+	timeEntries, _ := cachedAPI.GetTimeEntries(start.Add(-time.Hour), start.Add(time.Hour))
+
+	// assert
+	if len(timeEntries) != 1 || timeEntries[0].Description != "Review" {
+		t.Fail()
+		t.Logf("GetTimeEntries should have returned the patched time entry but returned %v", timeEntries)
+	}
+}
+
+func Test_PatchProject_CachedProjectsAreInvalidated(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, DefaultTTLs())
+	created, _ := api.CreateProject(model.Project{WorkspaceID: 1, Name: "Website"})
+	cachedAPI.GetProjects(1)
+
+	// act
+	cachedAPI.PatchProject(created.ID, model.ProjectChanges{Name: model.String("Shop")})
+	projects, _ := cachedAPI.GetProjects(1)
+
+	// assert
+	if len(projects) != 1 || projects[0].Name != "Shop" {
+		t.Fail()
+		t.Logf("GetProjects should have returned the patched project but returned %v", projects)
+	}
+}
+
+func Test_Refresh_CachedValuesAreFetchedAgain(t *testing.T) {
+	// arrange
+	cachedAPI, api, _ := newTestCache(t, DefaultTTLs())
+	cachedAPI.GetWorkspaces()
+
+	// act
+	cachedAPI.Refresh()
+	cachedAPI.GetWorkspaces()
+
+	// assert
+	if countCalls(api, "GetWorkspaces") != 2 {
+		t.Fail()
+		t.Logf("GetWorkspaces should have been fetched again after a refresh (Calls: %v)", api.Calls)
+	}
+}
+
+func Test_FileStore_ValuesArePersisted(t *testing.T) {
+	// arrange
+	cachedAPI, api, path := newTestCache(t, DefaultTTLs())
+	cachedAPI.GetWorkspaces()
+
+	// act
+	otherCachedAPI := New(api, NewFileStore(path), DefaultTTLs())
+	workspaces, err := otherCachedAPI.GetWorkspaces()
+
+	// assert
+	if err != nil || len(workspaces) != 1 || workspaces[0].Name != "Acme" || countCalls(api, "GetWorkspaces") != 1 {
+		t.Fail()
+		t.Logf("The workspaces should have been read from the cache file (Calls: %v, Error: %v)", api.Calls, err)
+	}
+}
+
+func Test_GetTimeEntries_ExpiredRanges_AreRemovedFromTheStore(t *testing.T) {
+	// arrange
+	cachedAPI, _, path := newTestCache(t, DefaultTTLs())
+	now := time.Now()
+	cachedAPI.now = func() time.Time { return now }
+	start := time.Date(2016, 9, 6, 0, 0, 0, 0, time.UTC)
+
+	// act
+	cachedAPI.GetTimeEntries(start, start.Add(24*time.Hour))
+	now = now.Add(DefaultTTLs().TimeEntries + time.Second)
+	cachedAPI.GetTimeEntries(start, start.Add(48*time.Hour))
+
+	// assert
+	var timeEntries []model.TimeEntry
+	_, expiredFound, _ := NewFileStore(path).Get(fmt.Sprintf("%s%d-%d", timeEntriesKeyPrefix, start.Unix(), start.Add(24*time.Hour).Unix()), &timeEntries)
+	_, currentFound, _ := NewFileStore(path).Get(fmt.Sprintf("%s%d-%d", timeEntriesKeyPrefix, start.Unix(), start.Add(48*time.Hour).Unix()), &timeEntries)
+	if expiredFound || !currentFound {
+		t.Fail()
+		t.Logf("Only the current range should be cached (expired range found: %t, current range found: %t)", expiredFound, currentFound)
+	}
+}
+
+func Test_FileStore_TwoStoresOnSameFile_WritesAreMerged(t *testing.T) {
+	// arrange
+	directory, _ := ioutil.TempDir("", "togglapi-cache")
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "cache.json")
+	first, second := NewFileStore(path), NewFileStore(path)
+
+	// act
+	first.Set("a", 1)
+	second.Set("b", 2)
+	first.Set("c", 3)
+
+	// assert
+	for _, key := range []string{"a", "b", "c"} {
+		var value int
+		if _, found, err := NewFileStore(path).Get(key, &value); !found || err != nil {
+			t.Errorf("The value %q should have been kept (Error: %v)", key, err)
+		}
+	}
+}
+
+func Test_FileStore_CorruptFile_CacheIsDiscarded(t *testing.T) {
+	// arrange
+	directory, _ := ioutil.TempDir("", "togglapi-cache")
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "cache.json")
+	ioutil.WriteFile(path, []byte("{corrupt"), 0600)
+	store := NewFileStore(path)
+
+	// act
+	var value []string
+	_, found, err := store.Get("key", &value)
+
+	// assert
+	if err != nil || found {
+		t.Fail()
+		t.Logf("A corrupt cache file should be treated as an empty cache (Error: %v)", err)
+	}
+}
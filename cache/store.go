@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The Store interface provides functions for persisting cached values.
+type Store interface {
+	// Get reads the value stored under the given key into the given value.
+	// Returns the time the value has been stored and false if the key does not exist.
+	Get(key string, value interface{}) (time.Time, bool, error)
+
+	// Set stores the given value under the given key.
+	Set(key string, value interface{}) error
+
+	// DeletePrefix removes all values whose key starts with the given prefix.
+	DeletePrefix(prefix string) error
+
+	// DeleteStoredBefore removes all values whose key starts with the
+	// given prefix and which have been stored before the given time.
+	DeleteStoredBefore(prefix string, storedBefore time.Time) error
+}
+
+// NewFileStore creates a new store which persists all
+// values in a single JSON file at the given path.
+func NewFileStore(path string) Store {
+	return &fileStore{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// fileEntry contains a single value of the file store.
+type fileEntry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// fileStore persists values in a JSON file.
+type fileStore struct {
+	path string
+	now  func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]fileEntry
+
+	// modTime contains the modification time of the loaded file. The
+	// file is read again if another process has replaced it since.
+	modTime time.Time
+}
+
+// Get reads the value stored under the given key into the given value.
+func (store *fileStore) Get(key string, value interface{}) (time.Time, bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return time.Time{}, false, err
+	}
+
+	entry, exists := store.entries[key]
+	if !exists {
+		return time.Time{}, false, nil
+	}
+
+	if unmarshalError := json.Unmarshal(entry.Value, value); unmarshalError != nil {
+		return time.Time{}, false, errors.Wrap(unmarshalError, "Failed to deserialize the cached value")
+	}
+
+	return entry.StoredAt, true, nil
+}
+
+// Set stores the given value under the given key.
+func (store *fileStore) Set(key string, value interface{}) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+
+	content, marshalError := json.Marshal(value)
+	if marshalError != nil {
+		return errors.Wrap(marshalError, "Failed to serialize the cached value")
+	}
+
+	store.entries[key] = fileEntry{
+		StoredAt: store.now(),
+		Value:    content,
+	}
+
+	return store.save()
+}
+
+// DeletePrefix removes all values whose key starts with the given prefix.
+func (store *fileStore) DeletePrefix(prefix string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+
+	for key := range store.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(store.entries, key)
+		}
+	}
+
+	return store.save()
+}
+
+// DeleteStoredBefore removes all values whose key starts with the
+// given prefix and which have been stored before the given time.
+func (store *fileStore) DeleteStoredBefore(prefix string, storedBefore time.Time) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+
+	deleted := false
+	for key, entry := range store.entries {
+		if strings.HasPrefix(key, prefix) && entry.StoredAt.Before(storedBefore) {
+			delete(store.entries, key)
+			deleted = true
+		}
+	}
+
+	if !deleted {
+		return nil
+	}
+
+	return store.save()
+}
+
+// load reads the cache file unless the file has not changed since it has
+// been read before, so changes of other processes are not overwritten.
+// A missing cache file is treated as an empty cache.
+func (store *fileStore) load() error {
+	info, statError := os.Stat(store.path)
+	if os.IsNotExist(statError) {
+		if store.entries == nil || !store.modTime.IsZero() {
+			store.entries = make(map[string]fileEntry)
+			store.modTime = time.Time{}
+		}
+
+		return nil
+	}
+
+	if statError != nil {
+		return errors.Wrap(statError, "Failed to read the cache file")
+	}
+
+	if store.entries != nil && info.ModTime().Equal(store.modTime) {
+		return nil
+	}
+
+	store.entries = make(map[string]fileEntry)
+	store.modTime = info.ModTime()
+
+	content, readError := ioutil.ReadFile(store.path)
+
+	if readError != nil {
+		return errors.Wrap(readError, "Failed to read the cache file")
+	}
+
+	if unmarshalError := json.Unmarshal(content, &store.entries); unmarshalError != nil {
+		// a corrupt cache is discarded rather than breaking every call
+		store.entries = make(map[string]fileEntry)
+	}
+
+	return nil
+}
+
+// save writes all entries to the cache file. The file is replaced
+// atomically so concurrent readers never see a partial file.
+func (store *fileStore) save() error {
+	content, marshalError := json.Marshal(store.entries)
+	if marshalError != nil {
+		return errors.Wrap(marshalError, "Failed to serialize the cache")
+	}
+
+	directory := filepath.Dir(store.path)
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return errors.Wrap(err, "Failed to create the cache directory")
+	}
+
+	temporaryFile, createError := ioutil.TempFile(directory, filepath.Base(store.path)+".tmp")
+	if createError != nil {
+		return errors.Wrap(createError, "Failed to create the cache file")
+	}
+
+	if _, writeError := temporaryFile.Write(content); writeError != nil {
+		temporaryFile.Close()
+		os.Remove(temporaryFile.Name())
+		return errors.Wrap(writeError, "Failed to write the cache file")
+	}
+
+	if closeError := temporaryFile.Close(); closeError != nil {
+		os.Remove(temporaryFile.Name())
+		return errors.Wrap(closeError, "Failed to write the cache file")
+	}
+
+	if renameError := os.Rename(temporaryFile.Name(), store.path); renameError != nil {
+		return errors.Wrap(renameError, "Failed to replace the cache file")
+	}
+
+	if info, statError := os.Stat(store.path); statError == nil {
+		store.modTime = info.ModTime()
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package togglapi
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/andreaskoch/togglapi/model"
@@ -26,33 +27,72 @@ type ClientAPI struct {
 }
 
 // CreateClient creates a new client.
+// Returns a *model.ValidationError without sending a request if the client is invalid.
 func (repository *ClientAPI) CreateClient(client model.Client) (model.Client, error) {
+	if err := client.Validate(); err != nil {
+		return model.Client{}, err
+	}
 
-	clientRequest := struct {
-		Client model.Client `json:"client"`
+	clientModel := struct {
+		WorkspaceID int    `json:"wid"`
+		Name        string `json:"name"`
+		Notes       string `json:"notes,omitempty"`
 	}{
-		Client: client,
+		WorkspaceID: client.WorkspaceID,
+		Name:        client.Name,
+		Notes:       client.Notes,
 	}
 
-	jsonBody, marshalError := json.Marshal(clientRequest)
-	if marshalError != nil {
-		return model.Client{}, errors.Wrap(marshalError, "Failed to serialize the client")
+	content, err := repository.sendClient(http.MethodPost, "clients", clientModel)
+	if err != nil {
+		return model.Client{}, errors.Wrap(err, "Failed to create client")
+	}
+
+	return repository.decodeClient(content, "created")
+}
+
+// UpdateClient updates the client with the ID of the given client.
+func (repository *ClientAPI) UpdateClient(client model.Client) (model.Client, error) {
+
+	clientModel := struct {
+		WorkspaceID int    `json:"wid,omitempty"`
+		Name        string `json:"name"`
+		Notes       string `json:"notes"`
+	}{
+		WorkspaceID: client.WorkspaceID,
+		Name:        client.Name,
+		Notes:       client.Notes,
 	}
 
-	content, err := repository.restClient.Request(http.MethodPost, "clients", bytes.NewBuffer(jsonBody))
+	route := fmt.Sprintf("clients/%d", client.ID)
+	content, err := repository.sendClient(http.MethodPut, route, clientModel)
 	if err != nil {
-		return model.Client{}, errors.Wrap(err, "Failed to create client")
+		return model.Client{}, errors.Wrap(err, fmt.Sprintf("Failed to update client %d", client.ID))
 	}
 
-	var clientResponse struct {
-		Client model.Client `json:"data"`
+	return repository.decodeClient(content, "updated")
+}
+
+// PatchClient changes only the given fields of the client with the given ID.
+func (repository *ClientAPI) PatchClient(id int, changes model.ClientChanges) (model.Client, error) {
+	route := fmt.Sprintf("clients/%d", id)
+	content, err := repository.sendClient(http.MethodPut, route, changes)
+	if err != nil {
+		return model.Client{}, errors.Wrap(err, fmt.Sprintf("Failed to update client %d", id))
 	}
 
-	if unmarshalError := json.Unmarshal(content, &clientResponse); unmarshalError != nil {
-		return model.Client{}, errors.Wrap(unmarshalError, "Failed to deserialize the created client")
+	return repository.decodeClient(content, "updated")
+}
+
+// DeleteClient deletes the client with the given ID.
+func (repository *ClientAPI) DeleteClient(id int) error {
+	route := fmt.Sprintf("clients/%d", id)
+
+	if _, err := repository.restClient.Request(http.MethodDelete, route, nil); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to delete client %d", id))
 	}
 
-	return clientResponse.Client, nil
+	return nil
 }
 
 // GetClients returns all clients for the given workspace.
@@ -69,3 +109,34 @@ func (repository *ClientAPI) GetClients() ([]model.Client, error) {
 
 	return clients, nil
 }
+
+// sendClient wraps the given client model into a client request
+// and sends it to the given route.
+func (repository *ClientAPI) sendClient(method, route string, clientModel interface{}) ([]byte, error) {
+	clientRequest := struct {
+		Client interface{} `json:"client"`
+	}{
+		Client: clientModel,
+	}
+
+	jsonBody, marshalError := json.Marshal(clientRequest)
+	if marshalError != nil {
+		return nil, errors.Wrap(marshalError, "Failed to serialize the client")
+	}
+
+	return repository.restClient.Request(method, route, bytes.NewBuffer(jsonBody))
+}
+
+// decodeClient deserializes the client contained in the data attribute
+// of the given API response. The action (e.g. "created") is used in errors.
+func (repository *ClientAPI) decodeClient(content []byte, action string) (model.Client, error) {
+	var clientResponse struct {
+		Client model.Client `json:"data"`
+	}
+
+	if unmarshalError := json.Unmarshal(content, &clientResponse); unmarshalError != nil {
+		return model.Client{}, errors.Wrap(unmarshalError, fmt.Sprintf("Failed to deserialize the %s client", action))
+	}
+
+	return clientResponse.Client, nil
+}
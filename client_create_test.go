@@ -21,7 +21,7 @@ func Test_CreateClient_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Client{}
+	input := model.Client{WorkspaceID: 1, Name: "Client A"}
 
 	// act
 	_, err := clientAPI.CreateClient(input)
@@ -47,7 +47,7 @@ func Test_CreateClient_InvalidJSONIsReturned_ErrorIsReturned(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Client{}
+	input := model.Client{WorkspaceID: 1, Name: "Client A"}
 
 	// act
 	_, err := clientAPI.CreateClient(input)
@@ -73,7 +73,7 @@ func Test_CreateClient_NoClientsReturned_EmptyClientIsReturned(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Client{}
+	input := model.Client{WorkspaceID: 1, Name: "Client A"}
 
 	// act
 	_, err := clientAPI.CreateClient(input)
@@ -103,7 +103,7 @@ func Test_CreateClient_HTTPMethodIsPOST(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Client{}
+	input := model.Client{WorkspaceID: 1, Name: "Client A"}
 
 	// act
 	clientAPI.CreateClient(input)
@@ -129,7 +129,7 @@ func Test_CreateClient_ValidJSONIsReturned_CreatedClientIsReturned(t *testing.T)
 		restClient: restClient,
 	}
 
-	input := model.Client{}
+	input := model.Client{WorkspaceID: 1, Name: "Client A"}
 
 	// act
 	client, err := clientAPI.CreateClient(input)
@@ -145,3 +145,26 @@ func Test_CreateClient_ValidJSONIsReturned_CreatedClientIsReturned(t *testing.T)
 		}
 	}
 }
+
+func Test_CreateClient_InvalidClient_NoRequestIsSent(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			t.Errorf("CreateClient should not send a request for an invalid client")
+			return nil, nil
+		},
+	}
+
+	clientAPI := &ClientAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := clientAPI.CreateClient(model.Client{Name: "Client A"})
+
+	// assert
+	if _, ok := err.(*model.ValidationError); !ok {
+		t.Fail()
+		t.Logf("CreateClient should have returned a validation error but returned %v", err)
+	}
+}
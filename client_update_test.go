@@ -0,0 +1,114 @@
+package togglapi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_UpdateClient_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	clientAPI := &ClientAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := clientAPI.UpdateClient(model.Client{ID: 1})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("UpdateClient should return an error if the REST client returned an error")
+	}
+}
+
+func Test_UpdateClient_PUTRequestIsSentToClientRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "PUT" || route != "clients/1239455" {
+				t.Fail()
+				t.Logf("UpdateClient should send a PUT request to clients/1239455 but sent %s %s", method, route)
+			}
+
+			return []byte(`{"data": {"id": 1239455, "name": "Very Big Company"}}`), nil
+		},
+	}
+
+	clientAPI := &ClientAPI{
+		restClient: restClient,
+	}
+
+	// act
+	client, err := clientAPI.UpdateClient(model.Client{ID: 1239455, Name: "Very Big Company"})
+
+	// assert
+	if err != nil || client.Name != "Very Big Company" {
+		t.Fail()
+		t.Logf("UpdateClient should have returned the updated client (Error: %v)", err)
+	}
+}
+
+func Test_DeleteClient_DELETERequestIsSentToClientRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "DELETE" || route != "clients/1239455" {
+				t.Fail()
+				t.Logf("DeleteClient should send a DELETE request to clients/1239455 but sent %s %s", method, route)
+			}
+
+			return nil, nil
+		},
+	}
+
+	clientAPI := &ClientAPI{
+		restClient: restClient,
+	}
+
+	// act
+	err := clientAPI.DeleteClient(1239455)
+
+	// assert
+	if err != nil {
+		t.Fail()
+		t.Logf("DeleteClient should not have returned an error: %s", err)
+	}
+}
+
+func Test_PatchClient_OnlyChangedFieldsAreSent(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 3}}`), nil
+		},
+	}
+
+	clientAPI := &ClientAPI{
+		restClient: restClient,
+	}
+
+	// act
+	clientAPI.PatchClient(3, model.ClientChanges{Notes: model.String("")})
+
+	// assert
+	expected := `{"client":{"notes":""}}`
+	if body != expected {
+		t.Fail()
+		t.Logf("PatchClient should have sent %s but sent %s", expected, body)
+	}
+}
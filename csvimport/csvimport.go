@@ -0,0 +1,255 @@
+// Package csvimport imports time entries from CSV files.
+//
+// The columns of the CSV file are mapped to the fields of a time entry
+// (date, start, end or duration, description, project, client, tags and
+// billable). Project and client names are resolved to IDs and missing
+// projects and clients can be created on the fly. Every import is planned
+// first so the plan can be reviewed (dry run) before it is applied.
+package csvimport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// The names of the time entry fields which can be mapped to CSV columns.
+const (
+	FieldDate        = "date"
+	FieldStart       = "start"
+	FieldEnd         = "end"
+	FieldDuration    = "duration"
+	FieldDescription = "description"
+	FieldProject     = "project"
+	FieldClient      = "client"
+	FieldTags        = "tags"
+	FieldBillable    = "billable"
+)
+
+// fields contains the names of all mappable fields.
+var fields = []string{
+	FieldDate,
+	FieldStart,
+	FieldEnd,
+	FieldDuration,
+	FieldDescription,
+	FieldProject,
+	FieldClient,
+	FieldTags,
+	FieldBillable,
+}
+
+// Mapping maps the time entry fields to the CSV column headers.
+// Fields which are not mapped are read from the column with the
+// same name as the field (e.g. "description"). Header names are
+// compared case-insensitively.
+type Mapping map[string]string
+
+// ParseMapping parses a comma separated list of field=column
+// pairs (e.g. "date=Day,description=Task").
+func ParseMapping(value string) (Mapping, error) {
+	mapping := make(Mapping)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid column mapping %q (expected field=column)", pair)
+		}
+
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !isField(field) {
+			return nil, fmt.Errorf("Unknown field %q (available: %s)", field, strings.Join(fields, ", "))
+		}
+
+		mapping[field] = strings.TrimSpace(parts[1])
+	}
+
+	return mapping, nil
+}
+
+// column returns the column header of the given field.
+func (mapping Mapping) column(field string) string {
+	if column, exists := mapping[field]; exists {
+		return column
+	}
+
+	return field
+}
+
+// isField returns true if the given name is a mappable field.
+func isField(name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Options contains the settings of an import.
+type Options struct {
+	// WorkspaceID contains the ID of the workspace the entries are imported into.
+	WorkspaceID int
+
+	// Mapping maps the time entry fields to the CSV column headers.
+	Mapping Mapping
+
+	// Comma contains the field delimiter (default: ',').
+	Comma rune
+
+	// DateLayout contains the layout of the date column (default: "2006-01-02").
+	DateLayout string
+
+	// TimeLayout contains the layout of the start and end columns (default: "15:04").
+	TimeLayout string
+
+	// TagSeparator separates the tags in the tags column (default: ",").
+	TagSeparator string
+
+	// Location is used for the dates and times of the CSV file (default: time.Local).
+	Location *time.Location
+
+	// CreateMissing defines whether unknown projects and clients are created.
+	CreateMissing bool
+}
+
+// Importer imports time entries from CSV files.
+type Importer struct {
+	api     model.TogglAPI
+	options Options
+}
+
+// New creates a new importer which uses the given API for resolving
+// and creating projects, clients and time entries.
+func New(api model.TogglAPI, options Options) *Importer {
+	if options.Comma == 0 {
+		options.Comma = ','
+	}
+
+	if options.DateLayout == "" {
+		options.DateLayout = "2006-01-02"
+	}
+
+	if options.TimeLayout == "" {
+		options.TimeLayout = "15:04"
+	}
+
+	if options.TagSeparator == "" {
+		options.TagSeparator = ","
+	}
+
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	return &Importer{
+		api:     api,
+		options: options,
+	}
+}
+
+// Plan contains the time entries, projects and clients
+// which will be created by an import.
+type Plan struct {
+	WorkspaceID int
+
+	// Entries contains one planned time entry per CSV row.
+	Entries []PlannedEntry
+
+	// Clients contains the names of the clients which will be created.
+	Clients []string
+
+	// Projects contains the projects which will be created.
+	Projects []PlannedProject
+}
+
+// PlannedEntry contains a time entry read from a single CSV row.
+type PlannedEntry struct {
+	// Line contains the line number of the row in the CSV file.
+	Line int
+
+	// TimeEntry contains the time entry. The project ID is
+	// zero if the project has yet to be created.
+	TimeEntry model.TimeEntry
+
+	ProjectName string
+	ClientName  string
+
+	// Problems contains the validation errors of the row.
+	Problems []string
+}
+
+// PlannedProject contains a project which will be created.
+type PlannedProject struct {
+	Name string
+
+	// ClientID contains the ID of an existing client and ClientName
+	// the name of the client, which might have yet to be created.
+	ClientID   int
+	ClientName string
+}
+
+// Valid returns true if none of the planned entries has problems.
+func (plan Plan) Valid() bool {
+	for _, entry := range plan.Entries {
+		if len(entry.Problems) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Write prints a human readable summary of the plan to the given writer.
+func (plan Plan) Write(w io.Writer) error {
+	for _, name := range plan.Clients {
+		if _, err := fmt.Fprintf(w, "create client  %q\n", name); err != nil {
+			return err
+		}
+	}
+
+	for _, project := range plan.Projects {
+		if _, err := fmt.Fprintf(w, "create project %q (client: %q)\n", project.Name, project.ClientName); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range plan.Entries {
+		if len(entry.Problems) > 0 {
+			if _, err := fmt.Fprintf(w, "line %d: %s\n", entry.Line, strings.Join(entry.Problems, "; ")); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		timeEntry := entry.TimeEntry
+		if _, err := fmt.Fprintf(w, "create entry   line %d: %s - %s %q project=%q tags=%q billable=%t\n",
+			entry.Line,
+			timeEntry.Start.Format("2006-01-02 15:04"),
+			timeEntry.Stop.Format("15:04"),
+			timeEntry.Description,
+			entry.ProjectName,
+			strings.Join(timeEntry.Tags, ","),
+			timeEntry.Billable,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Result contains the entities created by an import.
+type Result struct {
+	Clients     []model.Client
+	Projects    []model.Project
+	TimeEntries []model.TimeEntry
+}
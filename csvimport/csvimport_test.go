@@ -0,0 +1,197 @@
+package csvimport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// newTestAPI returns an in-memory API with one workspace, client and project.
+func newTestAPI() *togglapitest.API {
+	api := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Acme"})
+	api.Clients = []model.Client{{ID: 10, WorkspaceID: 1, Name: "Globex"}}
+	api.Projects = []model.Project{{ID: 100, WorkspaceID: 1, ClientID: 10, Name: "Website"}}
+	return api
+}
+
+func Test_ParseMapping_UnknownField_ErrorIsReturned(t *testing.T) {
+	// act
+	_, err := ParseMapping("date=Day,color=Color")
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("ParseMapping should return an error for unknown fields")
+	}
+}
+
+func Test_Plan_MappedColumns_EntriesAreParsed(t *testing.T) {
+	// arrange
+	csvFile := `Day;From;To;Task;Project;Labels;Billable
+2016-09-06;08:00;09:30;Layout;website;design, web;yes
+2016-09-06;23:00;01:00;Deployment;Website;;no
+`
+	mapping, _ := ParseMapping("date=Day,start=From,end=To,description=Task,tags=Labels")
+	importer := New(newTestAPI(), Options{
+		WorkspaceID: 1,
+		Mapping:     mapping,
+		Comma:       ';',
+		Location:    time.UTC,
+	})
+
+	// act
+	plan, err := importer.Plan(strings.NewReader(csvFile))
+
+	// assert
+	if err != nil || !plan.Valid() || len(plan.Entries) != 2 {
+		t.Fatalf("Plan should have returned two valid entries but returned %#v (Error: %v)", plan, err)
+	}
+
+	first := plan.Entries[0].TimeEntry
+	if first.Pid != 100 || first.Duration != 5400 || len(first.Tags) != 2 || !first.Billable || first.Description != "Layout" {
+		t.Fail()
+		t.Logf("The first entry was not parsed correctly: %#v", first)
+	}
+
+	second := plan.Entries[1].TimeEntry
+	if second.Stop.Day() != 7 || second.Duration != 7200 {
+		t.Fail()
+		t.Logf("Entries ending before they start should span midnight: %#v", second)
+	}
+}
+
+func Test_Plan_DurationColumn_StopIsCalculated(t *testing.T) {
+	// arrange
+	csvFile := `date,start,duration,description
+2016-09-06,08:00,1:15,a
+2016-09-06,10:00,0.5,b
+2016-09-06,11:00,2h,c
+`
+	importer := New(newTestAPI(), Options{WorkspaceID: 1, Location: time.UTC})
+
+	// act
+	plan, err := importer.Plan(strings.NewReader(csvFile))
+
+	// assert
+	expectedDurations := []int{4500, 1800, 7200}
+	if err != nil || len(plan.Entries) != 3 {
+		t.Fatalf("Plan should have returned three entries (Error: %v)", err)
+	}
+
+	for index, entry := range plan.Entries {
+		if entry.TimeEntry.Duration != expectedDurations[index] {
+			t.Fail()
+			t.Logf("Entry %d should have a duration of %d seconds but has %d", index, expectedDurations[index], entry.TimeEntry.Duration)
+		}
+	}
+}
+
+func Test_Plan_MissingEndAndDurationColumns_ErrorIsReturned(t *testing.T) {
+	// arrange
+	importer := New(newTestAPI(), Options{WorkspaceID: 1})
+
+	// act
+	_, err := importer.Plan(strings.NewReader("date,start,description\n2016-09-06,08:00,a\n"))
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("Plan should return an error if the end and duration columns are missing")
+	}
+}
+
+func Test_Plan_InvalidRows_ProblemsAreReported(t *testing.T) {
+	// arrange
+	csvFile := `date,start,end,project
+06.09.2016,08:00,09:00,
+2016-09-06,08:00,08:00,
+2016-09-06,08:00,09:00,Unknown
+`
+	importer := New(newTestAPI(), Options{WorkspaceID: 1})
+
+	// act
+	plan, _ := importer.Plan(strings.NewReader(csvFile))
+
+	// assert
+	if plan.Valid() || len(plan.Entries) != 3 {
+		t.Fatalf("Plan should have reported three invalid entries")
+	}
+
+	for _, entry := range plan.Entries {
+		if len(entry.Problems) == 0 {
+			t.Fail()
+			t.Logf("Line %d should have a problem", entry.Line)
+		}
+	}
+}
+
+func Test_Apply_CreateMissing_ClientsProjectsAndEntriesAreCreated(t *testing.T) {
+	// arrange
+	csvFile := `date,start,end,project,client,description
+2016-09-06,08:00,09:00,App,Initech,Kickoff
+2016-09-07,08:00,09:00,app,initech,Planning
+2016-09-07,10:00,11:00,Website,Globex,Review
+`
+	api := newTestAPI()
+	importer := New(api, Options{WorkspaceID: 1, CreateMissing: true, Location: time.UTC})
+	plan, _ := importer.Plan(strings.NewReader(csvFile))
+
+	// act
+	result, err := importer.Apply(plan)
+
+	// assert
+	if err != nil || len(result.Clients) != 1 || len(result.Projects) != 1 || len(result.TimeEntries) != 3 {
+		t.Fatalf("Apply should have created 1 client, 1 project and 3 entries but created %d, %d and %d (Error: %v)",
+			len(result.Clients), len(result.Projects), len(result.TimeEntries), err)
+	}
+
+	if result.Projects[0].ClientID != result.Clients[0].ID {
+		t.Fail()
+		t.Logf("The created project should belong to the created client")
+	}
+
+	if result.TimeEntries[0].Pid != result.Projects[0].ID || result.TimeEntries[2].Pid != 100 {
+		t.Fail()
+		t.Logf("The created entries should reference the resolved projects: %#v", result.TimeEntries)
+	}
+}
+
+func Test_Apply_InvalidPlan_NothingIsCreated(t *testing.T) {
+	// arrange
+	api := newTestAPI()
+	importer := New(api, Options{WorkspaceID: 1})
+	plan, _ := importer.Plan(strings.NewReader("date,start,end,project\n2016-09-06,08:00,09:00,Unknown\n"))
+
+	// act
+	_, err := importer.Apply(plan)
+
+	// assert
+	if err == nil || len(api.TimeEntries) != 0 {
+		t.Fail()
+		t.Logf("Apply should not create entries if the plan is invalid")
+	}
+}
+
+func Test_Write_DryRun_PlanIsPrinted(t *testing.T) {
+	// arrange
+	importer := New(newTestAPI(), Options{WorkspaceID: 1, CreateMissing: true, Location: time.UTC})
+	plan, _ := importer.Plan(strings.NewReader("date,start,end,project,client\n2016-09-06,08:00,09:00,App,Initech\n"))
+	output := &bytes.Buffer{}
+
+	// act
+	plan.Write(output)
+
+	// assert
+	expected := `create client  "Initech"
+create project "App" (client: "Initech")
+create entry   line 2: 2016-09-06 08:00 - 09:00 "" project="App" tags="" billable=false
+`
+	if output.String() != expected {
+		t.Fail()
+		t.Logf("Write should have printed\n%s\nbut printed\n%s", expected, output.String())
+	}
+}
@@ -0,0 +1,433 @@
+package csvimport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// Plan reads the time entries from the given CSV file, resolves the project
+// and client names and validates the entries. Returns an error if the file
+// could not be read or required columns are missing. Problems with individual
+// rows are reported in the plan.
+func (importer *Importer) Plan(r io.Reader) (Plan, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = importer.options.Comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, headerError := reader.Read()
+	if headerError != nil {
+		return Plan{}, errors.Wrap(headerError, "Failed to read the CSV header")
+	}
+
+	columns, columnsError := importer.getColumnIndexes(header)
+	if columnsError != nil {
+		return Plan{}, columnsError
+	}
+
+	resolver, resolverError := newNameResolver(importer.api, importer.options.WorkspaceID)
+	if resolverError != nil {
+		return Plan{}, resolverError
+	}
+
+	plan := Plan{WorkspaceID: importer.options.WorkspaceID}
+	for {
+		row, readError := reader.Read()
+		if readError == io.EOF {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if readError != nil {
+			return Plan{}, errors.Wrap(readError, fmt.Sprintf("Failed to read line %d", line))
+		}
+
+		if isEmptyRow(row) {
+			continue
+		}
+
+		values := make(map[string]string)
+		for field, index := range columns {
+			if index < len(row) {
+				values[field] = strings.TrimSpace(row[index])
+			}
+		}
+
+		entry := importer.parseEntry(values)
+		entry.Line = line
+
+		if entry.ProjectName != "" {
+			importer.resolveProject(&plan, &entry, resolver)
+		}
+
+		plan.Entries = append(plan.Entries, entry)
+	}
+
+	return plan, nil
+}
+
+// Apply creates the clients, projects and time entries of the given plan.
+// Returns the created entities and an error if the plan is invalid or
+// one of the create calls failed.
+func (importer *Importer) Apply(plan Plan) (Result, error) {
+	if !plan.Valid() {
+		return Result{}, fmt.Errorf("The import plan contains invalid entries")
+	}
+
+	var result Result
+
+	clientIDs := make(map[string]int)
+	for _, name := range plan.Clients {
+		client, createError := importer.api.CreateClient(model.Client{
+			WorkspaceID: plan.WorkspaceID,
+			Name:        name,
+		})
+
+		if createError != nil {
+			return result, errors.Wrap(createError, fmt.Sprintf("Failed to create client %q", name))
+		}
+
+		clientIDs[normalize(name)] = client.ID
+		result.Clients = append(result.Clients, client)
+	}
+
+	projectIDs := make(map[string]int)
+	for _, plannedProject := range plan.Projects {
+		project := model.Project{
+			WorkspaceID: plan.WorkspaceID,
+			ClientID:    plannedProject.ClientID,
+			Name:        plannedProject.Name,
+		}
+
+		if project.ClientID == 0 && plannedProject.ClientName != "" {
+			project.ClientID = clientIDs[normalize(plannedProject.ClientName)]
+		}
+
+		createdProject, createError := importer.api.CreateProject(project)
+		if createError != nil {
+			return result, errors.Wrap(createError, fmt.Sprintf("Failed to create project %q", plannedProject.Name))
+		}
+
+		projectIDs[projectKey(plannedProject.Name, plannedProject.ClientName)] = createdProject.ID
+		result.Projects = append(result.Projects, createdProject)
+	}
+
+	for _, entry := range plan.Entries {
+		timeEntry := entry.TimeEntry
+		if timeEntry.Pid == 0 && entry.ProjectName != "" {
+			timeEntry.Pid = projectIDs[projectKey(entry.ProjectName, entry.ClientName)]
+		}
+
+		createdTimeEntry, createError := importer.api.CreateTimeEntry(timeEntry)
+		if createError != nil {
+			return result, errors.Wrap(createError, fmt.Sprintf("Failed to create the time entry of line %d", entry.Line))
+		}
+
+		result.TimeEntries = append(result.TimeEntries, createdTimeEntry)
+	}
+
+	return result, nil
+}
+
+// getColumnIndexes returns the column index of each mapped field.
+// Returns an error if a required column is missing.
+func (importer *Importer) getColumnIndexes(header []string) (map[string]int, error) {
+	headerIndexes := make(map[string]int)
+	for index, name := range header {
+		headerIndexes[normalize(name)] = index
+	}
+
+	columns := make(map[string]int)
+	for _, field := range fields {
+		column := importer.options.Mapping.column(field)
+		if index, exists := headerIndexes[normalize(column)]; exists {
+			columns[field] = index
+		}
+	}
+
+	for _, field := range []string{FieldDate, FieldStart} {
+		if _, exists := columns[field]; !exists {
+			return nil, fmt.Errorf("The CSV file has no %s column (%q)", field, importer.options.Mapping.column(field))
+		}
+	}
+
+	_, hasEnd := columns[FieldEnd]
+	_, hasDuration := columns[FieldDuration]
+	if !hasEnd && !hasDuration {
+		return nil, fmt.Errorf("The CSV file needs an end (%q) or a duration (%q) column",
+			importer.options.Mapping.column(FieldEnd),
+			importer.options.Mapping.column(FieldDuration),
+		)
+	}
+
+	return columns, nil
+}
+
+// parseEntry creates a planned time entry from the given field values.
+func (importer *Importer) parseEntry(values map[string]string) PlannedEntry {
+	entry := PlannedEntry{
+		ProjectName: values[FieldProject],
+		ClientName:  values[FieldClient],
+	}
+
+	timeEntry := model.TimeEntry{
+		Wid:         importer.options.WorkspaceID,
+		Description: values[FieldDescription],
+	}
+
+	day, dateError := time.ParseInLocation(importer.options.DateLayout, values[FieldDate], importer.options.Location)
+	if dateError != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("invalid date %q", values[FieldDate]))
+	}
+
+	start, startError := importer.parseTimeOfDay(day, values[FieldStart])
+	if startError != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("invalid start time %q", values[FieldStart]))
+	}
+
+	timeEntry.Start = start
+
+	switch {
+	case values[FieldEnd] != "":
+		stop, stopError := importer.parseTimeOfDay(day, values[FieldEnd])
+		if stopError != nil {
+			entry.Problems = append(entry.Problems, fmt.Sprintf("invalid end time %q", values[FieldEnd]))
+			break
+		}
+
+		// entries ending before they start span midnight
+		if stop.Before(start) {
+			stop = stop.AddDate(0, 0, 1)
+		}
+
+		timeEntry.Stop = stop
+
+	case values[FieldDuration] != "":
+		duration, durationError := parseDuration(values[FieldDuration])
+		if durationError != nil {
+			entry.Problems = append(entry.Problems, fmt.Sprintf("invalid duration %q", values[FieldDuration]))
+			break
+		}
+
+		timeEntry.Stop = start.Add(duration)
+
+	default:
+		entry.Problems = append(entry.Problems, "end time or duration missing")
+	}
+
+	if len(entry.Problems) == 0 && !timeEntry.Stop.After(timeEntry.Start) {
+		entry.Problems = append(entry.Problems, "the duration must be greater than zero")
+	}
+
+	timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+
+	for _, tag := range strings.Split(values[FieldTags], importer.options.TagSeparator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			timeEntry.Tags = append(timeEntry.Tags, tag)
+		}
+	}
+
+	if values[FieldBillable] != "" {
+		billable, billableError := parseBool(values[FieldBillable])
+		if billableError != nil {
+			entry.Problems = append(entry.Problems, fmt.Sprintf("invalid billable flag %q", values[FieldBillable]))
+		}
+
+		timeEntry.Billable = billable
+	}
+
+	entry.TimeEntry = timeEntry
+	return entry
+}
+
+// parseTimeOfDay returns the given time of the given day.
+func (importer *Importer) parseTimeOfDay(day time.Time, value string) (time.Time, error) {
+	parsed, err := time.ParseInLocation(importer.options.TimeLayout, value, importer.options.Location)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, date := day.Date()
+	return time.Date(year, month, date, parsed.Hour(), parsed.Minute(), parsed.Second(), 0, importer.options.Location), nil
+}
+
+// resolveProject sets the project ID of the given entry or adds the
+// project (and its client) to the list of projects to create.
+func (importer *Importer) resolveProject(plan *Plan, entry *PlannedEntry, resolver *nameResolver) {
+	project, projectExists, projectError := resolver.findProject(entry.ProjectName, entry.ClientName)
+	if projectError != nil {
+		entry.Problems = append(entry.Problems, projectError.Error())
+		return
+	}
+
+	if projectExists {
+		entry.TimeEntry.Pid = project.ID
+		return
+	}
+
+	if !importer.options.CreateMissing {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("unknown project %q", entry.ProjectName))
+		return
+	}
+
+	plannedProject := PlannedProject{
+		Name:       entry.ProjectName,
+		ClientName: entry.ClientName,
+	}
+
+	if entry.ClientName != "" {
+		client, clientExists := resolver.findClient(entry.ClientName)
+		if clientExists {
+			plannedProject.ClientID = client.ID
+		} else if !containsName(plan.Clients, entry.ClientName) {
+			plan.Clients = append(plan.Clients, entry.ClientName)
+		}
+	}
+
+	for _, existingProject := range plan.Projects {
+		if projectKey(existingProject.Name, existingProject.ClientName) == projectKey(plannedProject.Name, plannedProject.ClientName) {
+			return
+		}
+	}
+
+	plan.Projects = append(plan.Projects, plannedProject)
+}
+
+// nameResolver finds projects and clients of a workspace by name.
+type nameResolver struct {
+	projects []model.Project
+	clients  []model.Client
+}
+
+// newNameResolver loads the projects and clients of the given workspace.
+func newNameResolver(api model.TogglAPI, workspaceID int) (*nameResolver, error) {
+	projects, projectsError := api.GetProjects(workspaceID)
+	if projectsError != nil {
+		return nil, projectsError
+	}
+
+	allClients, clientsError := api.GetClients()
+	if clientsError != nil {
+		return nil, clientsError
+	}
+
+	var clients []model.Client
+	for _, client := range allClients {
+		if client.WorkspaceID == workspaceID {
+			clients = append(clients, client)
+		}
+	}
+
+	return &nameResolver{projects, clients}, nil
+}
+
+// findProject returns the project with the given name. If a client name is
+// given the project must belong to that client. Returns an error if the
+// name is ambiguous.
+func (resolver *nameResolver) findProject(name, clientName string) (model.Project, bool, error) {
+	var candidates []model.Project
+	for _, project := range resolver.projects {
+		if normalize(project.Name) != normalize(name) {
+			continue
+		}
+
+		if clientName != "" {
+			client, clientExists := resolver.findClient(clientName)
+			if !clientExists || client.ID != project.ClientID {
+				continue
+			}
+		}
+
+		candidates = append(candidates, project)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return model.Project{}, false, nil
+	case 1:
+		return candidates[0], true, nil
+	}
+
+	return model.Project{}, false, fmt.Errorf("%d projects are named %q, please add a client column", len(candidates), name)
+}
+
+// findClient returns the client with the given name.
+func (resolver *nameResolver) findClient(name string) (model.Client, bool) {
+	for _, client := range resolver.clients {
+		if normalize(client.Name) == normalize(name) {
+			return client, true
+		}
+	}
+
+	return model.Client{}, false
+}
+
+// parseDuration parses durations like "1:30", "1.5" (hours) or "1h30m".
+func parseDuration(value string) (time.Duration, error) {
+	if parts := strings.Split(value, ":"); len(parts) == 2 {
+		hours, hoursError := strconv.Atoi(parts[0])
+		minutes, minutesError := strconv.Atoi(parts[1])
+		if hoursError != nil || minutesError != nil || minutes >= 60 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+
+		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+	}
+
+	if hours, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64); err == nil {
+		return time.Duration(hours * float64(time.Hour)).Round(time.Second), nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// parseBool parses boolean values like "yes", "no", "x", "true" or "1".
+func parseBool(value string) (bool, error) {
+	switch normalize(value) {
+	case "yes", "y", "x", "true", "1":
+		return true, nil
+	case "no", "n", "false", "0", "-":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// isEmptyRow returns true if all values of the given row are empty.
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsName returns true if the given list contains the given name.
+func containsName(names []string, name string) bool {
+	for _, existingName := range names {
+		if normalize(existingName) == normalize(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// projectKey returns a key identifying a project by its name and client name.
+func projectKey(name, clientName string) string {
+	return normalize(name) + "\x00" + normalize(clientName)
+}
+
+// normalize returns the lower-case version of the given name without surrounding white space.
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
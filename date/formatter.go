@@ -2,7 +2,12 @@
 // dates according the requirements of Toggl (ISO 8601).
 package date
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
 
 // A Formatter interface provides functions for parsing and formatting dates.
 type Formatter interface {
@@ -19,20 +24,86 @@ func NewISO8601Formatter() Formatter {
 	return &iso80601Formatter{}
 }
 
+// NewISO8601FormatterInLocation creates a new ISO 8601 date formatter which
+// formats dates in the given location and returns parsed dates in it.
+func NewISO8601FormatterInLocation(location *time.Location) Formatter {
+	return &iso80601Formatter{location: location}
+}
+
 // iso8601DateFormat contains the date format for ISO 8601
 const iso8601DateFormat = "2006-01-02T15:04:05-07:00"
 
 // iso80601Formatter parses and formats ISO 8061 dates.
 type iso80601Formatter struct {
+	// location contains the optional location of formatted and parsed dates.
+	location *time.Location
 }
 
 // GetDateString returns an ISO 8601 formatted date from the given time.Time object.
-func (iso80601Formatter) GetDateString(date time.Time) string {
+func (formatter iso80601Formatter) GetDateString(date time.Time) string {
+	if formatter.location != nil {
+		date = date.In(formatter.location)
+	}
+
 	return date.Format(iso8601DateFormat)
 }
 
+// iso8601Layouts contains the accepted layouts of ISO 8601 dates. Times of
+// day require a zone ("Z" or an offset with or without colon); fractional
+// seconds are accepted by all layouts.
+var iso8601Layouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05Z07",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"20060102T150405Z0700",
+	"2006-01-02",
+	"20060102",
+}
+
+// iso8601WeekPattern matches ISO 8601 week dates (e.g. 2016-W36-2 or 2016-W36).
+var iso8601WeekPattern = regexp.MustCompile(`^(\d{4})-?W(\d{2})(?:-?([1-7]))?$`)
+
 // GetDate returns a time.Time model for the given date ISO 8601 date string.
+// Besides the format produced by GetDateString it accepts the "Z" suffix,
+// fractional seconds, offsets without colon, calendar dates (e.g. 2016-09-06)
+// and week dates (e.g. 2016-W36-2 or 2016-W36 for the Monday). Dates without
+// a time of day are returned as midnight UTC. Formatters with a location
+// return all dates in that location and dates without a time of day as
+// midnight of that location.
 // Returns an error of the date could not be parsed.
-func (iso80601Formatter) GetDate(date string) (time.Time, error) {
-	return time.Parse(iso8601DateFormat, date)
+func (formatter iso80601Formatter) GetDate(date string) (time.Time, error) {
+	location := formatter.location
+	if location == nil {
+		location = time.UTC
+	}
+
+	for _, layout := range iso8601Layouts {
+		if parsed, err := time.ParseInLocation(layout, date, location); err == nil {
+			if formatter.location != nil {
+				parsed = parsed.In(formatter.location)
+			}
+
+			return parsed, nil
+		}
+	}
+
+	if matches := iso8601WeekPattern.FindStringSubmatch(date); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		week, _ := strconv.Atoi(matches[2])
+		start, err := isoWeekStart(year, week, location)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if matches[3] != "" {
+			weekday, _ := strconv.Atoi(matches[3])
+			start = start.AddDate(0, 0, weekday-1)
+		}
+
+		return start, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a valid ISO 8601 date (e.g. 2016-09-06T08:00:00Z)", date)
 }
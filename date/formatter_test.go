@@ -145,3 +145,196 @@ func Test_GetDate_ValidISO8601DatesGiven_DatesAreParsed(t *testing.T) {
 	}
 
 }
+
+func Test_GetDate_ISO8601VariantsGiven_DatesAreParsed(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		DateString     string
+		ExpectedResult time.Time
+	}{
+		{
+			DateString:     "2026-10-18T08:00:00Z",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026-10-18T08:00:00.123Z",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 123000000, time.UTC),
+		},
+
+		{
+			DateString:     "2026-10-18T10:00:00.5+02:00",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 500000000, time.UTC),
+		},
+
+		{
+			DateString:     "2026-10-18T10:00:00+0200",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026-10-18T10:00:00+02",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026-10-18 08:00:00Z",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "20261018T080000Z",
+			ExpectedResult: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026-10-18",
+			ExpectedResult: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026-W42-7",
+			ExpectedResult: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026W421",
+			ExpectedResult: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+		},
+
+		{
+			DateString:     "2026-W01",
+			ExpectedResult: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	dateFormatter := iso80601Formatter{}
+
+	for _, input := range inputs {
+
+		// act
+		result, err := dateFormatter.GetDate(input.DateString)
+
+		// assert
+		if err != nil || !result.Equal(input.ExpectedResult) {
+			t.Fail()
+			t.Logf("GetDate(%q) should have returned %q but returned %q (%v) instead.", input.DateString, input.ExpectedResult, result, err)
+		}
+	}
+
+}
+
+func Test_GetDate_InvalidWeekDatesGiven_ErrorIsReturned(t *testing.T) {
+	// arrange
+	inputs := []string{
+		"2026-W00",
+		"2026-W54",
+		"2026-W42-8",
+		"2026-10-18T08:00:00.123",
+	}
+
+	dateFormatter := iso80601Formatter{}
+
+	for _, input := range inputs {
+
+		// act
+		_, err := dateFormatter.GetDate(input)
+
+		// assert
+		if err == nil {
+			t.Fail()
+			t.Logf("GetDate(%q) should have returned an error.", input)
+		}
+	}
+
+}
+
+func Test_GetDateString_LocationGiven_DatesAreFormattedInLocationAcrossDSTTransitions(t *testing.T) {
+	// arrange
+	berlinTimeZone, _ := time.LoadLocation("Europe/Berlin")
+
+	inputs := []struct {
+		Date           time.Time
+		ExpectedResult string
+	}{
+		{
+			Date:           time.Date(2016, 3, 27, 0, 59, 59, 0, time.UTC),
+			ExpectedResult: "2016-03-27T01:59:59+01:00",
+		},
+
+		{
+			Date:           time.Date(2016, 3, 27, 1, 0, 0, 0, time.UTC),
+			ExpectedResult: "2016-03-27T03:00:00+02:00",
+		},
+
+		{
+			Date:           time.Date(2016, 10, 30, 0, 59, 59, 0, time.UTC),
+			ExpectedResult: "2016-10-30T02:59:59+02:00",
+		},
+
+		{
+			Date:           time.Date(2016, 10, 30, 1, 0, 0, 0, time.UTC),
+			ExpectedResult: "2016-10-30T02:00:00+01:00",
+		},
+	}
+
+	dateFormatter := NewISO8601FormatterInLocation(berlinTimeZone)
+
+	for _, input := range inputs {
+
+		// act
+		result := dateFormatter.GetDateString(input.Date)
+
+		// assert
+		if result != input.ExpectedResult {
+			t.Fail()
+			t.Logf("GetDateString(%q) should have returned %q but returned %q instead.", input.Date, input.ExpectedResult, result)
+		}
+	}
+
+}
+
+func Test_GetDate_LocationGiven_DatesAreReturnedInLocation(t *testing.T) {
+	// arrange
+	berlinTimeZone, _ := time.LoadLocation("Europe/Berlin")
+
+	inputs := []struct {
+		DateString     string
+		ExpectedResult string
+	}{
+		{
+			DateString:     "2016-10-30T01:30:00Z",
+			ExpectedResult: "2016-10-30T02:30:00+01:00",
+		},
+
+		{
+			DateString:     "2016-10-30T00:30:00Z",
+			ExpectedResult: "2016-10-30T02:30:00+02:00",
+		},
+
+		{
+			DateString:     "2016-03-27",
+			ExpectedResult: "2016-03-27T00:00:00+01:00",
+		},
+
+		{
+			DateString:     "2016-W13-1",
+			ExpectedResult: "2016-03-28T00:00:00+02:00",
+		},
+	}
+
+	dateFormatter := NewISO8601FormatterInLocation(berlinTimeZone)
+
+	for _, input := range inputs {
+
+		// act
+		result, err := dateFormatter.GetDate(input.DateString)
+
+		// assert
+		if err != nil || result.Location() != berlinTimeZone || result.Format(time.RFC3339) != input.ExpectedResult {
+			t.Fail()
+			t.Logf("GetDate(%q) should have returned %q in Berlin but returned %q (%v) instead.", input.DateString, input.ExpectedResult, result, err)
+		}
+	}
+
+}
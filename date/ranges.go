@@ -0,0 +1,194 @@
+package date
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range contains the first and the last second of a period.
+// Both values can be passed to GetTimeEntries.
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+// RangeOptions contains the settings for parsing date ranges.
+type RangeOptions struct {
+	// Location is used for the boundaries of the days. Defaults to time.Local.
+	Location *time.Location
+
+	// WeekStart contains the first day of the week. Defaults to Monday.
+	// ISO weeks (e.g. 2016-W36) always start on Monday.
+	WeekStart *time.Weekday
+
+	// Now is the reference for relative ranges. Defaults to the current time.
+	Now time.Time
+}
+
+// The patterns of the supported range expressions.
+var (
+	relativePattern = regexp.MustCompile(`^(this|last|next) (week|month|quarter|year)$`)
+	rollingPattern  = regexp.MustCompile(`^(?:last|past) (\d+) (days?|weeks?)$`)
+	weekPattern     = regexp.MustCompile(`^(\d{4})-?w(\d{2})$`)
+	quarterPattern  = regexp.MustCompile(`^(\d{4})-?q([1-4])$`)
+	monthPattern    = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	yearPattern     = regexp.MustCompile(`^(\d{4})$`)
+)
+
+// ParseRange parses a date range like "today", "yesterday", "this week",
+// "last month", "next quarter", "last 7 days" (including today), "2016-W36",
+// "2016-Q3", "2016-09", "2016" or "2016-09-06". Two expressions joined by
+// ".." (e.g. "2016-09-01..2016-09-15") span from the start of the first to
+// the end of the second range.
+func ParseRange(value string, options RangeOptions) (Range, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	weekStart := time.Monday
+	if options.WeekStart != nil {
+		weekStart = *options.WeekStart
+	}
+
+	normalized := strings.ToLower(strings.Join(strings.Fields(value), " "))
+	if parts := strings.Split(normalized, ".."); len(parts) == 2 {
+		first, firstError := ParseRange(parts[0], options)
+		if firstError != nil {
+			return Range{}, firstError
+		}
+
+		last, lastError := ParseRange(parts[1], options)
+		if lastError != nil {
+			return Range{}, lastError
+		}
+
+		if last.End.Before(first.Start) {
+			return Range{}, fmt.Errorf("The range %q ends before it starts", value)
+		}
+
+		return Range{Start: first.Start, End: last.End}, nil
+	}
+
+	now := options.Now.In(options.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, options.Location)
+
+	switch normalized {
+	case "today":
+		return newRange(today, today.AddDate(0, 0, 1)), nil
+	case "yesterday":
+		return newRange(today.AddDate(0, 0, -1), today), nil
+	case "tomorrow":
+		return newRange(today.AddDate(0, 0, 1), today.AddDate(0, 0, 2)), nil
+	}
+
+	if matches := relativePattern.FindStringSubmatch(normalized); matches != nil {
+		offset := map[string]int{"last": -1, "this": 0, "next": 1}[matches[1]]
+		switch matches[2] {
+		case "week":
+			start := today.AddDate(0, 0, -((int(today.Weekday())-int(weekStart)+7)%7)+7*offset)
+			return newRange(start, start.AddDate(0, 0, 7)), nil
+		case "month":
+			start := time.Date(today.Year(), today.Month()+time.Month(offset), 1, 0, 0, 0, 0, options.Location)
+			return newRange(start, start.AddDate(0, 1, 0)), nil
+		case "quarter":
+			firstMonth := (today.Month()-1)/3*3 + 1
+			start := time.Date(today.Year(), firstMonth+time.Month(3*offset), 1, 0, 0, 0, 0, options.Location)
+			return newRange(start, start.AddDate(0, 3, 0)), nil
+		case "year":
+			start := time.Date(today.Year()+offset, 1, 1, 0, 0, 0, 0, options.Location)
+			return newRange(start, start.AddDate(1, 0, 0)), nil
+		}
+	}
+
+	if matches := rollingPattern.FindStringSubmatch(normalized); matches != nil {
+		count, _ := strconv.Atoi(matches[1])
+		if strings.HasPrefix(matches[2], "week") {
+			count *= 7
+		}
+
+		if count < 1 {
+			return Range{}, fmt.Errorf("The range %q is empty", value)
+		}
+
+		return newRange(today.AddDate(0, 0, 1-count), today.AddDate(0, 0, 1)), nil
+	}
+
+	if matches := weekPattern.FindStringSubmatch(normalized); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		week, _ := strconv.Atoi(matches[2])
+
+		start, err := isoWeekStart(year, week, options.Location)
+		if err != nil {
+			return Range{}, err
+		}
+
+		return newRange(start, start.AddDate(0, 0, 7)), nil
+	}
+
+	if matches := quarterPattern.FindStringSubmatch(normalized); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		quarter, _ := strconv.Atoi(matches[2])
+		start := time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, options.Location)
+		return newRange(start, start.AddDate(0, 3, 0)), nil
+	}
+
+	if matches := monthPattern.FindStringSubmatch(normalized); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		month, _ := strconv.Atoi(matches[2])
+		if month < 1 || month > 12 {
+			return Range{}, fmt.Errorf("%q is not a valid month", value)
+		}
+
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, options.Location)
+		return newRange(start, start.AddDate(0, 1, 0)), nil
+	}
+
+	if matches := yearPattern.FindStringSubmatch(normalized); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, options.Location)
+		return newRange(start, start.AddDate(1, 0, 0)), nil
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", normalized, options.Location); err == nil {
+		return newRange(day, day.AddDate(0, 0, 1)), nil
+	}
+
+	return Range{}, fmt.Errorf("%q is not a valid date range (e.g. 2016-09-06, today, last week, 2016-W36, 2016-Q3 or last 7 days)", value)
+}
+
+// ParseWeekday parses an English weekday name (e.g. "monday" or "mon").
+func ParseWeekday(value string) (time.Weekday, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if normalized == name || normalized == name[:3] {
+			return weekday, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("%q is not a valid weekday (e.g. monday)", value)
+}
+
+// isoWeekStart returns the Monday of the given ISO week in the given location.
+func isoWeekStart(year, week int, location *time.Location) (time.Time, error) {
+	// the fourth of January is always in the first ISO week
+	fourth := time.Date(year, time.January, 4, 0, 0, 0, 0, location)
+	start := fourth.AddDate(0, 0, -((int(fourth.Weekday())+6)%7)+7*(week-1))
+	if isoYear, isoWeek := start.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+		return time.Time{}, fmt.Errorf("%d has no week %d", year, week)
+	}
+
+	return start, nil
+}
+
+// newRange returns the range from the given start to the second before the given end.
+func newRange(start, end time.Time) Range {
+	return Range{Start: start, End: end.Add(-time.Second)}
+}
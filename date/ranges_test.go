@@ -0,0 +1,130 @@
+package date
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ParseRange_ValidExpressions_RangesAreReturned(t *testing.T) {
+	// arrange
+	berlinTimeZone, _ := time.LoadLocation("Europe/Berlin")
+	sunday := time.Sunday
+
+	// Wednesday, 2016-09-07 in Berlin
+	options := RangeOptions{Location: berlinTimeZone, Now: time.Date(2016, 9, 7, 12, 0, 0, 0, time.UTC)}
+	sundayOptions := RangeOptions{Location: berlinTimeZone, WeekStart: &sunday, Now: options.Now}
+
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, berlinTimeZone)
+	}
+
+	inputs := []struct {
+		Value         string
+		Options       RangeOptions
+		ExpectedStart time.Time
+		ExpectedEnd   time.Time
+	}{
+		{"today", options, day(2016, 9, 7), day(2016, 9, 8)},
+		{"Yesterday", options, day(2016, 9, 6), day(2016, 9, 7)},
+		{"tomorrow", options, day(2016, 9, 8), day(2016, 9, 9)},
+		{"this week", options, day(2016, 9, 5), day(2016, 9, 12)},
+		{"this week", sundayOptions, day(2016, 9, 4), day(2016, 9, 11)},
+		{"last  week", options, day(2016, 8, 29), day(2016, 9, 5)},
+		{"next week", options, day(2016, 9, 12), day(2016, 9, 19)},
+		{"this month", options, day(2016, 9, 1), day(2016, 10, 1)},
+		{"last month", options, day(2016, 8, 1), day(2016, 9, 1)},
+		{"this quarter", options, day(2016, 7, 1), day(2016, 10, 1)},
+		{"last quarter", options, day(2016, 4, 1), day(2016, 7, 1)},
+		{"next quarter", options, day(2016, 10, 1), day(2017, 1, 1)},
+		{"last year", options, day(2015, 1, 1), day(2016, 1, 1)},
+		{"last 7 days", options, day(2016, 9, 1), day(2016, 9, 8)},
+		{"past 2 weeks", options, day(2016, 8, 25), day(2016, 9, 8)},
+		{"2016-W36", options, day(2016, 9, 5), day(2016, 9, 12)},
+		{"2015-W53", options, day(2015, 12, 28), day(2016, 1, 4)},
+		{"2016-Q3", options, day(2016, 7, 1), day(2016, 10, 1)},
+		{"2016-02", options, day(2016, 2, 1), day(2016, 3, 1)},
+		{"2016", options, day(2016, 1, 1), day(2017, 1, 1)},
+		{"2016-09-06", options, day(2016, 9, 6), day(2016, 9, 7)},
+		{"2016-09-01..yesterday", options, day(2016, 9, 1), day(2016, 9, 7)},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result, err := ParseRange(input.Value, input.Options)
+
+		// assert
+		if err != nil || !result.Start.Equal(input.ExpectedStart) || !result.End.Equal(input.ExpectedEnd.Add(-time.Second)) {
+			t.Fail()
+			t.Logf("ParseRange(%q) returned %s - %s (Error: %v) instead of %s - %s", input.Value, result.Start, result.End, err, input.ExpectedStart, input.ExpectedEnd)
+		}
+	}
+}
+
+func Test_ParseRange_DSTTransitions_DaysFollowTheLocation(t *testing.T) {
+	// arrange
+	berlinTimeZone, _ := time.LoadLocation("Europe/Berlin")
+	options := RangeOptions{Location: berlinTimeZone, Now: time.Date(2016, 10, 30, 12, 0, 0, 0, time.UTC)}
+
+	inputs := []struct {
+		Value            string
+		ExpectedStart    string
+		ExpectedDuration time.Duration
+	}{
+		{"2016-03-27", "2016-03-26T23:00:00Z", 23 * time.Hour},
+		{"today", "2016-10-29T22:00:00Z", 25 * time.Hour},
+		{"2016-W43", "2016-10-23T22:00:00Z", 7*24*time.Hour + time.Hour},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result, err := ParseRange(input.Value, options)
+
+		// assert
+		duration := result.End.Sub(result.Start) + time.Second
+		if err != nil || result.Start.UTC().Format(time.RFC3339) != input.ExpectedStart || duration != input.ExpectedDuration {
+			t.Fail()
+			t.Logf("ParseRange(%q) returned %s - %s (Error: %v) instead of %s lasting %s", input.Value, result.Start, result.End, err, input.ExpectedStart, input.ExpectedDuration)
+		}
+	}
+}
+
+func Test_ParseRange_InvalidExpressions_ErrorIsReturned(t *testing.T) {
+	inputs := []string{
+		"",
+		"someday",
+		"last decade",
+		"last 0 days",
+		"2016-W54",
+		"2016-W53",
+		"2016-Q5",
+		"2016-13",
+		"2016-09-31",
+		"today..yesterday",
+	}
+
+	for _, input := range inputs {
+
+		// act
+		_, err := ParseRange(input, RangeOptions{Location: time.UTC})
+
+		// assert
+		if err == nil {
+			t.Fail()
+			t.Logf("ParseRange(%q) should have returned an error", input)
+		}
+	}
+}
+
+func Test_ParseWeekday(t *testing.T) {
+	if weekday, err := ParseWeekday("Sun"); err != nil || weekday != time.Sunday {
+		t.Fail()
+		t.Logf("ParseWeekday returned %s (Error: %v)", weekday, err)
+	}
+
+	if _, err := ParseWeekday("someday"); err == nil {
+		t.Fail()
+		t.Logf("ParseWeekday should have rejected an invalid weekday")
+	}
+}
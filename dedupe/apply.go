@@ -0,0 +1,115 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// Log records the applied changes so they can be reverted.
+type Log struct {
+	Applied time.Time `json:"applied"`
+	Changes []Change  `json:"changes"`
+}
+
+// Apply updates the kept time entries and deletes the removed ones.
+// The returned log contains the changes which have been applied (even
+// partially) before an error occurred.
+func Apply(api model.TimeEntryAPI, changes []Change, now time.Time) (Log, error) {
+	log := Log{Applied: now}
+	for _, change := range changes {
+		applied := Change{Kind: change.Kind, Original: change.Original, Updated: change.Original}
+		if change.NeedsUpdate() {
+			if _, err := api.UpdateTimeEntry(change.Updated); err != nil {
+				return log, errors.Wrap(err, fmt.Sprintf("Failed to update time entry %d", change.Updated.ID))
+			}
+
+			applied.Updated = change.Updated
+		}
+
+		for _, removed := range change.Removed {
+			if err := api.DeleteTimeEntry(removed.ID); err != nil {
+				log.Changes = append(log.Changes, applied)
+				return log, errors.Wrap(err, fmt.Sprintf("Failed to delete time entry %d", removed.ID))
+			}
+
+			applied.Removed = append(applied.Removed, removed)
+		}
+
+		log.Changes = append(log.Changes, applied)
+	}
+
+	return log, nil
+}
+
+// Undo reverts the changes of the given log in reverse order: the kept
+// time entries are reset to their original state and the removed time
+// entries are created again (with new IDs). The removed time entries are
+// validated before anything is reverted. Returns the number of recreated
+// time entries and a log of the changes which have not been reverted yet,
+// which can be written back to retry the revert after an error.
+func Undo(api model.TimeEntryAPI, log Log) (int, Log, error) {
+	for _, change := range log.Changes {
+		for _, removed := range change.Removed {
+			removed.ID = 0
+			if err := removed.Validate(); err != nil {
+				return 0, log, errors.Wrap(err, fmt.Sprintf("Failed to recreate the time entry %q", removed.Description))
+			}
+		}
+	}
+
+	remaining := Log{Applied: log.Applied, Changes: append([]Change(nil), log.Changes...)}
+	recreated := 0
+	for index := len(remaining.Changes) - 1; index >= 0; index-- {
+		change := remaining.Changes[index]
+		if change.NeedsUpdate() {
+			if _, err := api.UpdateTimeEntry(change.Original); err != nil {
+				return recreated, remaining, errors.Wrap(err, fmt.Sprintf("Failed to restore time entry %d", change.Original.ID))
+			}
+
+			change.Updated = change.Original
+			remaining.Changes[index] = change
+		}
+
+		for len(change.Removed) > 0 {
+			removed := change.Removed[0]
+			removed.ID = 0
+			if _, err := api.CreateTimeEntry(removed); err != nil {
+				return recreated, remaining, errors.Wrap(err, fmt.Sprintf("Failed to recreate the time entry %q", removed.Description))
+			}
+
+			change.Removed = change.Removed[1:]
+			remaining.Changes[index] = change
+			recreated++
+		}
+
+		remaining.Changes = remaining.Changes[:index]
+	}
+
+	return recreated, remaining, nil
+}
+
+// WriteLog writes the given log as JSON to the given writer.
+func WriteLog(w io.Writer, log Log) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return errors.Wrap(err, "Failed to write the undo log")
+	}
+
+	return nil
+}
+
+// ReadLog reads a log written by WriteLog from the given reader.
+func ReadLog(r io.Reader) (Log, error) {
+	var log Log
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return Log{}, errors.Wrap(err, "Failed to read the undo log")
+	}
+
+	return log, nil
+}
@@ -0,0 +1,277 @@
+// Package dedupe finds duplicate time entries (e.g. left behind by
+// repeated imports or retried requests) and adjacent time entries of the
+// same task, and merges them via update and delete calls.
+//
+// Changes are planned first (Plan) and applied afterwards (Apply). Apply
+// returns a Log of the original time entries which can be used to revert
+// the changes (Undo).
+package dedupe
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// DefaultTolerance contains the default maximum difference of the start
+// and stop times of duplicate time entries.
+const DefaultTolerance = time.Minute
+
+// The kinds of changes.
+const (
+	// Duplicate changes delete time entries which are copies of the kept one.
+	Duplicate = "duplicate"
+
+	// Merge changes extend the kept time entry to the adjacent time entries
+	// of the same task and delete them.
+	Merge = "merge"
+)
+
+// Options contains the settings for planning changes.
+type Options struct {
+	// Tolerance contains the maximum difference of the start and the stop
+	// times of duplicate time entries. Defaults to DefaultTolerance.
+	Tolerance time.Duration
+
+	// Merge enables merging adjacent time entries of the same task.
+	Merge bool
+
+	// MergeGap contains the longest pause between two time entries which
+	// are merged. The pause is added to the merged time entry.
+	MergeGap time.Duration
+}
+
+// Change describes the update of a kept time entry and
+// the deletion of the time entries merged into it.
+type Change struct {
+	Kind string `json:"kind"`
+
+	// Original contains the kept time entry before the change.
+	Original model.TimeEntry `json:"original"`
+
+	// Updated contains the kept time entry after the change.
+	Updated model.TimeEntry `json:"updated"`
+
+	// Removed contains the deleted time entries.
+	Removed []model.TimeEntry `json:"removed"`
+}
+
+// NeedsUpdate returns true if the kept time entry is modified by the change.
+func (change Change) NeedsUpdate() bool {
+	original, updated := change.Original, change.Updated
+	return !original.Start.Equal(updated.Start) ||
+		!original.Stop.Equal(updated.Stop) ||
+		original.Billable != updated.Billable ||
+		!sameTags(original.Tags, updated.Tags)
+}
+
+// String returns a single line description of the change.
+func (change Change) String() string {
+	var removedIDs []int
+	for _, removed := range change.Removed {
+		removedIDs = append(removedIDs, removed.ID)
+	}
+
+	return fmt.Sprintf("%-9s keep %d %s - %s %q, delete %v",
+		change.Kind,
+		change.Updated.ID,
+		change.Updated.Start.Format("2006-01-02 15:04"),
+		change.Updated.Stop.Format("15:04"),
+		change.Updated.Description,
+		removedIDs,
+	)
+}
+
+// WriteChanges prints the given changes to the given writer.
+func WriteChanges(w io.Writer, changes []Change) error {
+	for _, change := range changes {
+		if _, err := fmt.Fprintf(w, "%s\n", change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Plan returns the changes which remove the duplicates and, if enabled,
+// merge the adjacent time entries of the same task. Running time entries
+// are never changed. The oldest time entry (lowest ID) of duplicates is kept.
+func Plan(timeEntries []model.TimeEntry, options Options) []Change {
+	if options.Tolerance <= 0 {
+		options.Tolerance = DefaultTolerance
+	}
+
+	var stopped []model.TimeEntry
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() {
+			continue
+		}
+
+		stopped = append(stopped, timeEntry)
+	}
+
+	sort.SliceStable(stopped, func(i, j int) bool {
+		if stopped[i].Start.Equal(stopped[j].Start) {
+			return stopped[i].ID < stopped[j].ID
+		}
+
+		return stopped[i].Start.Before(stopped[j].Start)
+	})
+
+	groups := groupDuplicates(stopped, options.Tolerance)
+	if options.Merge {
+		groups = mergeAdjacent(groups, options.MergeGap)
+	}
+
+	var changes []Change
+	for _, group := range groups {
+		if len(group.Removed) > 0 {
+			changes = append(changes, group)
+		}
+	}
+
+	return changes
+}
+
+// groupDuplicates returns a change for every time entry which is kept.
+// Changes without removed time entries do not modify anything.
+func groupDuplicates(timeEntries []model.TimeEntry, tolerance time.Duration) []Change {
+	var groups []Change
+	removed := make(map[int]bool)
+	for index, timeEntry := range timeEntries {
+		if removed[timeEntry.ID] {
+			continue
+		}
+
+		duplicates := []model.TimeEntry{timeEntry}
+		for _, candidate := range timeEntries[index+1:] {
+			if candidate.Start.Sub(timeEntry.Start) > tolerance {
+				break
+			}
+
+			if removed[candidate.ID] || !sameTask(timeEntry, candidate) || !within(candidate.Stop.Sub(timeEntry.Stop), tolerance) {
+				continue
+			}
+
+			removed[candidate.ID] = true
+			duplicates = append(duplicates, candidate)
+		}
+
+		// keep the oldest time entry
+		sort.SliceStable(duplicates, func(i, j int) bool { return duplicates[i].ID < duplicates[j].ID })
+
+		group := Change{Original: duplicates[0], Updated: copyTimeEntry(duplicates[0]), Removed: duplicates[1:]}
+		for _, duplicate := range group.Removed {
+			absorbAttributes(&group.Updated, duplicate)
+		}
+
+		if len(group.Removed) > 0 {
+			group.Kind = Duplicate
+		} else {
+			group.Removed = nil
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// mergeAdjacent merges the groups of consecutive time entries of the
+// same task which are separated by at most the given gap.
+func mergeAdjacent(groups []Change, maximumGap time.Duration) []Change {
+	var merged []Change
+	for _, group := range groups {
+		if len(merged) > 0 {
+			previous := &merged[len(merged)-1]
+			gap := group.Updated.Start.Sub(previous.Updated.Stop)
+			if sameTask(previous.Updated, group.Updated) && gap >= 0 && gap <= maximumGap {
+				previous.Kind = Merge
+				previous.Removed = append(previous.Removed, group.Original)
+				previous.Removed = append(previous.Removed, group.Removed...)
+				absorbPeriod(&previous.Updated, group.Updated)
+				absorbAttributes(&previous.Updated, group.Updated)
+				continue
+			}
+		}
+
+		merged = append(merged, group)
+	}
+
+	return merged
+}
+
+// sameTask returns true if both time entries belong to the same task.
+func sameTask(a, b model.TimeEntry) bool {
+	return a.Wid == b.Wid && a.Pid == b.Pid && a.Description == b.Description && a.Uid == b.Uid
+}
+
+// within returns true if the given difference is not larger than the tolerance.
+func within(difference, tolerance time.Duration) bool {
+	if difference < 0 {
+		difference = -difference
+	}
+
+	return difference <= tolerance
+}
+
+// absorbPeriod extends the kept time entry to the period of the other time entry.
+func absorbPeriod(kept *model.TimeEntry, other model.TimeEntry) {
+	if other.Start.Before(kept.Start) {
+		kept.Start = other.Start
+	}
+
+	if other.Stop.After(kept.Stop) {
+		kept.Stop = other.Stop
+	}
+
+	kept.Duration = int(kept.Stop.Sub(kept.Start).Seconds())
+}
+
+// absorbAttributes adds the tags and the billable flag of the other time entry to the kept one.
+func absorbAttributes(kept *model.TimeEntry, other model.TimeEntry) {
+	kept.Billable = kept.Billable || other.Billable
+	for _, tag := range other.Tags {
+		if !containsTag(kept.Tags, tag) {
+			kept.Tags = append(kept.Tags, tag)
+		}
+	}
+}
+
+// copyTimeEntry returns a copy of the given time entry which does not share its tags.
+func copyTimeEntry(timeEntry model.TimeEntry) model.TimeEntry {
+	if timeEntry.Tags != nil {
+		timeEntry.Tags = append([]string(nil), timeEntry.Tags...)
+	}
+
+	return timeEntry
+}
+
+// containsTag returns true if the given tags contain the given tag.
+func containsTag(tags []string, tag string) bool {
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameTags returns true if both lists contain the same tags.
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, tag := range a {
+		if !containsTag(b, tag) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,225 @@
+package dedupe
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// at returns the given time on 2016-09-06 (UTC).
+func at(hour, minute, second int) time.Time {
+	return time.Date(2016, 9, 6, hour, minute, second, 0, time.UTC)
+}
+
+// testEntry returns a stopped time entry of project 1 in workspace 1.
+func testEntry(id int, description string, start, stop time.Time) model.TimeEntry {
+	return model.TimeEntry{ID: id, Wid: 1, Pid: 1, Description: description, Start: start, Stop: stop, Duration: int(stop.Sub(start).Seconds())}
+}
+
+// removedIDs returns the IDs of the removed time entries of the given change.
+func removedIDs(change Change) []int {
+	var ids []int
+	for _, removed := range change.Removed {
+		ids = append(ids, removed.ID)
+	}
+
+	return ids
+}
+
+func Test_Plan_DuplicatesWithinTolerance_OldestEntryIsKept(t *testing.T) {
+	// arrange
+	duplicate := testEntry(2, "Layout", at(9, 0, 30), at(10, 0, 0))
+	duplicate.Tags = []string{"invoiced-2016-09"}
+
+	timeEntries := []model.TimeEntry{
+		duplicate,
+		testEntry(1, "Layout", at(9, 0, 0), at(10, 0, 20)),
+		testEntry(3, "Review", at(9, 0, 0), at(10, 0, 0)),
+		testEntry(4, "Layout", at(9, 5, 0), at(10, 5, 0)),
+	}
+
+	// act
+	changes := Plan(timeEntries, Options{Tolerance: time.Minute})
+
+	// assert
+	if len(changes) != 1 || changes[0].Kind != Duplicate || changes[0].Updated.ID != 1 || fmt.Sprint(removedIDs(changes[0])) != "[2]" {
+		t.Fatalf("Plan should have removed entry 2 as a duplicate of entry 1: %v", changes)
+	}
+
+	if !changes[0].Updated.Stop.Equal(at(10, 0, 20)) || len(changes[0].Updated.Tags) != 1 || !changes[0].NeedsUpdate() {
+		t.Fail()
+		t.Logf("Plan should have kept the period and added the tags of the duplicate: %+v", changes[0].Updated)
+	}
+}
+
+func Test_Plan_AdjacentEntries_EntriesAreMergedIfEnabled(t *testing.T) {
+	// arrange
+	timeEntries := []model.TimeEntry{
+		testEntry(1, "Layout", at(9, 0, 0), at(10, 0, 0)),
+		testEntry(2, "Layout", at(10, 0, 30), at(11, 0, 0)),
+		testEntry(3, "Layout", at(11, 30, 0), at(12, 0, 0)),
+		testEntry(4, "Review", at(12, 0, 0), at(13, 0, 0)),
+	}
+
+	// act
+	withoutMerge := Plan(timeEntries, Options{})
+	changes := Plan(timeEntries, Options{Merge: true, MergeGap: time.Minute})
+
+	// assert
+	if len(withoutMerge) != 0 {
+		t.Fail()
+		t.Logf("Plan should not have merged anything without the Merge option: %v", withoutMerge)
+	}
+
+	if len(changes) != 1 || changes[0].Kind != Merge || fmt.Sprint(removedIDs(changes[0])) != "[2]" || !changes[0].Updated.Stop.Equal(at(11, 0, 0)) || changes[0].Updated.Duration != 7200 {
+		t.Fail()
+		t.Logf("Plan should have merged entry 2 into entry 1: %v", changes)
+	}
+}
+
+func Test_Plan_DuplicatesOfMergedEntries_AllAreRemovedByOneChange(t *testing.T) {
+	// arrange
+	timeEntries := []model.TimeEntry{
+		testEntry(1, "Layout", at(9, 0, 0), at(10, 0, 0)),
+		testEntry(2, "Layout", at(9, 0, 0), at(10, 0, 0)),
+		testEntry(3, "Layout", at(10, 0, 0), at(11, 0, 0)),
+		testEntry(4, "Layout", at(10, 0, 0), at(11, 0, 0)),
+	}
+
+	// act
+	changes := Plan(timeEntries, Options{Merge: true})
+
+	// assert
+	if len(changes) != 1 || changes[0].Updated.ID != 1 || fmt.Sprint(removedIDs(changes[0])) != "[2 3 4]" || !changes[0].Original.Stop.Equal(at(10, 0, 0)) {
+		t.Fail()
+		t.Logf("Plan should have merged all entries into entry 1: %v", changes)
+	}
+}
+
+func Test_Plan_RunningEntries_AreIgnored(t *testing.T) {
+	// arrange
+	running := model.TimeEntry{ID: 2, Wid: 1, Pid: 1, Description: "Layout", Start: at(9, 0, 0), Duration: -int(at(9, 0, 0).Unix())}
+	timeEntries := []model.TimeEntry{testEntry(1, "Layout", at(9, 0, 0), at(10, 0, 0)), running}
+
+	// act
+	changes := Plan(timeEntries, Options{Merge: true})
+
+	// assert
+	if len(changes) != 0 {
+		t.Fail()
+		t.Logf("Plan should not have changed the running entry: %v", changes)
+	}
+}
+
+func Test_Apply_Undo_ChangesAreReverted(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateTimeEntry(testEntry(0, "Layout", at(9, 0, 0), at(10, 0, 0)))
+	api.CreateTimeEntry(testEntry(0, "Layout", at(9, 0, 0), at(10, 0, 0)))
+	api.CreateTimeEntry(testEntry(0, "Layout", at(10, 0, 0), at(11, 0, 0)))
+
+	changes := Plan(api.TimeEntries, Options{Merge: true})
+
+	// act
+	log, applyError := Apply(api, changes, at(12, 0, 0))
+	applied := append([]model.TimeEntry(nil), api.TimeEntries...)
+	recreated, remaining, undoError := Undo(api, log)
+
+	// assert
+	if applyError != nil || len(applied) != 1 || !applied[0].Stop.Equal(at(11, 0, 0)) {
+		t.Fatalf("Apply should have merged the entries into one (Entries: %v, Error: %v)", applied, applyError)
+	}
+
+	if undoError != nil || recreated != 2 || len(remaining.Changes) != 0 || len(api.TimeEntries) != 3 || !api.TimeEntries[0].Stop.Equal(at(10, 0, 0)) {
+		t.Fail()
+		t.Logf("Undo should have restored the three entries (Entries: %v, Error: %v)", api.TimeEntries, undoError)
+	}
+}
+
+func Test_Apply_DeleteFails_LogContainsPartialChange(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateTimeEntry(testEntry(0, "Layout", at(9, 0, 0), at(10, 0, 0)))
+	api.CreateTimeEntry(testEntry(0, "Layout", at(10, 0, 0), at(11, 0, 0)))
+	api.Errors["DeleteTimeEntry"] = fmt.Errorf("Service unavailable")
+
+	changes := Plan(api.TimeEntries, Options{Merge: true})
+
+	// act
+	log, err := Apply(api, changes, at(12, 0, 0))
+
+	// assert
+	if err == nil || len(log.Changes) != 1 || !log.Changes[0].NeedsUpdate() || len(log.Changes[0].Removed) != 0 {
+		t.Fail()
+		t.Logf("Apply should have logged the update but not the failed deletion (Log: %+v, Error: %v)", log, err)
+	}
+}
+
+func Test_Undo_CreateFails_RemainingChangesAreReturned(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateTimeEntry(testEntry(0, "Layout", at(9, 0, 0), at(10, 0, 0)))
+	api.CreateTimeEntry(testEntry(0, "Layout", at(10, 0, 0), at(11, 0, 0)))
+
+	log, _ := Apply(api, Plan(api.TimeEntries, Options{Merge: true}), at(12, 0, 0))
+	api.Errors["CreateTimeEntry"] = fmt.Errorf("Service unavailable")
+
+	// act
+	recreated, remaining, undoError := Undo(api, log)
+
+	// assert
+	if undoError == nil || recreated != 0 || len(remaining.Changes) != 1 || remaining.Changes[0].NeedsUpdate() || len(remaining.Changes[0].Removed) != 1 {
+		t.Fatalf("Undo should have returned the recreation which is still pending (Log: %+v, Error: %v)", remaining, undoError)
+	}
+
+	delete(api.Errors, "CreateTimeEntry")
+	recreated, remaining, undoError = Undo(api, remaining)
+	if undoError != nil || recreated != 1 || len(remaining.Changes) != 0 || len(api.TimeEntries) != 2 || !api.TimeEntries[0].Stop.Equal(at(10, 0, 0)) {
+		t.Fail()
+		t.Logf("The retried Undo should have recreated the removed entry (Entries: %v, Error: %v)", api.TimeEntries, undoError)
+	}
+}
+
+func Test_Undo_InvalidRemovedEntry_NothingIsReverted(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateTimeEntry(testEntry(0, "Layout", at(9, 0, 0), at(10, 0, 0)))
+	api.CreateTimeEntry(testEntry(0, "Layout", at(10, 0, 0), at(11, 0, 0)))
+
+	log, _ := Apply(api, Plan(api.TimeEntries, Options{Merge: true}), at(12, 0, 0))
+	log.Changes[0].Removed[0].Tags = []string{"review", "Review"}
+	api.Calls = nil
+
+	// act
+	_, remaining, undoError := Undo(api, log)
+
+	// assert
+	if undoError == nil || len(api.Calls) != 0 || len(remaining.Changes) != 1 {
+		t.Fail()
+		t.Logf("Undo should have rejected the invalid entry without calling the API (Calls: %v, Error: %v)", api.Calls, undoError)
+	}
+}
+
+func Test_WriteLog_ReadLog_RoundTrip(t *testing.T) {
+	// arrange
+	changes := Plan([]model.TimeEntry{
+		testEntry(1, "Layout", at(9, 0, 0), at(10, 0, 0)),
+		testEntry(2, "Layout", at(9, 0, 0), at(10, 0, 0)),
+	}, Options{})
+
+	buffer := &bytes.Buffer{}
+
+	// act
+	writeError := WriteLog(buffer, Log{Applied: at(12, 0, 0), Changes: changes})
+	log, readError := ReadLog(buffer)
+
+	// assert
+	if writeError != nil || readError != nil || !log.Applied.Equal(at(12, 0, 0)) || len(log.Changes) != 1 || log.Changes[0].Removed[0].ID != 2 {
+		t.Fail()
+		t.Logf("ReadLog should have returned the written log (Log: %+v, Errors: %v, %v)", log, writeError, readError)
+	}
+}
@@ -0,0 +1,187 @@
+package togglapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// firstDryRunID contains the first ID assigned to models created in dry-run mode.
+const firstDryRunID = 900000000
+
+// dryRunSecret contains the secret of webhook subscriptions created in dry-run mode.
+const dryRunSecret = "dry-run"
+
+// dryRunRoutePattern extracts the ID of the model from routes like "time_entries/123/stop".
+var dryRunRoutePattern = regexp.MustCompile(`^[a-z_]+/(\d+)(?:/|$)`)
+
+// dryRunWebhookRoutePattern extracts the workspace and the optional
+// subscription ID from webhook routes like "subscriptions/1/123".
+var dryRunWebhookRoutePattern = regexp.MustCompile(`^subscriptions/(\d+)(?:/(\d+))?$`)
+
+// NewDryRunAPI creates a new instance of the Toggl API which reads from
+// the Toggl API but only writes the method, route and payload of all
+// mutating requests to the given log.
+func NewDryRunAPI(baseURL, token string, log io.Writer) model.TogglAPI {
+	return NewAPIWithRequester(NewDryRunRequester(NewRESTRequester(baseURL, token), log), nil)
+}
+
+// NewDryRunAPIInLocation creates a dry-run instance of the Toggl API like
+// NewDryRunAPI which uses the given reporting time zone like
+// NewAPIInLocation. If no location is given the time zone of the Toggl
+// profile of the user is used.
+func NewDryRunAPIInLocation(baseURL, token string, location *time.Location, log io.Writer) (model.TogglAPI, error) {
+	requester := NewDryRunRequester(NewRESTRequester(baseURL, token), log)
+	if location == nil {
+		userLocation, locationError := GetUserLocation(&UserAPI{requester})
+		if locationError != nil {
+			return nil, locationError
+		}
+
+		location = userLocation
+	}
+
+	return NewAPIWithRequester(requester, location), nil
+}
+
+// NewDryRunRequester creates a RESTRequester which passes GET requests to
+// the given requester and writes POST, PUT and DELETE requests to the given
+// log instead of sending them. Mutating requests are answered with the sent
+// model and the ID of the route or a fake ID for created models.
+func NewDryRunRequester(requester RESTRequester, log io.Writer) RESTRequester {
+	return &dryRunRequester{
+		requester: requester,
+		log:       log,
+		nextID:    firstDryRunID,
+	}
+}
+
+// NewDryRunWebhookRequester creates a RESTRequester like NewDryRunRequester
+// for the webhooks API. Created subscriptions are answered unwrapped with
+// the workspace of the route, a fake ID and a fake secret if none was sent.
+func NewDryRunWebhookRequester(requester RESTRequester, log io.Writer) RESTRequester {
+	return &dryRunRequester{
+		requester: requester,
+		log:       log,
+		webhooks:  true,
+		nextID:    firstDryRunID,
+	}
+}
+
+// dryRunRequester logs mutating requests instead of sending them.
+type dryRunRequester struct {
+	requester RESTRequester
+	log       io.Writer
+	webhooks  bool
+
+	lock   sync.Mutex
+	nextID int
+}
+
+// Request passes GET requests to the wrapped requester and logs all other
+// requests. Returns a synthetic response for the logged requests.
+func (requester *dryRunRequester) Request(method, route string, payload io.Reader) ([]byte, error) {
+	if method == http.MethodGet {
+		return requester.requester.Request(method, route, payload)
+	}
+
+	var body []byte
+	if payload != nil {
+		content, readError := ioutil.ReadAll(payload)
+		if readError != nil {
+			return nil, errors.Wrap(readError, "Failed to read the payload")
+		}
+
+		body = content
+	}
+
+	if _, err := fmt.Fprintf(requester.log, "%s %s %s\n", method, route, body); err != nil {
+		return nil, errors.Wrap(err, "Failed to write the dry-run log")
+	}
+
+	if method == http.MethodDelete {
+		return []byte{}, nil
+	}
+
+	if requester.webhooks {
+		return requester.webhookResponse(route, body)
+	}
+
+	return requester.response(route, body)
+}
+
+// response returns the synthetic response for a request with the given route
+// and body: the unwrapped model of the body with the ID of the route or a new
+// fake ID.
+func (requester *dryRunRequester) response(route string, body []byte) ([]byte, error) {
+	data := make(map[string]interface{})
+
+	var request map[string]map[string]interface{}
+	if len(body) > 0 && json.Unmarshal(body, &request) == nil {
+		for _, fields := range request {
+			for name, value := range fields {
+				data[name] = value
+			}
+		}
+	}
+
+	if matches := dryRunRoutePattern.FindStringSubmatch(route); matches != nil {
+		data["id"], _ = strconv.Atoi(matches[1])
+	} else {
+		data["id"] = requester.newID()
+	}
+
+	return json.Marshal(map[string]interface{}{"data": data})
+}
+
+// webhookResponse returns the synthetic response for a request to the
+// webhooks API: the subscription of the body with the IDs of the route
+// or a new fake ID. Pings are answered with an empty response.
+func (requester *dryRunRequester) webhookResponse(route string, body []byte) ([]byte, error) {
+	matches := dryRunWebhookRoutePattern.FindStringSubmatch(route)
+	if matches == nil {
+		return []byte{}, nil
+	}
+
+	// bodies which are no JSON object are answered with the IDs only
+	data := make(map[string]interface{})
+
+	var subscription map[string]interface{}
+	if len(body) > 0 && json.Unmarshal(body, &subscription) == nil {
+		for name, value := range subscription {
+			data[name] = value
+		}
+	}
+
+	data["workspace_id"], _ = strconv.Atoi(matches[1])
+	if matches[2] != "" {
+		data["subscription_id"], _ = strconv.Atoi(matches[2])
+	} else {
+		data["subscription_id"] = requester.newID()
+	}
+
+	if secret, _ := data["secret"].(string); secret == "" {
+		data["secret"] = dryRunSecret
+	}
+
+	return json.Marshal(data)
+}
+
+// newID returns a new fake ID.
+func (requester *dryRunRequester) newID() int {
+	requester.lock.Lock()
+	defer requester.lock.Unlock()
+
+	id := requester.nextID
+	requester.nextID++
+	return id
+}
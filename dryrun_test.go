@@ -0,0 +1,185 @@
+package togglapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_NewDryRunAPI(t *testing.T) {
+	// act
+	api := NewDryRunAPI("http://api.example.com", "sakldjaksljkl312312", &bytes.Buffer{})
+
+	// assert
+	if api == nil {
+		t.Fail()
+		t.Logf("NewDryRunAPI should have returned a Toggl API client")
+	}
+}
+
+func Test_NewDryRunAPIInLocation_NoLocation_TimeZoneOfUserIsUsed(t *testing.T) {
+	// arrange
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/me" {
+			fmt.Fprint(w, `{"data": {"id": 1, "timezone": "Europe/Berlin"}}`)
+			return
+		}
+
+		fmt.Fprint(w, `[{"id": 1, "start": "2016-09-06T08:00:00Z", "stop": "2016-09-06T09:00:00Z", "duration": 3600}]`)
+	}))
+	defer testServer.Close()
+
+	// act
+	api, err := NewDryRunAPIInLocation(testServer.URL, "sakldjaksljkl312312", nil, &bytes.Buffer{})
+
+	// assert
+	if err != nil {
+		t.Fatalf("NewDryRunAPIInLocation returned an error: %s", err)
+	}
+
+	timeEntries, timeEntriesError := api.GetTimeEntries(time.Now().AddDate(0, 0, -1), time.Now())
+	if timeEntriesError != nil || len(timeEntries) != 1 || timeEntries[0].Start.Location().String() != "Europe/Berlin" {
+		t.Fail()
+		t.Logf("NewDryRunAPIInLocation should have used the time zone of the user (time entries: %+v, error: %v)", timeEntries, timeEntriesError)
+	}
+}
+
+func Test_DryRunRequester_GETRequest_RequestIsPassedThrough(t *testing.T) {
+	// arrange
+	log := &bytes.Buffer{}
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(`[{"id": 1, "name": "Workspace"}]`), nil
+		},
+	}
+
+	api := NewAPIWithRequester(NewDryRunRequester(restClient, log), nil)
+
+	// act
+	workspaces, err := api.GetWorkspaces()
+
+	// assert
+	if err != nil || len(workspaces) != 1 || log.Len() != 0 {
+		t.Fail()
+		t.Logf("GetWorkspaces should have returned the workspace of the API without logging but returned %v (%v, log: %q)", workspaces, err, log.String())
+	}
+}
+
+func Test_DryRunRequester_MutatingRequests_RequestsAreLoggedAndNotSent(t *testing.T) {
+	// arrange
+	log := &bytes.Buffer{}
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			t.Errorf("The dry-run requester should not send the %s request to %s", method, route)
+			return nil, nil
+		},
+	}
+
+	api := NewAPIWithRequester(NewDryRunRequester(restClient, log), nil)
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+
+	// act
+	first, firstError := api.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour), Description: "Review"})
+	second, secondError := api.CreateProject(model.Project{WorkspaceID: 1, Name: "Website"})
+	updated, updateError := api.UpdateTimeEntry(model.TimeEntry{ID: 42, Wid: 1, Start: start, Stop: start.Add(time.Hour), Description: "Updated"})
+	stopped, stopError := api.StopTimeEntry(43)
+	deleteError := api.DeleteClient(44)
+
+	// assert
+	for _, err := range []error{firstError, secondError, updateError, stopError, deleteError} {
+		if err != nil {
+			t.Fatalf("The dry-run requester returned an error: %s", err)
+		}
+	}
+
+	if first.ID != firstDryRunID || first.Description != "Review" || !first.Start.Equal(start) || !first.Stop.Equal(start.Add(time.Hour)) || second.ID != firstDryRunID+1 || second.Name != "Website" {
+		t.Fail()
+		t.Logf("The created models should have fake IDs and the sent fields but were %+v and %+v", first, second)
+	}
+
+	if updated.ID != 42 || updated.Description != "Updated" || stopped.ID != 43 {
+		t.Fail()
+		t.Logf("The updated models should keep their IDs but were %+v and %+v", updated, stopped)
+	}
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	expected := []string{
+		`POST time_entries {"time_entry":{"wid":1,"start":"2016-09-06T06:30:00Z","stop":"2016-09-06T07:30:00Z","duration":3600,"description":"Review","created_with":"github.com/andreaskoch/togglapi"}}`,
+		`POST projects {"project":{"wid":1,"name":"Website"}}`,
+		`PUT time_entries/42 `,
+		`PUT time_entries/43/stop `,
+		`DELETE clients/44 `,
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("The dry-run log should contain %d requests but contains %q", len(expected), lines)
+	}
+
+	for index, line := range lines {
+		if !strings.HasPrefix(line+" ", expected[index]) {
+			t.Fail()
+			t.Logf("Line %d of the dry-run log should start with %q but is %q", index+1, expected[index], line)
+		}
+	}
+}
+
+func Test_DryRunWebhookRequester_CreatedSubscriptionHasFakeIDAndSecret(t *testing.T) {
+	// arrange
+	log := &bytes.Buffer{}
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			t.Errorf("The dry-run requester should not send the %s request to %s", method, route)
+			return nil, nil
+		},
+	}
+
+	api := NewWebhookAPIWithRequester(NewDryRunWebhookRequester(restClient, log))
+
+	// act
+	subscription, createError := api.CreateWebhookSubscription(model.WebhookSubscription{
+		WorkspaceID:  1,
+		Description:  "Sync",
+		URLCallback:  "https://example.com/toggl",
+		Enabled:      true,
+		EventFilters: []model.WebhookEventFilter{{Entity: "*", Action: "*"}},
+	})
+	pingError := api.PingWebhookSubscription(1, subscription.ID)
+	deleteError := api.DeleteWebhookSubscription(1, subscription.ID)
+
+	// assert
+	if createError != nil || pingError != nil || deleteError != nil {
+		t.Fatalf("The dry-run requester returned an error: %v, %v, %v", createError, pingError, deleteError)
+	}
+
+	if subscription.ID != firstDryRunID || subscription.WorkspaceID != 1 || subscription.Secret != dryRunSecret || subscription.Description != "Sync" {
+		t.Fail()
+		t.Logf("The created subscription should have a fake ID and secret but was %+v", subscription)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(log.String()), "\n"); len(lines) != 3 {
+		t.Fail()
+		t.Logf("The dry-run log should contain 3 requests but contains %q", lines)
+	}
+}
+
+func Test_DryRunWebhookRequester_BodyIsNoObject_IDsAreReturned(t *testing.T) {
+	// arrange
+	requester := NewDryRunWebhookRequester(&mockRESTRequester{}, &bytes.Buffer{})
+
+	// act
+	response, err := requester.Request(http.MethodPost, "subscriptions/1", strings.NewReader("null"))
+
+	// assert
+	expected := `{"secret":"dry-run","subscription_id":900000000,"workspace_id":1}`
+	if err != nil || string(response) != expected {
+		t.Fail()
+		t.Logf("The dry-run requester should have returned %s but returned %s (error: %v)", expected, response, err)
+	}
+}
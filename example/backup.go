@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/andreaskoch/togglapi/backup"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "backup",
+		usage:       "backup [--from date] [--to date] <file>",
+		description: "Save all workspaces, clients, projects and time entries to an archive",
+		run:         runBackup,
+	})
+
+	registerCommand(command{
+		name:        "restore",
+		usage:       "restore [--workspace w] <file>",
+		description: "Restore the clients, projects and time entries of an archive",
+		run:         runRestore,
+	})
+}
+
+// runBackup writes all data of the account to a compressed archive.
+func runBackup(env *environment, args []string) error {
+	flags := newFlagSet("backup")
+	from := flags.String("from", "", "The first day of the time entry history (default: all history)")
+	to := flags.String("to", "", "The last day of the time entry history (default: today)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return newUsageError("Usage: toggl backup [options] <file>")
+	}
+
+	options := backup.Options{}
+	if *from != "" || *to != "" {
+		start, end, rangeError := parseDateRange(*from, *to, 0, env.now(), env.weekStart())
+		if rangeError != nil {
+			return rangeError
+		}
+
+		if *from != "" {
+			options.Start = start
+		}
+
+		options.End = end
+	}
+
+	archive, createError := backup.Create(env.api, options)
+	if createError != nil {
+		return createError
+	}
+
+	file, fileError := os.Create(flags.Arg(0))
+	if fileError != nil {
+		return fileError
+	}
+
+	defer file.Close()
+
+	if err := backup.Write(file, archive); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "Saved %d workspaces, %d clients, %d projects and %d time entries\n",
+		len(archive.Workspaces), len(archive.Clients), len(archive.Projects), len(archive.TimeEntries))
+
+	return nil
+}
+
+// runRestore recreates the records of an archive in the selected workspace.
+func runRestore(env *environment, args []string) error {
+	flags := newFlagSet("restore")
+	workspaceName := flags.String("workspace", "", "The target workspace (name or ID)")
+	sourceWorkspaceID := flags.Int("source-workspace", 0, "Only restore the records of the archived workspace with this ID")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return newUsageError("Usage: toggl restore [options] <file>")
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	file, openError := os.Open(flags.Arg(0))
+	if openError != nil {
+		return openError
+	}
+
+	defer file.Close()
+
+	archive, readError := backup.Read(file)
+	if readError != nil {
+		return readError
+	}
+
+	result, restoreError := backup.Restore(env.api, archive, backup.RestoreOptions{
+		WorkspaceID:       workspace.ID,
+		SourceWorkspaceID: *sourceWorkspaceID,
+	})
+
+	fmt.Fprintf(env.stdout, "Created %d clients, %d projects and %d time entries (reused %d clients and %d projects, skipped %d running time entries)\n",
+		result.CreatedClients, result.CreatedProjects, result.CreatedTimeEntries,
+		result.ReusedClients, result.ReusedProjects, result.SkippedTimeEntries)
+
+	return restoreError
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/andreaskoch/togglapi/ical"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "calendar",
+		usage:       "calendar export|import",
+		description: "Export time entries to or import them from iCalendar files",
+		run:         runCalendar,
+	})
+}
+
+// runCalendar executes the selected calendar sub command.
+func runCalendar(env *environment, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("Usage: toggl calendar export|import [options]")
+	}
+
+	switch args[0] {
+	case "export":
+		return exportCalendar(env, args[1:])
+	case "import":
+		return importCalendar(env, args[1:])
+	}
+
+	return newUsageError("Unknown calendar command %q. Available: export, import", args[0])
+}
+
+// exportCalendar writes the time entries of the selected
+// date range to an iCalendar file.
+func exportCalendar(env *environment, args []string) error {
+	flags := newFlagSet("calendar export")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 30 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	outputPath := flags.String("output", "", "The path of the .ics file (default: standard output)")
+	name := flags.String("name", "Toggl", "The name of the calendar")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	start, end, rangeError := parseDateRange(*from, *to, 30, env.now(), env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	timeEntries, timeEntriesError := env.api.GetTimeEntries(start, end)
+	if timeEntriesError != nil {
+		return timeEntriesError
+	}
+
+	projects, projectsError := getProjects(env, timeEntries)
+	if projectsError != nil {
+		return projectsError
+	}
+
+	clients, clientsError := env.api.GetClients()
+	if clientsError != nil {
+		return clientsError
+	}
+
+	output := env.stdout
+	if *outputPath != "" {
+		file, createError := os.Create(*outputPath)
+		if createError != nil {
+			return createError
+		}
+
+		defer file.Close()
+		output = file
+	}
+
+	return ical.Encode(output, timeEntries, ical.EncodeOptions{
+		Name:     *name,
+		Projects: projects,
+		Clients:  clients,
+	})
+}
+
+// importCalendar creates time entries from the events of an iCalendar file.
+func importCalendar(env *environment, args []string) error {
+	flags := newFlagSet("calendar import")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	projectName := flags.String("project", "", "The project of events without Toggl project (name or ID)")
+	dryRun := flags.Bool("dry-run", false, "Only print the time entries which would be created")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return newUsageError("Usage: toggl calendar import [options] <file.ics>")
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	options := ical.DecodeOptions{
+		WorkspaceID: workspace.ID,
+		Location:    env.timeZone(),
+	}
+
+	if *projectName != "" {
+		project, projectError := resolveProject(env, workspace.ID, *projectName)
+		if projectError != nil {
+			return projectError
+		}
+
+		options.ProjectID = project.ID
+	}
+
+	file, openError := os.Open(flags.Arg(0))
+	if openError != nil {
+		return openError
+	}
+
+	defer file.Close()
+
+	if *dryRun {
+		timeEntries, decodeError := ical.Decode(file, options)
+		if decodeError != nil {
+			return decodeError
+		}
+
+		formatter, formatterError := output.formatter(nil, nil)
+		if formatterError != nil {
+			return formatterError
+		}
+
+		return formatter.WriteTimeEntries(env.stdout, timeEntries)
+	}
+
+	createdTimeEntries, importError := ical.Import(env.api, file, options)
+	fmt.Fprintf(env.stdout, "Created %d time entries\n", len(createdTimeEntries))
+	return importError
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andreaskoch/togglapi/validation"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "check",
+		usage:       "check [--from date] [--to date]",
+		description: "Report overlaps, gaps and other problems of time entries",
+		run:         checkTimeEntries,
+	})
+}
+
+// checkTimeEntries prints the problems found in the time entries of the
+// selected date range and fails if there are any.
+func checkTimeEntries(env *environment, args []string) error {
+	flags := newFlagSet("check")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 7 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	workingHours := flags.String("working-hours", "09:00-17:00", "The working hours on Monday to Friday for the gap detection (empty: no gap detection)")
+	minimumGap := flags.Duration("gap", 15*time.Minute, "The shortest reported gap")
+	maximum := flags.Duration("max", 10*time.Hour, "The longest allowed time entry (0: no limit)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	now := env.now()
+	start, end, rangeError := parseDateRange(*from, *to, 7, now, env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	options := validation.Options{
+		MinimumGap:      *minimumGap,
+		MaximumDuration: *maximum,
+		Start:           start,
+		End:             end,
+		Location:        now.Location(),
+		Now:             now,
+	}
+
+	if *workingHours != "" {
+		parsed, parseError := validation.ParseWorkingHours(*workingHours)
+		if parseError != nil {
+			return newUsageError("%s", parseError)
+		}
+
+		options.WorkingHours = &parsed
+	}
+
+	// the gaps of today are only reported up to now
+	if options.End.After(now) {
+		options.End = now
+	}
+
+	timeEntries, timeEntriesError := env.api.GetTimeEntries(start, end)
+	if timeEntriesError != nil {
+		return timeEntriesError
+	}
+
+	findings := validation.Check(timeEntries, options)
+	for _, finding := range findings {
+		fmt.Fprintf(env.stdout, "%s\n", finding)
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("Found %d problems", len(findings))
+	}
+
+	fmt.Fprintf(env.stdout, "No problems found\n")
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andreaskoch/togglapi"
+	"github.com/andreaskoch/togglapi/date"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// defaultConfigFileName contains the name of the configuration file
+// in the home directory of the current user.
+const defaultConfigFileName = ".toggl.json"
+
+// defaultProfileName contains the name of the profile that is used
+// if no profile has been selected.
+const defaultProfileName = "default"
+
+// togglTimeZone selects the time zone of the Toggl profile of the user.
+const togglTimeZone = "toggl"
+
+// configuration contains the profiles of the toggl command-line tool.
+//
+// Example:
+//
+//	{
+//	  "default_profile": "work",
+//	  "profiles": {
+//	    "work": { "token": "...", "workspace": "Acme Inc.", "week_start": "sunday", "time_zone": "Europe/Berlin" },
+//	    "private": { "token": "..." }
+//	  }
+//	}
+type configuration struct {
+	DefaultProfile string             `json:"default_profile"`
+	Profiles       map[string]profile `json:"profiles"`
+}
+
+// profile contains the settings for a single Toggl account.
+type profile struct {
+	// Token contains the Toggl API token.
+	Token string `json:"token"`
+
+	// BaseURL contains the URL of the Toggl API.
+	BaseURL string `json:"base_url"`
+
+	// WebhookURL contains the URL of the Toggl webhooks API.
+	WebhookURL string `json:"webhook_url"`
+
+	// Workspace contains the name or ID of the default workspace.
+	Workspace string `json:"workspace"`
+
+	// WeekStart contains the first day of the week (e.g. "monday" or "sunday").
+	WeekStart string `json:"week_start"`
+
+	// TimeZone contains the reporting time zone (e.g. "Europe/Berlin") or
+	// "toggl" for the time zone of the Toggl profile (default: the time
+	// zone of the Toggl profile or the local time if it cannot be fetched).
+	TimeZone string `json:"time_zone"`
+}
+
+// loadProfile returns the profile with the given name from the configuration
+// file at the given path. If no path is given the configuration file in the
+// home directory is used. A missing configuration file is only an error
+// if the path or the profile have been specified explicitly.
+func loadProfile(path, name string) (profile, error) {
+	explicitPath := path != ""
+	if !explicitPath {
+		path = defaultConfigPath()
+	}
+
+	config, readError := readConfiguration(path)
+	if readError != nil {
+		if os.IsNotExist(errors.Cause(readError)) && !explicitPath && name == "" {
+			return profile{BaseURL: defaultBaseURL, WebhookURL: togglapi.DefaultWebhookBaseURL}, nil
+		}
+
+		return profile{}, readError
+	}
+
+	if name == "" {
+		name = config.DefaultProfile
+	}
+
+	if name == "" {
+		name = defaultProfileName
+	}
+
+	selectedProfile, exists := config.Profiles[name]
+	if !exists {
+		return profile{}, fmt.Errorf("The profile %q does not exist in %s", name, path)
+	}
+
+	if selectedProfile.BaseURL == "" {
+		selectedProfile.BaseURL = defaultBaseURL
+	}
+
+	if selectedProfile.WebhookURL == "" {
+		selectedProfile.WebhookURL = togglapi.DefaultWebhookBaseURL
+	}
+
+	if selectedProfile.WeekStart != "" {
+		if _, err := date.ParseWeekday(selectedProfile.WeekStart); err != nil {
+			return profile{}, errors.Wrap(err, fmt.Sprintf("Invalid week_start of profile %q", name))
+		}
+	}
+
+	if selectedProfile.TimeZone != "" && selectedProfile.TimeZone != togglTimeZone {
+		if _, err := time.LoadLocation(selectedProfile.TimeZone); err != nil {
+			return profile{}, errors.Wrap(err, fmt.Sprintf("Invalid time_zone of profile %q", name))
+		}
+	}
+
+	return selectedProfile, nil
+}
+
+// weekStart returns the first day of the week of the profile (default: Monday).
+func (selectedProfile profile) weekStart() time.Weekday {
+	weekday, err := date.ParseWeekday(selectedProfile.WeekStart)
+	if err != nil {
+		return time.Monday
+	}
+
+	return weekday
+}
+
+// location returns the reporting time zone of the profile. The time zone
+// "toggl" is fetched from the Toggl profile with the given API. Without a
+// time zone the Toggl profile is tried as well; nil (local time) is
+// returned if it cannot be fetched.
+func (selectedProfile profile) location(users model.UserAPI) (*time.Location, error) {
+	switch selectedProfile.TimeZone {
+	case "":
+		location, locationError := togglapi.GetUserLocation(users)
+		if locationError != nil {
+			return nil, nil
+		}
+
+		return location, nil
+	case togglTimeZone:
+		return togglapi.GetUserLocation(users)
+	default:
+		return time.LoadLocation(selectedProfile.TimeZone)
+	}
+}
+
+// readConfiguration reads the configuration file at the given path.
+func readConfiguration(path string) (configuration, error) {
+	content, readError := ioutil.ReadFile(path)
+	if readError != nil {
+		return configuration{}, errors.Wrap(readError, "Failed to read the configuration file")
+	}
+
+	var config configuration
+	if unmarshalError := json.Unmarshal(content, &config); unmarshalError != nil {
+		return configuration{}, errors.Wrap(unmarshalError, fmt.Sprintf("Failed to deserialize the configuration file %s", path))
+	}
+
+	return config, nil
+}
+
+// defaultConfigPath returns the path of the configuration
+// file in the home directory of the current user.
+func defaultConfigPath() string {
+	homeDirectory, err := os.UserHomeDir()
+	if err != nil {
+		return defaultConfigFileName
+	}
+
+	return filepath.Join(homeDirectory, defaultConfigFileName)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andreaskoch/togglapi/dedupe"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "dedupe",
+		usage:       "dedupe [--merge] [--dry-run] [--undo]",
+		description: "Remove duplicate time entries and merge adjacent ones",
+		run:         runDedupe,
+	})
+}
+
+// runDedupe removes the duplicate time entries of the selected period
+// and records the changes in an undo log, or reverts the last changes.
+func runDedupe(env *environment, args []string) error {
+	flags := newFlagSet("dedupe")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 30 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	tolerance := flags.Duration("tolerance", dedupe.DefaultTolerance, "The maximum difference of the start and stop times of duplicates")
+	merge := flags.Bool("merge", false, "Merge adjacent time entries with the same project and description")
+	mergeGap := flags.Duration("merge-gap", time.Minute, "The longest pause between merged time entries")
+	dryRun := flags.Bool("dry-run", false, "Only print the changes")
+	undo := flags.Bool("undo", false, "Revert the changes of the last run")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	logPath := dataPath(env.profile, "dedupe-undo")
+	if *undo {
+		return undoDedupe(env, logPath)
+	}
+
+	start, end, rangeError := parseDateRange(*from, *to, 30, env.now(), env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	timeEntries, timeEntriesError := env.api.GetTimeEntries(start, end)
+	if timeEntriesError != nil {
+		return timeEntriesError
+	}
+
+	changes := dedupe.Plan(timeEntries, dedupe.Options{
+		Tolerance: *tolerance,
+		Merge:     *merge,
+		MergeGap:  *mergeGap,
+	})
+
+	if err := dedupe.WriteChanges(env.stdout, changes); err != nil {
+		return err
+	}
+
+	if *dryRun || len(changes) == 0 {
+		fmt.Fprintf(env.stderr, "%d changes planned\n", len(changes))
+		return nil
+	}
+
+	log, applyError := dedupe.Apply(env.api, changes, time.Now())
+	if env.dryRun {
+		return applyError
+	}
+
+	if err := writeDedupeLog(logPath, log); err != nil {
+		return err
+	}
+
+	if applyError != nil {
+		return applyError
+	}
+
+	fmt.Fprintf(env.stderr, "Applied %d changes (revert them with: toggl dedupe --undo)\n", len(log.Changes))
+	return nil
+}
+
+// undoDedupe reverts the changes of the undo log at the given path and removes
+// the log. If the revert fails the changes which have not been reverted yet
+// are written back to the log.
+func undoDedupe(env *environment, logPath string) error {
+	file, fileError := os.Open(logPath)
+	if os.IsNotExist(fileError) {
+		return fmt.Errorf("There are no changes to revert")
+	}
+
+	if fileError != nil {
+		return fileError
+	}
+
+	log, readError := dedupe.ReadLog(file)
+	file.Close()
+	if readError != nil {
+		return readError
+	}
+
+	recreated, remaining, undoError := dedupe.Undo(env.api, log)
+	fmt.Fprintf(env.stderr, "Recreated %d time entries\n", recreated)
+	if env.dryRun {
+		return undoError
+	}
+
+	if undoError != nil {
+		if err := writeDedupeLog(logPath, remaining); err != nil {
+			return err
+		}
+
+		return undoError
+	}
+
+	return os.Remove(logPath)
+}
+
+// writeDedupeLog saves the given undo log to the given path.
+func writeDedupeLog(path string, log dedupe.Log) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, fileError := os.Create(path)
+	if fileError != nil {
+		return fileError
+	}
+
+	defer file.Close()
+	return dedupe.WriteLog(file, log)
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "entries",
+		usage:       "entries list|create|edit|delete",
+		description: "List, create, edit or delete time entries",
+		run:         runEntries,
+	})
+}
+
+// entrySubCommands contains the sub commands of the entries command.
+var entrySubCommands = map[string]func(env *environment, args []string) error{
+	"list":   listEntries,
+	"create": createEntry,
+	"edit":   editEntry,
+	"delete": deleteEntries,
+}
+
+// runEntries executes the selected entries sub command.
+func runEntries(env *environment, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("Usage: toggl entries list|create|edit|delete [options]")
+	}
+
+	subCommand, exists := entrySubCommands[args[0]]
+	if !exists {
+		return newUsageError("Unknown entries command %q. Available: list, create, edit, delete", args[0])
+	}
+
+	return subCommand(env, args[1:])
+}
+
+// listEntries prints the time entries of the selected date range.
+func listEntries(env *environment, args []string) error {
+	flags := newFlagSet("entries list")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 7 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	workspaceName := flags.String("workspace", "", "Only list entries of this workspace (name or ID)")
+	projectName := flags.String("project", "", "Only list entries of this project (name or ID)")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	now := env.now()
+	start, end, rangeError := parseDateRange(*from, *to, 7, now, env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	var workspace model.Workspace
+	if *workspaceName != "" || *projectName != "" {
+		selectedWorkspace, workspaceError := resolveWorkspace(env, *workspaceName)
+		if workspaceError != nil {
+			return workspaceError
+		}
+
+		workspace = selectedWorkspace
+	}
+
+	var project model.Project
+	if *projectName != "" {
+		selectedProject, projectError := resolveProject(env, workspace.ID, *projectName)
+		if projectError != nil {
+			return projectError
+		}
+
+		project = selectedProject
+	}
+
+	timeEntries, timeEntriesError := env.api.GetTimeEntries(start, end)
+	if timeEntriesError != nil {
+		return timeEntriesError
+	}
+
+	var selectedTimeEntries []model.TimeEntry
+	for _, timeEntry := range timeEntries {
+		if workspace.ID != 0 && timeEntry.Wid != workspace.ID {
+			continue
+		}
+
+		if project.ID != 0 && timeEntry.Pid != project.ID {
+			continue
+		}
+
+		selectedTimeEntries = append(selectedTimeEntries, timeEntry)
+	}
+
+	projects, projectsError := getProjects(env, selectedTimeEntries)
+	if projectsError != nil {
+		return projectsError
+	}
+
+	var clients []model.Client
+	if output.needsClients(false) {
+		allClients, clientsError := env.api.GetClients()
+		if clientsError != nil {
+			return clientsError
+		}
+
+		clients = allClients
+	}
+
+	formatter, formatterError := output.formatter(projects, clients)
+	if formatterError != nil {
+		return formatterError
+	}
+
+	return formatter.WriteTimeEntries(env.stdout, selectedTimeEntries)
+}
+
+// createEntry creates a new time entry.
+func createEntry(env *environment, args []string) error {
+	flags := newFlagSet("entries create")
+	options := addEntryFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *options.start == "" {
+		return newUsageError("entries create: --start is required")
+	}
+
+	if *options.stop == "" && *options.duration == "" {
+		return newUsageError("entries create: --stop or --duration is required")
+	}
+
+	var timeEntry model.TimeEntry
+	if err := options.apply(env, &timeEntry, visitedFlags(flags)); err != nil {
+		return err
+	}
+
+	createdTimeEntry, createError := env.api.CreateTimeEntry(timeEntry)
+	if createError != nil {
+		return createError
+	}
+
+	if createdTimeEntry.ID < 0 {
+		fmt.Fprintf(env.stdout, "Queued time entry %d, it will be created once Toggl can be reached\n", createdTimeEntry.ID)
+		return nil
+	}
+
+	fmt.Fprintf(env.stdout, "Created time entry %d\n", createdTimeEntry.ID)
+	return nil
+}
+
+// editEntry updates the time entry with the given ID.
+func editEntry(env *environment, args []string) error {
+	flags := newFlagSet("entries edit")
+	options := addEntryFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return newUsageError("Usage: toggl entries edit [options] <id>")
+	}
+
+	id, parseError := parseID(flags.Arg(0))
+	if parseError != nil {
+		return parseError
+	}
+
+	timeEntry, getError := env.api.GetTimeEntry(id)
+	if getError != nil {
+		return getError
+	}
+
+	visited := visitedFlags(flags)
+	if err := options.apply(env, &timeEntry, visited); err != nil {
+		return err
+	}
+
+	if _, patchError := env.api.PatchTimeEntry(id, entryChanges(timeEntry, visited)); patchError != nil {
+		return patchError
+	}
+
+	fmt.Fprintf(env.stdout, "Updated time entry %d\n", id)
+	return nil
+}
+
+// deleteEntries deletes the time entries with the given IDs.
+func deleteEntries(env *environment, args []string) error {
+	flags := newFlagSet("entries delete")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return newUsageError("Usage: toggl entries delete <id> [<id> ...]")
+	}
+
+	var ids []int
+	for _, arg := range flags.Args() {
+		id, parseError := parseID(arg)
+		if parseError != nil {
+			return parseError
+		}
+
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		if err := env.api.DeleteTimeEntry(id); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(env.stdout, "Deleted time entry %d\n", id)
+	}
+
+	return nil
+}
+
+// entryOptions contains the command line options describing a time entry.
+type entryOptions struct {
+	workspace   *string
+	project     *string
+	description *string
+	start       *string
+	stop        *string
+	duration    *string
+	tags        *string
+	billable    *bool
+}
+
+// addEntryFlags registers the time entry options on the given flag set.
+func addEntryFlags(flags *flag.FlagSet) entryOptions {
+	return entryOptions{
+		workspace:   flags.String("workspace", "", "The workspace (name or ID)"),
+		project:     flags.String("project", "", "The project (name or ID)"),
+		description: flags.String("description", "", "The description"),
+		start:       flags.String("start", "", "The start time (e.g. 2016-09-06 09:00 or 09:00)"),
+		stop:        flags.String("stop", "", "The stop time (e.g. 2016-09-06 17:30 or 17:30)"),
+		duration:    flags.String("duration", "", "The duration (e.g. 1h30m); alternative to --stop"),
+		tags:        flags.String("tags", "", "A comma separated list of tags"),
+		billable:    flags.Bool("billable", false, "Mark the time entry as billable"),
+	}
+}
+
+// apply sets the given (visited) options on the given time entry.
+func (options entryOptions) apply(env *environment, timeEntry *model.TimeEntry, visited map[string]bool) error {
+	now := env.now()
+
+	// edited time entries keep their workspace unless another one is given
+	if (visited["workspace"] && *options.workspace != "") || timeEntry.Wid == 0 {
+		workspace, workspaceError := resolveWorkspace(env, *options.workspace)
+		if workspaceError != nil {
+			return workspaceError
+		}
+
+		timeEntry.Wid = workspace.ID
+	}
+
+	if visited["project"] {
+		timeEntry.Pid = 0
+		if *options.project != "" {
+			project, projectError := resolveProject(env, timeEntry.Wid, *options.project)
+			if projectError != nil {
+				return projectError
+			}
+
+			timeEntry.Pid = project.ID
+		}
+	}
+
+	if visited["description"] {
+		timeEntry.Description = *options.description
+	}
+
+	if visited["tags"] {
+		timeEntry.Tags = parseTags(*options.tags)
+	}
+
+	if visited["billable"] {
+		timeEntry.Billable = *options.billable
+	}
+
+	// running time entries keep running unless a stop or duration is given
+	if isRunning(*timeEntry) && !visited["stop"] && !visited["duration"] {
+		if visited["start"] {
+			start, parseError := parseTime(*options.start, now)
+			if parseError != nil {
+				return parseError
+			}
+
+			timeEntry.Start = start
+			timeEntry.Duration = -int(start.Unix())
+		}
+
+		return nil
+	}
+
+	duration := timeEntry.Stop.Sub(timeEntry.Start)
+	if visited["start"] {
+		start, parseError := parseTime(*options.start, now)
+		if parseError != nil {
+			return parseError
+		}
+
+		timeEntry.Start = start
+		timeEntry.Stop = start.Add(duration)
+	}
+
+	if visited["stop"] {
+		stop, parseError := parseTime(*options.stop, timeEntry.Start.In(now.Location()))
+		if parseError != nil {
+			return parseError
+		}
+
+		timeEntry.Stop = stop
+	}
+
+	if visited["duration"] {
+		parsedDuration, parseError := time.ParseDuration(*options.duration)
+		if parseError != nil {
+			return newUsageError("%q is not a valid duration (e.g. 1h30m)", *options.duration)
+		}
+
+		timeEntry.Stop = timeEntry.Start.Add(parsedDuration)
+	}
+
+	if timeEntry.Stop.Before(timeEntry.Start) {
+		return newUsageError("The stop time must not be before the start time")
+	}
+
+	timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+	return nil
+}
+
+// entryChanges returns the fields of the given edited time entry
+// which have been changed by the given flags.
+func entryChanges(timeEntry model.TimeEntry, visited map[string]bool) model.TimeEntryChanges {
+	var changes model.TimeEntryChanges
+	if visited["workspace"] || visited["project"] {
+		changes.Wid = model.Int(timeEntry.Wid)
+		changes.Pid = model.Int(timeEntry.Pid)
+	}
+
+	if visited["description"] {
+		changes.Description = model.String(timeEntry.Description)
+	}
+
+	if visited["tags"] {
+		changes.Tags = model.Strings(timeEntry.Tags)
+	}
+
+	if visited["billable"] {
+		changes.Billable = model.Bool(timeEntry.Billable)
+	}
+
+	if visited["start"] || visited["stop"] || visited["duration"] {
+		changes.Start = model.Time(timeEntry.Start)
+		changes.Duration = model.Int(timeEntry.Duration)
+		if !isRunning(timeEntry) {
+			changes.Stop = model.Time(timeEntry.Stop)
+		}
+	}
+
+	return changes
+}
+
+// visitedFlags returns the names of all flags which have been set.
+func visitedFlags(flags *flag.FlagSet) map[string]bool {
+	visited := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+
+	return visited
+}
+
+// parseTags splits the given comma separated list of tags.
+func parseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// parseID parses the given time entry ID.
+func parseID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, newUsageError("%q is not a valid ID", value)
+	}
+
+	return id, nil
+}
+
+// isRunning returns true if the given time entry is still running.
+func isRunning(timeEntry model.TimeEntry) bool {
+	return timeEntry.Duration < 0
+}
+
+// getDuration returns the tracked duration of the given time entry.
+// The duration of running time entries is measured until the given time.
+func getDuration(timeEntry model.TimeEntry, now time.Time) time.Duration {
+	if isRunning(timeEntry) {
+		return now.Sub(timeEntry.Start)
+	}
+
+	return timeEntry.Stop.Sub(timeEntry.Start)
+}
+
+// getProjectNames returns the names of the projects of the
+// workspaces referenced by the given time entries.
+func getProjectNames(env *environment, timeEntries []model.TimeEntry) (map[int]string, error) {
+	projects, projectsError := getProjects(env, timeEntries)
+	if projectsError != nil {
+		return nil, projectsError
+	}
+
+	projectNames := make(map[int]string)
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	return projectNames, nil
+}
+
+// getProjects returns the projects of the workspaces
+// referenced by the given time entries.
+func getProjects(env *environment, timeEntries []model.TimeEntry) ([]model.Project, error) {
+	var projects []model.Project
+	visitedWorkspaces := make(map[int]bool)
+
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Pid == 0 || visitedWorkspaces[timeEntry.Wid] {
+			continue
+		}
+
+		visitedWorkspaces[timeEntry.Wid] = true
+
+		workspaceProjects, projectsError := env.api.GetProjects(timeEntry.Wid)
+		if projectsError != nil {
+			return nil, projectsError
+		}
+
+		projects = append(projects, workspaceProjects...)
+	}
+
+	return projects, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/andreaskoch/togglapi/metrics"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "exporter",
+		usage:       "exporter [--listen address]",
+		description: "Serve the tracked hours as Prometheus metrics",
+		run:         runExporter,
+	})
+}
+
+// runExporter polls the time entries periodically and serves the tracked
+// hours and the request metrics of the API client until it is interrupted.
+func runExporter(env *environment, args []string) error {
+	flags := newFlagSet("exporter")
+	listen := flags.String("listen", "127.0.0.1:9110", "The address of the HTTP endpoint")
+	interval := flags.Duration("interval", metrics.DefaultInterval, "The time between two polls of the time entries")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	weekStart := env.weekStart()
+	exporter := metrics.NewExporter(env.api, env.requestMetrics, metrics.Options{
+		Location:  env.timeZone(),
+		WeekStart: &weekStart,
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Addr: *listen, Handler: mux}
+
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		close(stop)
+		server.Close()
+	}()
+
+	go exporter.Watch(*interval, stop, func(err error) {
+		if err != nil {
+			fmt.Fprintf(env.stderr, "%s\n", err)
+		}
+	})
+
+	fmt.Fprintf(env.stderr, "Serving metrics on http://%s/metrics (stop with Ctrl+C)\n", *listen)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/gitlog"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "gitlog",
+		usage:       "gitlog [--create] <repository>[=project] ...",
+		description: "Propose time entries from the commits of git repositories",
+		run:         runGitlog,
+	})
+}
+
+// runGitlog proposes (or creates) time entries for the work sessions
+// found in the commit history of the given repositories.
+func runGitlog(env *environment, args []string) error {
+	flags := newFlagSet("gitlog")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 7 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	author := flags.String("author", "", "The commit author (default: the user.email of each repository)")
+	gap := flags.Duration("gap", gitlog.DefaultGap, "The longest pause between two commits of one session")
+	padding := flags.Duration("padding", gitlog.DefaultPadding, "The time added before the first commit of a session")
+	create := flags.Bool("create", false, "Create the proposed time entries")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return newUsageError("Usage: toggl gitlog [options] <repository>[=project] ...")
+	}
+
+	now := env.now()
+	start, end, rangeError := parseDateRange(*from, *to, 7, now, env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	options := gitlog.Options{
+		Gap:         *gap,
+		Padding:     *padding,
+		WorkspaceID: workspace.ID,
+		Projects:    make(map[string]int),
+		Now:         now,
+	}
+
+	var commits []gitlog.Commit
+	for _, argument := range flags.Args() {
+		repository, projectName := argument, ""
+		if index := strings.LastIndex(argument, "="); index >= 0 {
+			repository, projectName = argument[:index], argument[index+1:]
+		}
+
+		if projectName != "" {
+			project, projectError := resolveProject(env, workspace.ID, projectName)
+			if projectError != nil {
+				return projectError
+			}
+
+			options.Projects[repository] = project.ID
+		}
+
+		repositoryCommits, readError := gitlog.Read(repository, start, end, *author)
+		if readError != nil {
+			return readError
+		}
+
+		commits = append(commits, repositoryCommits...)
+	}
+
+	existing, existingError := env.api.GetTimeEntries(start.Add(-24*time.Hour), end)
+	if existingError != nil {
+		return existingError
+	}
+
+	proposals := gitlog.Propose(commits, existing, options)
+	for _, proposal := range proposals {
+		timeEntry := proposal.TimeEntry
+		fmt.Fprintf(env.stdout, "%s - %s %-20s %d commits %q\n",
+			timeEntry.Start.In(env.timeZone()).Format("2006-01-02 15:04"),
+			timeEntry.Stop.In(env.timeZone()).Format("15:04"),
+			proposal.Repository,
+			len(proposal.Commits),
+			timeEntry.Description,
+		)
+	}
+
+	if !*create {
+		fmt.Fprintf(env.stderr, "%d time entries proposed (create them with --create)\n", len(proposals))
+		return nil
+	}
+
+	created, createError := gitlog.Create(env.api, proposals)
+	fmt.Fprintf(env.stderr, "Created %d time entries\n", len(created))
+	return createError
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/andreaskoch/togglapi/csvimport"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "import",
+		usage:       "import [--dry-run] [--map m] <file.csv>",
+		description: "Import time entries from a CSV file",
+		run:         importEntries,
+	})
+}
+
+// importEntries imports the time entries of a CSV file.
+func importEntries(env *environment, args []string) error {
+	flags := newFlagSet("import")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	mappingValue := flags.String("map", "", "The column mapping (e.g. date=Day,start=From,end=To,description=Task)")
+	delimiter := flags.String("delimiter", ",", "The field delimiter")
+	dateLayout := flags.String("date-layout", "2006-01-02", "The layout of the date column")
+	timeLayout := flags.String("time-layout", "15:04", "The layout of the start and end columns")
+	tagSeparator := flags.String("tag-separator", ",", "The separator of the tags column")
+	createMissing := flags.Bool("create-missing", false, "Create unknown projects and clients")
+	dryRun := flags.Bool("dry-run", false, "Only print what would be created")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return newUsageError("Usage: toggl import [options] <file.csv>")
+	}
+
+	mapping, mappingError := csvimport.ParseMapping(*mappingValue)
+	if mappingError != nil {
+		return newUsageError("%s", mappingError)
+	}
+
+	comma, _ := utf8.DecodeRuneInString(*delimiter)
+	if utf8.RuneCountInString(*delimiter) != 1 {
+		return newUsageError("The delimiter must be a single character")
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	file, openError := os.Open(flags.Arg(0))
+	if openError != nil {
+		return openError
+	}
+
+	defer file.Close()
+
+	importer := csvimport.New(env.api, csvimport.Options{
+		WorkspaceID:   workspace.ID,
+		Mapping:       mapping,
+		Comma:         comma,
+		DateLayout:    *dateLayout,
+		TimeLayout:    *timeLayout,
+		TagSeparator:  *tagSeparator,
+		Location:      env.timeZone(),
+		CreateMissing: *createMissing,
+	})
+
+	plan, planError := importer.Plan(file)
+	if planError != nil {
+		return planError
+	}
+
+	if *dryRun || !plan.Valid() {
+		if err := plan.Write(env.stdout); err != nil {
+			return err
+		}
+	}
+
+	if !plan.Valid() {
+		return fmt.Errorf("The CSV file contains invalid entries, nothing has been imported")
+	}
+
+	if *dryRun {
+		return nil
+	}
+
+	result, importError := importer.Apply(plan)
+	fmt.Fprintf(env.stdout, "Created %d clients, %d projects and %d time entries\n", len(result.Clients), len(result.Projects), len(result.TimeEntries))
+	return importError
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/billing"
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/invoice"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "invoice",
+		usage:       "invoice --client c [--rate r] [--format f]",
+		description: "Create an invoice for the billable time of a client",
+		run:         createInvoice,
+	})
+}
+
+// createInvoice renders an invoice for the billable time entries
+// of the selected client and optionally tags the invoiced entries.
+func createInvoice(env *environment, args []string) error {
+	flags := newFlagSet("invoice")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	clientName := flags.String("client", "", "The billed client (name or ID)")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 30 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	rate := flags.String("rate", "", "The hourly rate (e.g. 95.00)")
+	currency := flags.String("currency", "EUR", "The currency of the hourly rate")
+	ratesPath := flags.String("rates", "", "The path of a JSON file with billing rates (instead of --rate)")
+	rounding := flags.String("rounding", "", "The rounding (e.g. up/15m, nearest/6m or up/15m/day)")
+	minimum := flags.Duration("minimum", 0, "The minimum billed duration of a time entry (e.g. 15m)")
+	taxes := flags.String("tax", "", "Comma-separated taxes (e.g. VAT=19)")
+	issuer := flags.String("issuer", "", "The issuer, lines separated by | (e.g. \"Jane Doe|Main Street 1\")")
+	number := flags.String("number", "", "The invoice number (default: the next number with the --number-prefix)")
+	numberPrefix := flags.String("number-prefix", "INV-", "The prefix of generated invoice numbers")
+	outputFormat := flags.String("format", invoice.Text, "The output format ("+strings.Join(invoice.Formats(), ", ")+")")
+	outputPath := flags.String("output", "", "The path of the invoice file (default: standard output)")
+	mark := flags.Bool("mark", false, "Tag the invoiced time entries so they are not billed twice")
+	tag := flags.String("tag", "", "The tag of invoiced time entries (default: invoiced-<year>-<month>)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *clientName == "" {
+		return newUsageError("Please select the billed client with --client")
+	}
+
+	start, end, rangeError := parseDateRange(*from, *to, 30, env.now(), env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	clients, clientsError := env.api.GetClients()
+	if clientsError != nil {
+		return clientsError
+	}
+
+	client, clientError := findClient(clients, workspace.ID, *clientName)
+	if clientError != nil {
+		return clientError
+	}
+
+	rates, ratesError := loadRates(*ratesPath, *rate, *currency)
+	if ratesError != nil {
+		return ratesError
+	}
+
+	billingRounding, roundingError := billing.ParseRounding(*rounding)
+	if roundingError != nil {
+		return newUsageError("%s", roundingError)
+	}
+
+	options := invoice.Options{
+		Number:      *number,
+		WorkspaceID: workspace.ID,
+		ClientID:    client.ID,
+		Start:       start,
+		End:         end,
+		Billing: billing.Options{
+			Rates:    rates,
+			Rounding: billingRounding,
+			Minimum:  *minimum,
+			Location: env.timeZone(),
+		},
+	}
+
+	if *issuer != "" {
+		options.Issuer = strings.Split(*issuer, "|")
+	}
+
+	if *taxes != "" {
+		for _, value := range strings.Split(*taxes, ",") {
+			tax, taxError := invoice.ParseTax(value)
+			if taxError != nil {
+				return newUsageError("%s", taxError)
+			}
+
+			options.Taxes = append(options.Taxes, tax)
+		}
+	}
+
+	// the generated number is only used up once the invoice has been rendered
+	var reservation *invoice.NumberReservation
+	if options.Number == "" {
+		store := cache.NewFileStore(dataPath(env.profile, "invoices"))
+		if env.dryRun {
+			store = readOnlyStore{store}
+		}
+
+		nextNumber, numberError := invoice.ReserveNumber(store, *numberPrefix, env.now())
+		if numberError != nil {
+			return numberError
+		}
+
+		reservation = &nextNumber
+		options.Number = nextNumber.Number
+	}
+
+	createdInvoice, createError := invoice.Create(env.api, options)
+	if createError != nil {
+		return createError
+	}
+
+	output := env.stdout
+	if *outputPath != "" {
+		file, fileError := os.Create(*outputPath)
+		if fileError != nil {
+			return fileError
+		}
+
+		defer file.Close()
+		output = file
+	}
+
+	if err := invoice.Render(output, createdInvoice, *outputFormat); err != nil {
+		return err
+	}
+
+	// a number used up by a concurrent run must not be issued twice
+	if reservation != nil {
+		if err := reservation.Commit(); err != nil {
+			return fmt.Errorf("%s. Please create the invoice again; no time entries have been tagged.", err)
+		}
+	}
+
+	if !*mark {
+		return nil
+	}
+
+	if *tag == "" {
+		*tag = invoice.Tag(end)
+	}
+
+	updated, markError := invoice.MarkInvoiced(env.api, createdInvoice, *tag)
+	fmt.Fprintf(env.stderr, "Tagged %d time entries with %q\n", updated, *tag)
+	return markError
+}
+
+// loadRates returns the rates of the given JSON file or
+// a single rate with the given hourly rate and currency.
+func loadRates(path, rate, currency string) ([]billing.Rate, error) {
+	if path != "" {
+		content, readError := ioutil.ReadFile(path)
+		if readError != nil {
+			return nil, readError
+		}
+
+		var rates []billing.Rate
+		if err := json.Unmarshal(content, &rates); err != nil {
+			return nil, fmt.Errorf("Failed to read the rates from %s: %s", path, err)
+		}
+
+		return rates, nil
+	}
+
+	if rate == "" {
+		return nil, newUsageError("Please specify the hourly rate with --rate or --rates")
+	}
+
+	value, parseError := strconv.ParseFloat(rate, 64)
+	if parseError != nil || value < 0 {
+		return nil, newUsageError("%q is not a valid hourly rate (e.g. 95.00)", rate)
+	}
+
+	return []billing.Rate{{Hourly: billing.ToMinorUnits(value, currency), Currency: currency}}, nil
+}
+
+// readOnlyStore ignores all writes to the wrapped store, so that dry runs
+// do not use up invoice numbers.
+type readOnlyStore struct {
+	cache.Store
+}
+
+// Set ignores the given value.
+func (readOnlyStore) Set(key string, value interface{}) error {
+	return nil
+}
+
+// DeletePrefix keeps all values.
+func (readOnlyStore) DeletePrefix(prefix string) error {
+	return nil
+}
+
+// DeleteStoredBefore keeps all values.
+func (readOnlyStore) DeleteStoredBefore(prefix string, storedBefore time.Time) error {
+	return nil
+}
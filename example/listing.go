@@ -0,0 +1,147 @@
+package main
+
+import (
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "workspaces",
+		usage:       "workspaces",
+		description: "List all workspaces",
+		run:         listWorkspaces,
+	})
+
+	registerCommand(command{
+		name:        "clients",
+		usage:       "clients [--workspace w]",
+		description: "List all clients",
+		run:         listClients,
+	})
+
+	registerCommand(command{
+		name:        "projects",
+		usage:       "projects [--workspace w] [--client c]",
+		description: "List the projects of a workspace",
+		run:         listProjects,
+	})
+}
+
+// listWorkspaces prints all workspaces.
+func listWorkspaces(env *environment, args []string) error {
+	flags := newFlagSet("workspaces")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	formatter, formatterError := output.formatter(nil, nil)
+	if formatterError != nil {
+		return formatterError
+	}
+
+	workspaces, workspacesError := env.api.GetWorkspaces()
+	if workspacesError != nil {
+		return workspacesError
+	}
+
+	return formatter.WriteWorkspaces(env.stdout, workspaces)
+}
+
+// listClients prints all clients or the clients of the selected workspace.
+func listClients(env *environment, args []string) error {
+	flags := newFlagSet("clients")
+	workspaceName := flags.String("workspace", "", "Only list the clients of this workspace (name or ID)")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	formatter, formatterError := output.formatter(nil, nil)
+	if formatterError != nil {
+		return formatterError
+	}
+
+	var workspace model.Workspace
+	if *workspaceName != "" {
+		selectedWorkspace, workspaceError := resolveWorkspace(env, *workspaceName)
+		if workspaceError != nil {
+			return workspaceError
+		}
+
+		workspace = selectedWorkspace
+	}
+
+	clients, clientsError := env.api.GetClients()
+	if clientsError != nil {
+		return clientsError
+	}
+
+	var selectedClients []model.Client
+	for _, client := range clients {
+		if workspace.ID != 0 && client.WorkspaceID != workspace.ID {
+			continue
+		}
+
+		selectedClients = append(selectedClients, client)
+	}
+
+	return formatter.WriteClients(env.stdout, selectedClients)
+}
+
+// listProjects prints the projects of the selected workspace.
+func listProjects(env *environment, args []string) error {
+	flags := newFlagSet("projects")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	clientName := flags.String("client", "", "Only list the projects of this client (name or ID)")
+	output := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	var clients []model.Client
+	if *clientName != "" || output.needsClients(true) {
+		workspaceClients, clientsError := env.api.GetClients()
+		if clientsError != nil {
+			return clientsError
+		}
+
+		clients = workspaceClients
+	}
+
+	var client model.Client
+	if *clientName != "" {
+		selectedClient, clientError := findClient(clients, workspace.ID, *clientName)
+		if clientError != nil {
+			return clientError
+		}
+
+		client = selectedClient
+	}
+
+	formatter, formatterError := output.formatter(nil, clients)
+	if formatterError != nil {
+		return formatterError
+	}
+
+	projects, projectsError := env.api.GetProjects(workspace.ID)
+	if projectsError != nil {
+		return projectsError
+	}
+
+	var selectedProjects []model.Project
+	for _, project := range projects {
+		if client.ID != 0 && project.ClientID != client.ID {
+			continue
+		}
+
+		selectedProjects = append(selectedProjects, project)
+	}
+
+	return formatter.WriteProjects(env.stdout, selectedProjects)
+}
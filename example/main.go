@@ -1,92 +1,361 @@
-// Package main implements a simple sample implementation of the Toggl API for go (github.com/andreaskoch/togglapi).
-// The tool will print a list of all your workspaces, clients, project and some recent time entries to give
-// you an idea how you can use the github.com/andreaskoch/togglapi package.
+// Package main implements toggl, a command-line client for the Toggl API
+// which is built on top of the github.com/andreaskoch/togglapi package.
 //
 // Usage:
-// go run main.go your-api-token
+//
+//	toggl [global options] <command> [options] [arguments]
+//
+// Global options:
+//
+//	--profile  The name of the profile from the configuration file to use
+//	--config   The path of the configuration file (default: ~/.toggl.json)
+//	--token    Your Toggl API token (overrides the token of the profile)
+//	--url      The Toggl API URL (overrides the URL of the profile)
+//	--cache    Cache workspaces, clients, projects and time entries (default: true)
+//	--refresh  Discard the cached data before running the command
+//	--dry-run  Print the changes instead of sending them to Toggl
+//
+// Commands:
+//
+//	workspaces                  List all workspaces
+//	clients                     List all clients
+//	projects                    List the projects of a workspace
+//	entries list                List time entries
+//	entries create              Create a time entry
+//	entries edit <id>           Edit a time entry
+//	entries delete <id> [<id>]  Delete one or more time entries
+//	start [description]         Start a new running time entry
+//	stop                        Stop the running time entry
+//	current                     Print the running time entry
+//	report                      Print the tracked time per project, client, tag or period
+//	import <file.csv>           Import time entries from a CSV file
+//	calendar export|import      Export or import time entries as iCalendar files
+//	queue list|sync             List or replay the writes queued while offline
+//	backup <file>               Save all data of the account to an archive
+//	restore <file>              Restore an archive into a workspace
+//	migrate                     Copy time entries to another Toggl account
+//	invoice --client c          Create an invoice for the billable time of a client
+//	check                       Report overlaps, gaps and other problems of time entries
+//	dedupe [--merge] [--undo]   Remove duplicate time entries and merge adjacent ones
+//	rules run|watch --rules f   Assign projects, tags and the billable flag by rules
+//	templates --templates f     Create the recurring time entries of templates
+//	gitlog <repository> ...     Propose time entries from the commits of git repositories
+//	exporter [--listen address] Serve the tracked hours as Prometheus metrics
+//	webhooks list|create|ping|delete|listen Manage webhook subscriptions or receive their events
+//
+// The --workspace and --project options accept names and IDs.
+//
+// Exit codes: 0 on success, 1 if a command failed, 2 for invalid
+// arguments and 3 if the configuration could not be loaded.
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/andreaskoch/togglapi"
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/metrics"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/offline"
 )
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Please supply your API token as an argument\n")
-		os.Exit(1)
+const (
+	// exitSuccess is returned if the command succeeded.
+	exitSuccess = 0
+
+	// exitFailure is returned if the command failed (e.g. because of an API error).
+	exitFailure = 1
+
+	// exitUsage is returned if the command line arguments are invalid.
+	exitUsage = 2
+
+	// exitConfiguration is returned if the configuration could not be loaded.
+	exitConfiguration = 3
+)
+
+// defaultBaseURL contains the URL of the Toggl API.
+const defaultBaseURL = "https://www.toggl.com/api/v8"
+
+// environment contains the dependencies of the commands.
+type environment struct {
+	api     model.TogglAPI
+	queue   *offline.TimeEntryAPI
+	profile profile
+	stdout  io.Writer
+	stderr  io.Writer
+
+	// users returns the Toggl profile of the user (e.g. for the week start).
+	users model.UserAPI
+
+	// zone resolves the reporting time zone (default: time.Local).
+	zone *zoneResolver
+
+	// dryRun is set if changes are only printed (see --dry-run).
+	dryRun bool
+
+	// requestMetrics collects the requests sent to the Toggl API.
+	requestMetrics *metrics.Client
+
+	// webhooks manages the webhook subscriptions.
+	webhooks model.WebhookAPI
+}
+
+// timeZone returns the reporting time zone of the environment. The local
+// time is used if the time zone cannot be resolved; the time entry API
+// reports the error in that case.
+func (env *environment) timeZone() *time.Location {
+	if env.zone == nil {
+		return time.Local
 	}
 
-	apiToken := os.Args[1]
-	baseURL := "https://www.toggl.com/api/v8"
-	api := togglapi.NewAPI(baseURL, apiToken)
+	location, err := env.zone.resolve()
+	if err != nil || location == nil {
+		return time.Local
+	}
 
-	// workspaces
-	fmt.Println("Workspaces:")
+	return location
+}
 
-	workspaces, workspacesError := api.GetWorkspaces()
-	if workspacesError != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get workspaces: %s", workspacesError)
-		os.Exit(1)
+// weekStart returns the first day of the week of the profile. Without a
+// week start in the profile the one of the Toggl profile of the user is
+// used, and Monday if it cannot be fetched.
+func (env *environment) weekStart() time.Weekday {
+	if env.profile.WeekStart != "" || env.users == nil {
+		return env.profile.weekStart()
 	}
 
-	for _, workspace := range workspaces {
-		fmt.Println(workspace.Name)
+	user, err := env.users.GetCurrentUser()
+	if err != nil || user.BeginningOfWeek < int(time.Sunday) || user.BeginningOfWeek > int(time.Saturday) {
+		return env.profile.weekStart()
 	}
 
-	fmt.Println("")
+	return time.Weekday(user.BeginningOfWeek)
+}
+
+// now returns the current time in the reporting time zone.
+func (env *environment) now() time.Time {
+	return time.Now().In(env.timeZone())
+}
+
+// command defines a single sub command of the toggl command-line tool.
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(env *environment, args []string) error
+}
+
+// commands contains all available sub commands.
+var commands = map[string]command{}
+
+// registerCommand adds the given command to the list of available commands.
+func registerCommand(cmd command) {
+	commands[cmd.name] = cmd
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command specified by the given arguments
+// and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
 
-	// clients
-	fmt.Println("Clients:")
+	globalFlags := flag.NewFlagSet("toggl", flag.ContinueOnError)
+	globalFlags.SetOutput(stderr)
+	globalFlags.Usage = func() { printUsage(stderr) }
 
-	clients, clientsError := api.GetClients()
-	if clientsError != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get clients: %s", clientsError)
-		os.Exit(1)
+	profileName := globalFlags.String("profile", os.Getenv("TOGGL_PROFILE"), "The name of the profile to use")
+	configPath := globalFlags.String("config", os.Getenv("TOGGL_CONFIG"), "The path of the configuration file")
+	token := globalFlags.String("token", os.Getenv("TOGGL_API_TOKEN"), "Your Toggl API token")
+	baseURL := globalFlags.String("url", "", "The Toggl API URL")
+	useCache := globalFlags.Bool("cache", true, "Cache workspaces, clients, projects and time entries")
+	refresh := globalFlags.Bool("refresh", false, "Discard the cached data before running the command")
+	dryRun := globalFlags.Bool("dry-run", false, "Print the changes instead of sending them to Toggl")
+
+	if err := globalFlags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitSuccess
+		}
+
+		return exitUsage
 	}
 
-	for _, client := range clients {
-		fmt.Println(client.Name)
+	if globalFlags.NArg() == 0 || globalFlags.Arg(0) == "help" {
+		printUsage(stdout)
+		return exitSuccess
 	}
 
-	fmt.Println("")
+	cmd, exists := commands[globalFlags.Arg(0)]
+	if !exists {
+		fmt.Fprintf(stderr, "Unknown command %q\n\n", globalFlags.Arg(0))
+		printUsage(stderr)
+		return exitUsage
+	}
 
-	// projects
-	fmt.Println("Projects:")
+	selectedProfile, profileError := loadProfile(*configPath, *profileName)
+	if profileError != nil {
+		fmt.Fprintf(stderr, "%s\n", profileError)
+		return exitConfiguration
+	}
 
-	for _, workspace := range workspaces {
+	if *token != "" {
+		selectedProfile.Token = *token
+	}
 
-		projects, projectsError := api.GetProjects(workspace.ID)
-		if projectsError != nil {
-			fmt.Fprintf(os.Stderr, "Failed to get projects: %s", projectsError)
-			os.Exit(1)
-		}
+	if *baseURL != "" {
+		selectedProfile.BaseURL = *baseURL
+	}
+
+	if selectedProfile.Token == "" {
+		fmt.Fprintf(stderr, "No API token configured. Please use the --token option, the TOGGL_API_TOKEN variable or a configuration profile.\n")
+		return exitConfiguration
+	}
+
+	requestMetrics := metrics.NewClient()
+	requester := togglapi.NewObservedRESTRequester(selectedProfile.BaseURL, selectedProfile.Token, requestMetrics)
+	if *dryRun {
+		requester = togglapi.NewDryRunRequester(requester, stderr)
+	}
+
+	// the time zone is only fetched from Toggl when it is needed
+	users := &cachedUserAPI{UserAPI: togglapi.NewUserAPIWithRequester(requester)}
+	zone := &zoneResolver{profile: selectedProfile, users: users}
+	baseAPI := togglapi.NewAPIWithRequester(requester, nil)
+	var api model.TogglAPI = &togglapi.API{
+		WorkspaceAPI: baseAPI,
+		ProjectAPI:   baseAPI,
+		TimeEntryAPI: &zonedTimeEntryAPI{requester: requester, zone: zone},
+		ClientAPI:    baseAPI,
+	}
+
+	webhookRequester := togglapi.NewObservedRESTRequester(selectedProfile.WebhookURL, selectedProfile.Token, requestMetrics)
+	if *dryRun {
+		webhookRequester = togglapi.NewDryRunWebhookRequester(webhookRequester, stderr)
+	}
 
-		for _, project := range projects {
-			fmt.Println(project.Name)
+	if *useCache {
+		cachedAPI := cache.New(api, cache.NewFileStore(dataPath(selectedProfile, "cache")), cache.DefaultTTLs())
+		if *refresh {
+			if err := cachedAPI.Refresh(); err != nil {
+				fmt.Fprintf(stderr, "%s\n", err)
+				return exitFailure
+			}
 		}
+
+		api = cachedAPI
 	}
 
-	fmt.Println("")
+	// queue time entry writes while the Toggl API cannot be reached
+	offlineAPI := offline.New(api, cache.NewFileStore(dataPath(selectedProfile, "queue")))
+	api = &togglapi.API{
+		WorkspaceAPI: api,
+		ProjectAPI:   api,
+		TimeEntryAPI: offlineAPI,
+		ClientAPI:    api,
+	}
+
+	env := &environment{
+		api:            api,
+		queue:          offlineAPI,
+		profile:        selectedProfile,
+		stdout:         stdout,
+		stderr:         stderr,
+		users:          users,
+		zone:           zone,
+		dryRun:         *dryRun,
+		requestMetrics: requestMetrics,
+		webhooks:       togglapi.NewWebhookAPIWithRequester(webhookRequester),
+	}
 
-	// timeEntries
-	fmt.Println("Time Entries:")
+	if err := cmd.run(env, globalFlags.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return exitCode(err)
+	}
 
-	stop := time.Now()
-	start := stop.AddDate(0, -1, 0)
+	return exitSuccess
+}
 
-	timeEntries, timeEntriesError := api.GetTimeEntries(start, stop)
-	if timeEntriesError != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get timeEntries: %s", timeEntriesError)
-		os.Exit(1)
+// dataPath returns the path of the cache or queue file (kind) for the
+// given profile. Each API token gets its own files.
+func dataPath(selectedProfile profile, kind string) string {
+	directory, err := os.UserCacheDir()
+	if err != nil {
+		directory = os.TempDir()
 	}
 
-	for _, timeEntry := range timeEntries {
-		fmt.Printf("%s - %s: %s\n", timeEntry.Start, timeEntry.Stop, timeEntry.Description)
+	hash := sha256.Sum256([]byte(selectedProfile.BaseURL + selectedProfile.Token))
+	return filepath.Join(directory, "togglapi", hex.EncodeToString(hash[:8])+"-"+kind+".json")
+}
+
+// printUsage prints the list of available commands to the given writer.
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: toggl [--profile name] [--config path] [--token token] [--url url] [--cache=false] [--refresh] [--dry-run] <command> [options]\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-36s %s\n", commands[name].usage, commands[name].description)
+	}
+}
+
+// usageError indicates invalid command line arguments.
+type usageError struct {
+	message string
+}
+
+func (err usageError) Error() string {
+	return err.message
+}
+
+// newUsageError creates a new usage error with the given message.
+func newUsageError(format string, args ...interface{}) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// exitCode returns the exit code for the given command error.
+func exitCode(err error) int {
+	if _, isUsageError := err.(usageError); isUsageError {
+		return exitUsage
+	}
+
+	return exitFailure
+}
+
+// newFlagSet creates a flag set for the given command which reports
+// parse errors as usage errors.
+func newFlagSet(cmd string) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parseFlags parses the given arguments and converts
+// parse errors into usage errors which list the available options.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		options := &bytes.Buffer{}
+		flags.SetOutput(options)
+		flags.PrintDefaults()
+
+		return newUsageError("%s: %s\nOptions:\n%s", flags.Name(), err, strings.TrimRight(options.String(), "\n"))
 	}
 
-	fmt.Println("")
+	return nil
 }
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// stubAPI is a model.TogglAPI implementation which serves
+// the given data and records the created time entries.
+type stubAPI struct {
+	workspaces  []model.Workspace
+	clients     []model.Client
+	projects    []model.Project
+	timeEntries []model.TimeEntry
+
+	createdTimeEntries []model.TimeEntry
+	patchedTimeEntries []model.TimeEntryChanges
+}
+
+func (api *stubAPI) GetWorkspaces() ([]model.Workspace, error) { return api.workspaces, nil }
+func (api *stubAPI) GetClients() ([]model.Client, error)       { return api.clients, nil }
+
+func (api *stubAPI) CreateClient(client model.Client) (model.Client, error) {
+	client.ID = len(api.clients) + 1
+	api.clients = append(api.clients, client)
+	return client, nil
+}
+
+func (api *stubAPI) CreateProject(project model.Project) (model.Project, error) {
+	project.ID = len(api.projects) + 1
+	api.projects = append(api.projects, project)
+	return project, nil
+}
+
+func (api *stubAPI) UpdateClient(client model.Client) (model.Client, error) { return client, nil }
+func (api *stubAPI) DeleteClient(id int) error                              { return nil }
+
+func (api *stubAPI) PatchClient(id int, changes model.ClientChanges) (model.Client, error) {
+	return changes.Apply(model.Client{ID: id}), nil
+}
+
+func (api *stubAPI) UpdateProject(project model.Project) (model.Project, error) {
+	return project, nil
+}
+
+func (api *stubAPI) PatchProject(id int, changes model.ProjectChanges) (model.Project, error) {
+	return changes.Apply(model.Project{ID: id}), nil
+}
+
+func (api *stubAPI) DeleteProject(id int) error { return nil }
+
+func (api *stubAPI) GetProjects(workspaceID int) ([]model.Project, error) {
+	var projects []model.Project
+	for _, project := range api.projects {
+		if project.WorkspaceID == workspaceID {
+			projects = append(projects, project)
+		}
+	}
+
+	return projects, nil
+}
+
+func (api *stubAPI) CreateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	timeEntry.ID = len(api.createdTimeEntries) + 1
+	api.createdTimeEntries = append(api.createdTimeEntries, timeEntry)
+	return timeEntry, nil
+}
+
+func (api *stubAPI) GetTimeEntry(id int) (model.TimeEntry, error) {
+	for _, timeEntry := range api.timeEntries {
+		if timeEntry.ID == id {
+			return timeEntry, nil
+		}
+	}
+
+	return model.TimeEntry{}, nil
+}
+
+func (api *stubAPI) UpdateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	return timeEntry, nil
+}
+
+func (api *stubAPI) PatchTimeEntry(id int, changes model.TimeEntryChanges) (model.TimeEntry, error) {
+	api.patchedTimeEntries = append(api.patchedTimeEntries, changes)
+	return changes.Apply(model.TimeEntry{ID: id}), nil
+}
+
+func (api *stubAPI) DeleteTimeEntry(id int) error { return nil }
+
+func (api *stubAPI) StartTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	return timeEntry, nil
+}
+
+func (api *stubAPI) StopTimeEntry(id int) (model.TimeEntry, error) {
+	return model.TimeEntry{ID: id}, nil
+}
+
+func (api *stubAPI) GetCurrentTimeEntry() (model.TimeEntry, error) { return model.TimeEntry{}, nil }
+
+func (api *stubAPI) GetTimeEntries(start, end time.Time) ([]model.TimeEntry, error) {
+	return api.timeEntries, nil
+}
+
+// stubUsers is a model.UserAPI which returns the given user.
+type stubUsers struct {
+	user  model.User
+	calls int
+}
+
+func (users *stubUsers) GetCurrentUser() (model.User, error) {
+	users.calls++
+	return users.user, nil
+}
+
+func (users *stubUsers) GetChanges(since time.Time) (model.ChangeSet, error) {
+	return model.ChangeSet{}, nil
+}
+
+// newTestEnvironment creates a test environment for the given API.
+func newTestEnvironment(api model.TogglAPI) (*environment, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	return &environment{
+		api:    api,
+		stdout: stdout,
+		stderr: &bytes.Buffer{},
+	}, stdout
+}
+
+func Test_run_UnknownCommand_UsageExitCodeIsReturned(t *testing.T) {
+	// act
+	exitCode := run([]string{"--token", "123", "unknown-command"}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	// assert
+	if exitCode != exitUsage {
+		t.Fail()
+		t.Logf("run should have returned exit code %d for an unknown command but returned %d", exitUsage, exitCode)
+	}
+}
+
+func Test_run_ConfigurationFileDoesNotExist_ConfigurationExitCodeIsReturned(t *testing.T) {
+	// act
+	exitCode := run([]string{"--config", "/does/not/exist.json", "workspaces"}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	// assert
+	if exitCode != exitConfiguration {
+		t.Fail()
+		t.Logf("run should have returned exit code %d for a missing configuration file but returned %d", exitConfiguration, exitCode)
+	}
+}
+
+func Test_run_InvalidCommandOption_UsageExitCodeIsReturned(t *testing.T) {
+	// act
+	exitCode := run([]string{"--token", "123", "entries", "list", "--unknown-option"}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	// assert
+	if exitCode != exitUsage {
+		t.Fail()
+		t.Logf("run should have returned exit code %d for an invalid option but returned %d", exitUsage, exitCode)
+	}
+}
+
+func Test_run_DryRun_ChangesArePrintedAndNotSent(t *testing.T) {
+	// arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/me" {
+			fmt.Fprint(w, `{"data": {"id": 1, "timezone": "Europe/Berlin"}}`)
+			return
+		}
+
+		t.Errorf("The %s request to %s should not have been sent", r.Method, r.URL)
+	}))
+	defer server.Close()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	// act
+	exitCode := run([]string{"--token", "123", "--url", server.URL, "--cache=false", "--dry-run", "entries", "delete", "5"}, stdout, stderr)
+
+	// assert
+	if exitCode != exitSuccess || !strings.Contains(stderr.String(), "DELETE time_entries/5") {
+		t.Fail()
+		t.Logf("run should have printed the DELETE request but returned %d (stderr: %q)", exitCode, stderr.String())
+	}
+}
+
+func Test_run_TimeZoneNotNeeded_UserIsNotFetched(t *testing.T) {
+	// arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/workspaces" {
+			fmt.Fprint(w, `[{"id": 1, "name": "Acme"}]`)
+			return
+		}
+
+		t.Errorf("The %s request to %s should not have been sent", r.Method, r.URL)
+	}))
+	defer server.Close()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	// act
+	exitCode := run([]string{"--token", "123", "--url", server.URL, "--cache=false", "workspaces"}, stdout, stderr)
+
+	// assert
+	if exitCode != exitSuccess || !strings.Contains(stdout.String(), "Acme") {
+		t.Fail()
+		t.Logf("run should have listed the workspaces but returned %d (stdout: %q, stderr: %q)", exitCode, stdout.String(), stderr.String())
+	}
+}
+
+func Test_weekStart_NoWeekStartInProfile_WeekStartOfUserIsUsed(t *testing.T) {
+	// arrange
+	users := &stubUsers{user: model.User{BeginningOfWeek: int(time.Sunday)}}
+	env, _ := newTestEnvironment(&stubAPI{})
+	env.users = &cachedUserAPI{UserAPI: users}
+
+	// act
+	env.weekStart()
+	weekStart := env.weekStart()
+
+	// assert
+	if weekStart != time.Sunday || users.calls != 1 {
+		t.Fail()
+		t.Logf("weekStart should have fetched the week start of the user once but returned %s (calls: %d)", weekStart, users.calls)
+	}
+}
+
+func Test_weekStart_WeekStartInProfile_UserIsNotFetched(t *testing.T) {
+	// arrange
+	users := &stubUsers{user: model.User{BeginningOfWeek: int(time.Sunday)}}
+	env, _ := newTestEnvironment(&stubAPI{})
+	env.users = users
+	env.profile.WeekStart = "tuesday"
+
+	// act
+	weekStart := env.weekStart()
+
+	// assert
+	if weekStart != time.Tuesday || users.calls != 0 {
+		t.Fail()
+		t.Logf("weekStart should have returned the week start of the profile but returned %s (calls: %d)", weekStart, users.calls)
+	}
+}
+
+func Test_createEntry_ProjectNameGiven_ProjectIDIsUsed(t *testing.T) {
+	// arrange
+	api := &stubAPI{
+		workspaces: []model.Workspace{{ID: 1, Name: "Acme"}},
+		projects:   []model.Project{{ID: 10, WorkspaceID: 1, Name: "Website"}, {ID: 11, WorkspaceID: 1, Name: "App"}},
+	}
+
+	env, _ := newTestEnvironment(api)
+
+	// act
+	err := createEntry(env, []string{"--project", "website", "--start", "2016-09-06 09:00", "--duration", "1h30m"})
+
+	// assert
+	if err != nil || len(api.createdTimeEntries) != 1 {
+		t.Fatalf("createEntry should have created one time entry (Error: %v)", err)
+	}
+
+	timeEntry := api.createdTimeEntries[0]
+	if timeEntry.Wid != 1 || timeEntry.Pid != 10 || timeEntry.Duration != 5400 {
+		t.Fail()
+		t.Logf("createEntry created an unexpected time entry: %#v", timeEntry)
+	}
+}
+
+func Test_createEntry_NoStopOrDuration_UsageErrorIsReturned(t *testing.T) {
+	// arrange
+	env, _ := newTestEnvironment(&stubAPI{})
+
+	// act
+	err := createEntry(env, []string{"--start", "2016-09-06 09:00"})
+
+	// assert
+	if exitCode(err) != exitUsage {
+		t.Fail()
+		t.Logf("createEntry should have returned a usage error but returned %v", err)
+	}
+}
+
+func Test_editEntry_RunningTimeEntry_TimeEntryKeepsRunning(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 9, 0, 0, 0, time.UTC)
+	api := &stubAPI{
+		workspaces:  []model.Workspace{{ID: 1, Name: "Acme"}},
+		timeEntries: []model.TimeEntry{{ID: 7, Wid: 1, Start: start, Duration: -int(start.Unix())}},
+	}
+
+	env, _ := newTestEnvironment(api)
+
+	// act
+	err := editEntry(env, []string{"--description", "Review", "7"})
+
+	// assert
+	if err != nil || len(api.patchedTimeEntries) != 1 {
+		t.Fatalf("editEntry should have patched one time entry (Error: %v)", err)
+	}
+
+	changes := api.patchedTimeEntries[0]
+	if changes.Description == nil || *changes.Description != "Review" || changes.Stop != nil || changes.Duration != nil || changes.Start != nil || changes.Pid != nil {
+		t.Fail()
+		t.Logf("editEntry should only have changed the description but patched %#v", changes)
+	}
+}
+
+func Test_editEntry_ProjectWithoutWorkspace_ProjectOfEntryWorkspaceIsUsed(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 9, 0, 0, 0, time.UTC)
+	api := &stubAPI{
+		workspaces:  []model.Workspace{{ID: 1, Name: "Acme"}, {ID: 2, Name: "Private"}},
+		projects:    []model.Project{{ID: 10, WorkspaceID: 1, Name: "Website"}, {ID: 20, WorkspaceID: 2, Name: "Website"}},
+		timeEntries: []model.TimeEntry{{ID: 7, Wid: 2, Start: start, Stop: start.Add(time.Hour), Duration: 3600}},
+	}
+
+	env, _ := newTestEnvironment(api)
+	env.profile.Workspace = "Acme"
+
+	// act
+	err := editEntry(env, []string{"--project", "website", "7"})
+
+	// assert
+	if err != nil || len(api.patchedTimeEntries) != 1 {
+		t.Fatalf("editEntry should have patched one time entry (Error: %v)", err)
+	}
+
+	changes := api.patchedTimeEntries[0]
+	if changes.Wid == nil || *changes.Wid != 2 || changes.Pid == nil || *changes.Pid != 20 {
+		t.Fail()
+		t.Logf("editEntry should have used the project of workspace 2 but patched %#v", changes)
+	}
+}
+
+func Test_listEntries_CSVFormat_SelectedColumnsAreWritten(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	api := &stubAPI{
+		workspaces:  []model.Workspace{{ID: 1, Name: "Acme"}},
+		projects:    []model.Project{{ID: 10, WorkspaceID: 1, Name: "Website"}},
+		timeEntries: []model.TimeEntry{{ID: 5, Wid: 1, Pid: 10, Start: start, Stop: start.Add(time.Hour), Duration: 3600}},
+	}
+
+	env, stdout := newTestEnvironment(api)
+
+	// act
+	err := listEntries(env, []string{"--format", "csv", "--columns", "id,project,duration"})
+
+	// assert
+	expected := "id,project,duration\n5,Website,3600\n"
+	if err != nil || stdout.String() != expected {
+		t.Fail()
+		t.Logf("listEntries should have written %q but wrote %q (Error: %v)", expected, stdout.String(), err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+
+	"github.com/andreaskoch/togglapi"
+	"github.com/andreaskoch/togglapi/migrate"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "migrate",
+		usage:       "migrate --target-token t [--dry-run]",
+		description: "Copy time entries to another Toggl account",
+		run:         runMigrate,
+	})
+}
+
+// runMigrate copies the time entries of the selected period to the
+// workspace of another Toggl account.
+func runMigrate(env *environment, args []string) error {
+	flags := newFlagSet("migrate")
+	workspaceName := flags.String("workspace", "", "Only copy the time entries of this workspace (name or ID, default: all)")
+	targetToken := flags.String("target-token", os.Getenv("TOGGL_TARGET_API_TOKEN"), "The API token of the target account")
+	targetURL := flags.String("target-url", "", "The Toggl API URL of the target account (default: the URL of the profile)")
+	targetWorkspaceName := flags.String("target-workspace", "", "The workspace of the target account (name or ID)")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 30 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	dryRun := flags.Bool("dry-run", false, "Only print what would be copied")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *targetToken == "" {
+		return newUsageError("Please specify the API token of the target account with --target-token or TOGGL_TARGET_API_TOKEN")
+	}
+
+	start, end, rangeError := parseDateRange(*from, *to, 30, env.now(), env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	options := migrate.Options{
+		Start:  start,
+		End:    end,
+		DryRun: *dryRun,
+	}
+
+	if *workspaceName != "" {
+		workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+		if workspaceError != nil {
+			return workspaceError
+		}
+
+		options.SourceWorkspaceID = workspace.ID
+	}
+
+	targetProfile := profile{Token: *targetToken, BaseURL: env.profile.BaseURL}
+	if *targetURL != "" {
+		targetProfile.BaseURL = *targetURL
+	}
+
+	targetEnv := &environment{
+		api:     togglapi.NewAPI(targetProfile.BaseURL, targetProfile.Token),
+		profile: targetProfile,
+		stdout:  env.stdout,
+		stderr:  env.stderr,
+	}
+
+	targetWorkspace, targetWorkspaceError := resolveWorkspace(targetEnv, *targetWorkspaceName)
+	if targetWorkspaceError != nil {
+		return targetWorkspaceError
+	}
+
+	options.DestinationWorkspaceID = targetWorkspace.ID
+
+	report, migrateError := migrate.Migrate(env.api, targetEnv.api, options)
+	if err := report.Write(env.stdout); err != nil {
+		return err
+	}
+
+	return migrateError
+}
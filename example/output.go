@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/andreaskoch/togglapi/format"
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// outputOptions contains the command line options controlling the output format.
+type outputOptions struct {
+	format    *string
+	columns   *string
+	template  *string
+	durations *string
+}
+
+// addOutputFlags registers the output options on the given flag set.
+func addOutputFlags(flags *flag.FlagSet) outputOptions {
+	return outputOptions{
+		format:    flags.String("format", format.Table, "The output format ("+strings.Join(format.Names(), ", ")+")"),
+		columns:   flags.String("columns", "", "A comma separated list of columns (e.g. id,name)"),
+		template:  flags.String("template", "", "The Go template for the template format (e.g. '{{.ID}} {{.Description}}')"),
+		durations: flags.String("durations", "", "The duration style (seconds, human, clock, decimal)"),
+	}
+}
+
+// needsClients returns true if the selected columns or template
+// reference client names. defaultColumnsIncludeClient defines whether
+// the default columns of the listed entity contain a client name.
+func (options outputOptions) needsClients(defaultColumnsIncludeClient bool) bool {
+	if *options.format == format.Template {
+		return strings.Contains(*options.template, "client")
+	}
+
+	if *options.columns == "" {
+		return defaultColumnsIncludeClient
+	}
+
+	return strings.Contains(*options.columns, "client")
+}
+
+// formatter creates a formatter for the selected output options.
+func (options outputOptions) formatter(projects []model.Project, clients []model.Client) (format.Formatter, error) {
+	durationStyle, durationStyleError := format.ParseDurationStyle(*options.durations)
+	if durationStyleError != nil {
+		return nil, newUsageError("%s", durationStyleError)
+	}
+
+	formatter, formatterError := format.New(*options.format, format.Options{
+		Columns:       parseTags(*options.columns),
+		Template:      *options.template,
+		DurationStyle: durationStyle,
+		Projects:      projects,
+		Clients:       clients,
+	})
+
+	if formatterError != nil {
+		return nil, newUsageError("%s", formatterError)
+	}
+
+	return formatter, nil
+}
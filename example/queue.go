@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "queue",
+		usage:       "queue list|sync",
+		description: "List or replay the writes queued while offline",
+		run:         runQueue,
+	})
+}
+
+// runQueue executes the selected queue sub command.
+func runQueue(env *environment, args []string) error {
+	if len(args) != 1 || (args[0] != "list" && args[0] != "sync") {
+		return newUsageError("Usage: toggl queue list|sync")
+	}
+
+	if args[0] == "sync" {
+		return syncQueue(env)
+	}
+
+	return listQueue(env)
+}
+
+// listQueue prints the queued operations and the conflicts of previous replays.
+func listQueue(env *environment) error {
+	operations, pendingError := env.queue.Pending()
+	if pendingError != nil {
+		return pendingError
+	}
+
+	table := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(table, "QUEUED AT\tOPERATION\tID\tDESCRIPTION\n")
+	for _, operation := range operations {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\n",
+			operation.QueuedAt.In(env.timeZone()).Format("2006-01-02 15:04"),
+			operation.Kind,
+			operation.TimeEntry.ID,
+			operation.TimeEntry.Description,
+		)
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	conflicts, conflictsError := env.queue.Conflicts()
+	if conflictsError != nil {
+		return conflictsError
+	}
+
+	for _, conflict := range conflicts {
+		fmt.Fprintf(env.stdout, "Conflict: %s %d was rejected: %s\n", conflict.Operation.Kind, conflict.Operation.TimeEntry.ID, conflict.Error)
+	}
+
+	return nil
+}
+
+// syncQueue replays the queued operations and reports all conflicts.
+func syncQueue(env *environment) error {
+	result, syncError := env.queue.Sync()
+	if syncError != nil {
+		return syncError
+	}
+
+	conflicts, conflictsError := env.queue.Conflicts()
+	if conflictsError != nil {
+		return conflictsError
+	}
+
+	conflicts = append(conflicts, result.Conflicts...)
+	for _, conflict := range conflicts {
+		fmt.Fprintf(env.stdout, "Conflict: %s %d was rejected: %s\n", conflict.Operation.Kind, conflict.Operation.TimeEntry.ID, conflict.Error)
+	}
+
+	if err := env.queue.ClearConflicts(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "Replayed %d operations, %d pending\n", result.Replayed, result.Pending)
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%d queued operations have been rejected", len(conflicts))
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andreaskoch/togglapi/analytics"
+	"github.com/andreaskoch/togglapi/format"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "report",
+		usage:       "report [--by grouping] [--from date] [--to date]",
+		description: "Print the tracked time per project, client, tag, day, week or month",
+		run:         printReport,
+	})
+}
+
+// printReport prints the tracked time of the selected date range grouped by the selected grouping.
+func printReport(env *environment, args []string) error {
+	flags := newFlagSet("report")
+	by := flags.String("by", "project", "The grouping (day, week, month, project, client, tag or billable)")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 30 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	grouping, groupingError := analytics.ParseGrouping(*by)
+	if groupingError != nil {
+		return newUsageError("%s", groupingError)
+	}
+
+	now := env.now()
+	start, end, rangeError := parseDateRange(*from, *to, 30, now, env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	timeEntries, timeEntriesError := env.api.GetTimeEntries(start, end)
+	if timeEntriesError != nil {
+		return timeEntriesError
+	}
+
+	projects, projectsError := getProjects(env, timeEntries)
+	if projectsError != nil {
+		return projectsError
+	}
+
+	weekStart := env.weekStart()
+	options := analytics.Options{
+		Location:  now.Location(),
+		WeekStart: &weekStart,
+		Projects:  projects,
+		Now:       now,
+	}
+
+	if grouping == analytics.ByClient {
+		clients, clientsError := env.api.GetClients()
+		if clientsError != nil {
+			return clientsError
+		}
+
+		options.Clients = clients
+	}
+
+	sum := analytics.Sum(timeEntries, options)
+	totals := analytics.Group(timeEntries, grouping, options)
+
+	fmt.Fprintf(env.stdout, "%s - %s\n\n", start.Format(dateLayout), end.Format(dateLayout))
+
+	table := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(table, "%s\tDuration\tBillable\t\n", strings.ToUpper(string(grouping[:1]))+string(grouping[1:]))
+	for _, total := range totals {
+		fmt.Fprintf(table, "%s\t%s\t%s\t\n", total.Label, format.Duration(total.Duration, format.Clock), format.Duration(total.Billable, format.Clock))
+	}
+
+	fmt.Fprintf(table, "Total\t%s\t%s\t\n", format.Duration(sum.Duration, format.Clock), format.Duration(sum.Billable, format.Clock))
+	return table.Flush()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// resolveWorkspace returns the workspace matching the given name or ID.
+// If no value is given the workspace of the profile is used. If the
+// profile has no workspace either, the only workspace of the account is
+// returned.
+func resolveWorkspace(env *environment, value string) (model.Workspace, error) {
+	if value == "" {
+		value = env.profile.Workspace
+	}
+
+	workspaces, workspacesError := env.api.GetWorkspaces()
+	if workspacesError != nil {
+		return model.Workspace{}, workspacesError
+	}
+
+	if value == "" {
+		if len(workspaces) == 1 {
+			return workspaces[0], nil
+		}
+
+		return model.Workspace{}, newUsageError("Your account has %d workspaces. Please select one with --workspace.", len(workspaces))
+	}
+
+	var candidates []model.Workspace
+	for _, workspace := range workspaces {
+		if matchesNameOrID(value, workspace.Name, workspace.ID) {
+			candidates = append(candidates, workspace)
+		}
+	}
+
+	index, matchError := selectCandidate("workspace", value, len(candidates))
+	if matchError != nil {
+		return model.Workspace{}, matchError
+	}
+
+	return candidates[index], nil
+}
+
+// resolveProject returns the project of the given workspace
+// matching the given name or ID.
+func resolveProject(env *environment, workspaceID int, value string) (model.Project, error) {
+	projects, projectsError := env.api.GetProjects(workspaceID)
+	if projectsError != nil {
+		return model.Project{}, projectsError
+	}
+
+	var candidates []model.Project
+	for _, project := range projects {
+		if matchesNameOrID(value, project.Name, project.ID) {
+			candidates = append(candidates, project)
+		}
+	}
+
+	index, matchError := selectCandidate("project", value, len(candidates))
+	if matchError != nil {
+		return model.Project{}, matchError
+	}
+
+	return candidates[index], nil
+}
+
+// findClient returns the client of the given workspace
+// matching the given name or ID.
+func findClient(clients []model.Client, workspaceID int, value string) (model.Client, error) {
+	var candidates []model.Client
+	for _, client := range clients {
+		if client.WorkspaceID != workspaceID {
+			continue
+		}
+
+		if matchesNameOrID(value, client.Name, client.ID) {
+			candidates = append(candidates, client)
+		}
+	}
+
+	index, matchError := selectCandidate("client", value, len(candidates))
+	if matchError != nil {
+		return model.Client{}, matchError
+	}
+
+	return candidates[index], nil
+}
+
+// matchesNameOrID returns true if the given value is equal to the given ID
+// or matches the given name (case-insensitive).
+func matchesNameOrID(value, name string, id int) bool {
+	if numericValue, parseError := strconv.Atoi(value); parseError == nil && numericValue == id {
+		return true
+	}
+
+	return strings.EqualFold(strings.TrimSpace(value), name)
+}
+
+// selectCandidate returns the index of the only match or an error if
+// there is no or more than one match.
+func selectCandidate(kind, value string, numberOfCandidates int) (int, error) {
+	switch numberOfCandidates {
+	case 0:
+		return 0, newUsageError("No %s matches %q", kind, value)
+	case 1:
+		return 0, nil
+	default:
+		return 0, newUsageError("%d %ss match %q. Please use the ID instead.", numberOfCandidates, kind, value)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_resolveWorkspace_NameOrIDGiven_WorkspaceIsReturned(t *testing.T) {
+	// arrange
+	api := &stubAPI{
+		workspaces: []model.Workspace{{ID: 1, Name: "Private"}, {ID: 2, Name: "Acme Inc."}},
+	}
+
+	env, _ := newTestEnvironment(api)
+
+	inputs := []string{"2", "Acme Inc.", "acme inc."}
+
+	for _, input := range inputs {
+
+		// act
+		workspace, err := resolveWorkspace(env, input)
+
+		// assert
+		if err != nil || workspace.ID != 2 {
+			t.Fail()
+			t.Logf("resolveWorkspace(%q) should have returned workspace 2 but returned %d (Error: %v)", input, workspace.ID, err)
+		}
+	}
+}
+
+func Test_resolveWorkspace_NoValueAndMultipleWorkspaces_UsageErrorIsReturned(t *testing.T) {
+	// arrange
+	api := &stubAPI{
+		workspaces: []model.Workspace{{ID: 1, Name: "Private"}, {ID: 2, Name: "Acme Inc."}},
+	}
+
+	env, _ := newTestEnvironment(api)
+
+	// act
+	_, err := resolveWorkspace(env, "")
+
+	// assert
+	if exitCode(err) != exitUsage {
+		t.Fail()
+		t.Logf("resolveWorkspace should have returned a usage error but returned %v", err)
+	}
+}
+
+func Test_resolveWorkspace_NoValue_ProfileWorkspaceIsUsed(t *testing.T) {
+	// arrange
+	api := &stubAPI{
+		workspaces: []model.Workspace{{ID: 1, Name: "Private"}, {ID: 2, Name: "Acme Inc."}},
+	}
+
+	env, _ := newTestEnvironment(api)
+	env.profile.Workspace = "Private"
+
+	// act
+	workspace, err := resolveWorkspace(env, "")
+
+	// assert
+	if err != nil || workspace.ID != 1 {
+		t.Fail()
+		t.Logf("resolveWorkspace should have returned the workspace of the profile (Error: %v)", err)
+	}
+}
+
+func Test_resolveProject_AmbiguousName_ErrorIsReturned(t *testing.T) {
+	// arrange
+	api := &stubAPI{
+		projects: []model.Project{{ID: 1, WorkspaceID: 1, Name: "Support"}, {ID: 2, WorkspaceID: 1, Name: "support"}},
+	}
+
+	env, _ := newTestEnvironment(api)
+
+	// act
+	_, err := resolveProject(env, 1, "Support")
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("resolveProject should have returned an error because two projects match")
+	}
+}
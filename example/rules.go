@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/rules"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "rules",
+		usage:       "rules run|watch --rules file",
+		description: "Assign projects, tags and the billable flag by rules",
+		run:         runRules,
+	})
+}
+
+// runRules applies the rules of a JSON file once to the time entries of the
+// selected period (run) or continuously to new time entries (watch).
+func runRules(env *environment, args []string) error {
+	if len(args) == 0 || (args[0] != "run" && args[0] != "watch") {
+		return newUsageError("Usage: toggl rules run|watch --rules file [options]")
+	}
+
+	mode := args[0]
+	flags := newFlagSet("rules " + mode)
+	rulesPath := flags.String("rules", "", "The path of the JSON rules file")
+	from := flags.String("from", "", "The first day (run only, e.g. 2016-09-01, default: 7 days ago)")
+	to := flags.String("to", "", "The last day (run only, e.g. 2016-09-30, default: today)")
+	interval := flags.Duration("interval", 5*time.Minute, "The time between two checks (watch only)")
+	window := flags.Duration("window", rules.DefaultWindow, "The checked period before now (watch only)")
+	dryRun := flags.Bool("dry-run", false, "Only print the changes")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	if *rulesPath == "" {
+		return newUsageError("Please specify the rules file with --rules")
+	}
+
+	file, fileError := os.Open(*rulesPath)
+	if fileError != nil {
+		return fileError
+	}
+
+	set, readError := rules.Read(file)
+	file.Close()
+	if readError != nil {
+		return readError
+	}
+
+	engine := rules.New(env.api, set, rules.Options{Location: env.timeZone(), DryRun: *dryRun})
+
+	if mode == "watch" {
+		stop := make(chan struct{})
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		go func() {
+			<-interrupt
+			close(stop)
+		}()
+
+		fmt.Fprintf(env.stderr, "Applying %d rules every %s (stop with Ctrl+C)\n", set.Len(), *interval)
+		engine.Watch(*interval, *window, stop, func(result rules.Result, err error) {
+			printRuleUpdates(env, result, *dryRun)
+			if err != nil {
+				fmt.Fprintf(env.stderr, "%s\n", err)
+			}
+		})
+
+		return nil
+	}
+
+	start, end, rangeError := parseDateRange(*from, *to, 7, env.now(), env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	result, runError := engine.Run(start, end)
+	printRuleUpdates(env, result, *dryRun)
+	fmt.Fprintf(env.stderr, "%d of %d time entries changed\n", len(result.Updates), result.Checked)
+	return runError
+}
+
+// printRuleUpdates prints the time entries changed by rules.
+func printRuleUpdates(env *environment, result rules.Result, dryRun bool) {
+	prefix := ""
+	if dryRun {
+		prefix = "would update "
+	}
+
+	for _, update := range result.Updates {
+		timeEntry := update.TimeEntry
+		fmt.Fprintf(env.stdout, "%s%d %s %q project=%d tags=%s billable=%t (rules: %s)\n",
+			prefix,
+			timeEntry.ID,
+			timeEntry.Start.In(env.timeZone()).Format("2006-01-02 15:04"),
+			timeEntry.Description,
+			timeEntry.Pid,
+			strings.Join(timeEntry.Tags, ","),
+			timeEntry.Billable,
+			strings.Join(update.Rules, ", "),
+		)
+	}
+}
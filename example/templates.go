@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+
+	"github.com/andreaskoch/togglapi/ical"
+	"github.com/andreaskoch/togglapi/templates"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "templates",
+		usage:       "templates --templates file [--holidays file.ics]",
+		description: "Create the recurring time entries of templates",
+		run:         runTemplates,
+	})
+}
+
+// runTemplates creates the missing time entries of the templates of a
+// JSON file for the selected period, skipping holidays.
+func runTemplates(env *environment, args []string) error {
+	flags := newFlagSet("templates")
+	templatesPath := flags.String("templates", "", "The path of the JSON templates file")
+	holidaysPath := flags.String("holidays", "", "The path of an iCalendar file with holidays (all-day events)")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	from := flags.String("from", "", "The first day (e.g. 2016-09-01, default: 7 days ago)")
+	to := flags.String("to", "", "The last day (e.g. 2016-09-30, default: today)")
+	dryRun := flags.Bool("dry-run", false, "Only print the time entries which would be created")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *templatesPath == "" {
+		return newUsageError("Please specify the templates file with --templates")
+	}
+
+	file, fileError := os.Open(*templatesPath)
+	if fileError != nil {
+		return fileError
+	}
+
+	set, readError := templates.Read(file)
+	file.Close()
+	if readError != nil {
+		return readError
+	}
+
+	start, end, rangeError := parseDateRange(*from, *to, 7, env.now(), env.weekStart())
+	if rangeError != nil {
+		return rangeError
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	options := templates.Options{
+		WorkspaceID: workspace.ID,
+		Start:       start,
+		End:         end,
+		Location:    env.timeZone(),
+		DryRun:      *dryRun,
+	}
+
+	if *holidaysPath != "" {
+		holidaysFile, holidaysFileError := os.Open(*holidaysPath)
+		if holidaysFileError != nil {
+			return holidaysFileError
+		}
+
+		holidays, holidaysError := ical.DecodeHolidays(holidaysFile)
+		holidaysFile.Close()
+		if holidaysError != nil {
+			return holidaysError
+		}
+
+		options.Holidays = holidays
+	}
+
+	report, materializeError := templates.Materialize(env.api, set, options)
+	if err := report.Write(env.stdout); err != nil {
+		return err
+	}
+
+	return materializeError
+}
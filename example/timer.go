@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/format"
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "start",
+		usage:       "start [--project p] [description]",
+		description: "Start a new running time entry",
+		run:         startEntry,
+	})
+
+	registerCommand(command{
+		name:        "stop",
+		usage:       "stop",
+		description: "Stop the running time entry",
+		run:         stopEntry,
+	})
+
+	registerCommand(command{
+		name:        "current",
+		usage:       "current",
+		description: "Print the running time entry",
+		run:         printCurrentEntry,
+	})
+}
+
+// startEntry starts a new running time entry.
+func startEntry(env *environment, args []string) error {
+	flags := newFlagSet("start")
+	workspaceName := flags.String("workspace", "", "The workspace (name or ID)")
+	projectName := flags.String("project", "", "The project (name or ID)")
+	tags := flags.String("tags", "", "A comma separated list of tags")
+	billable := flags.Bool("billable", false, "Mark the time entry as billable")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	timeEntry := model.TimeEntry{
+		Wid:         workspace.ID,
+		Description: strings.Join(flags.Args(), " "),
+		Tags:        parseTags(*tags),
+		Billable:    *billable,
+	}
+
+	if *projectName != "" {
+		project, projectError := resolveProject(env, workspace.ID, *projectName)
+		if projectError != nil {
+			return projectError
+		}
+
+		timeEntry.Pid = project.ID
+	}
+
+	startedTimeEntry, startError := env.api.StartTimeEntry(timeEntry)
+	if startError != nil {
+		return startError
+	}
+
+	fmt.Fprintf(env.stdout, "Started time entry %d: %s\n", startedTimeEntry.ID, startedTimeEntry.Description)
+	return nil
+}
+
+// stopEntry stops the running time entry.
+func stopEntry(env *environment, args []string) error {
+	flags := newFlagSet("stop")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	currentTimeEntry, currentError := env.api.GetCurrentTimeEntry()
+	if currentError != nil {
+		return currentError
+	}
+
+	if currentTimeEntry.ID == 0 {
+		return fmt.Errorf("No time entry is running")
+	}
+
+	stoppedTimeEntry, stopError := env.api.StopTimeEntry(currentTimeEntry.ID)
+	if stopError != nil {
+		return stopError
+	}
+
+	fmt.Fprintf(env.stdout, "Stopped time entry %d after %s: %s\n",
+		stoppedTimeEntry.ID,
+		format.Duration(time.Duration(stoppedTimeEntry.Duration)*time.Second, format.Clock),
+		stoppedTimeEntry.Description,
+	)
+
+	return nil
+}
+
+// printCurrentEntry prints the running time entry.
+func printCurrentEntry(env *environment, args []string) error {
+	flags := newFlagSet("current")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	currentTimeEntry, currentError := env.api.GetCurrentTimeEntry()
+	if currentError != nil {
+		return currentError
+	}
+
+	if currentTimeEntry.ID == 0 {
+		fmt.Fprintf(env.stdout, "No time entry is running\n")
+		return nil
+	}
+
+	projectNames, projectsError := getProjectNames(env, []model.TimeEntry{currentTimeEntry})
+	if projectsError != nil {
+		return projectsError
+	}
+
+	fmt.Fprintf(env.stdout, "%d\t%s\t%s\t%s\n",
+		currentTimeEntry.ID,
+		format.Duration(getDuration(currentTimeEntry, time.Now()), format.Clock),
+		projectNames[currentTimeEntry.Pid],
+		currentTimeEntry.Description,
+	)
+
+	return nil
+}
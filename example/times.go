@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"github.com/andreaskoch/togglapi/date"
+)
+
+// timeLayouts contains the layouts accepted for points in time.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// clockLayouts contains the layouts accepted for times of the current day.
+var clockLayouts = []string{
+	"15:04:05",
+	"15:04",
+}
+
+// dateLayout contains the layout accepted for dates.
+const dateLayout = "2006-01-02"
+
+// parseTime parses the given point in time. Values without a date
+// (e.g. "09:30") refer to the day of the given reference time.
+func parseTime(value string, reference time.Time) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, reference.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+
+	for _, layout := range clockLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, reference.Location()); err == nil {
+			year, month, day := reference.Date()
+			return time.Date(year, month, day, parsed.Hour(), parsed.Minute(), parsed.Second(), 0, reference.Location()), nil
+		}
+	}
+
+	return time.Time{}, newUsageError("%q is not a valid time (e.g. 2006-01-02 15:04 or 15:04)", value)
+}
+
+// parseDateRange returns the time range between the beginning of the from
+// range and the end of the to range. Both accept dates (e.g. "2006-01-02")
+// and the expressions of date.ParseRange (e.g. "last month" or "2016-W36").
+// Empty values default to the given number of days before the reference
+// time and the end of the reference day.
+func parseDateRange(from, to string, defaultDays int, reference time.Time, weekStart time.Weekday) (time.Time, time.Time, error) {
+	options := date.RangeOptions{Location: reference.Location(), WeekStart: &weekStart, Now: reference}
+
+	today, _ := date.ParseRange("today", options)
+	start := today.Start.AddDate(0, 0, -defaultDays)
+	if from != "" {
+		parsed, err := date.ParseRange(from, options)
+		if err != nil {
+			return time.Time{}, time.Time{}, newUsageError("%s", err)
+		}
+
+		start = parsed.Start
+	}
+
+	end := today.End
+	if to != "" {
+		parsed, err := date.ParseRange(to, options)
+		if err != nil {
+			return time.Time{}, time.Time{}, newUsageError("%s", err)
+		}
+
+		end = parsed.End
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, newUsageError("The start date must be before the end date")
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseDateRange_NaturalRangesGiven_BoundariesAreUsed(t *testing.T) {
+	// arrange
+	reference := time.Date(2016, 9, 7, 12, 0, 0, 0, time.UTC)
+
+	// act
+	start, end, err := parseDateRange("last week", "yesterday", 7, reference, time.Sunday)
+
+	// assert
+	if err != nil || start != time.Date(2016, 8, 28, 0, 0, 0, 0, time.UTC) || end != time.Date(2016, 9, 6, 23, 59, 59, 0, time.UTC) {
+		t.Fail()
+		t.Logf("parseDateRange returned %s - %s (Error: %v)", start, end, err)
+	}
+}
+
+func Test_parseDateRange_NoValues_DefaultDaysAreUsed(t *testing.T) {
+	// arrange
+	reference := time.Date(2016, 9, 7, 12, 0, 0, 0, time.UTC)
+
+	// act
+	start, end, err := parseDateRange("", "", 7, reference, time.Monday)
+
+	// assert
+	if err != nil || start != time.Date(2016, 8, 31, 0, 0, 0, 0, time.UTC) || end != time.Date(2016, 9, 7, 23, 59, 59, 0, time.UTC) {
+		t.Fail()
+		t.Logf("parseDateRange returned %s - %s (Error: %v)", start, end, err)
+	}
+}
+
+func Test_parseDateRange_InvalidValue_UsageErrorIsReturned(t *testing.T) {
+	// act
+	_, _, err := parseDateRange("someday", "", 7, time.Now(), time.Monday)
+
+	// assert
+	if _, isUsageError := err.(usageError); !isUsageError {
+		t.Fail()
+		t.Logf("parseDateRange should have returned a usage error but returned %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/webhook"
+)
+
+func init() {
+	registerCommand(command{
+		name:        "webhooks",
+		usage:       "webhooks list|create|ping|delete|listen",
+		description: "Manage webhook subscriptions or receive their events",
+		run:         runWebhooks,
+	})
+}
+
+// runWebhooks executes the selected webhooks sub command.
+func runWebhooks(env *environment, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("Usage: toggl webhooks list|create|ping|delete|listen [options]")
+	}
+
+	switch args[0] {
+	case "list":
+		return listWebhooks(env, args[1:])
+	case "create":
+		return createWebhook(env, args[1:])
+	case "ping", "delete":
+		return changeWebhook(env, args[0], args[1:])
+	case "listen":
+		return listenForWebhooks(env, args[1:])
+	}
+
+	return newUsageError("Usage: toggl webhooks list|create|ping|delete|listen [options]")
+}
+
+// listWebhooks prints the subscriptions of a workspace.
+func listWebhooks(env *environment, args []string) error {
+	flags := newFlagSet("webhooks list")
+	workspaceName := flags.String("workspace", "", "The name or ID of the workspace")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	subscriptions, subscriptionsError := env.webhooks.GetWebhookSubscriptions(workspace.ID)
+	if subscriptionsError != nil {
+		return subscriptionsError
+	}
+
+	table := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(table, "ID\tENABLED\tVALIDATED\tEVENTS\tURL\tDESCRIPTION\n")
+	for _, subscription := range subscriptions {
+		var filters []string
+		for _, filter := range subscription.EventFilters {
+			filters = append(filters, filter.Entity+":"+filter.Action)
+		}
+
+		fmt.Fprintf(table, "%d\t%t\t%t\t%s\t%s\t%s\n",
+			subscription.ID,
+			subscription.Enabled,
+			subscription.ValidatedAt != nil,
+			strings.Join(filters, ","),
+			subscription.URLCallback,
+			subscription.Description,
+		)
+	}
+
+	return table.Flush()
+}
+
+// createWebhook creates a subscription and prints its ID and secret.
+func createWebhook(env *environment, args []string) error {
+	flags := newFlagSet("webhooks create")
+	workspaceName := flags.String("workspace", "", "The name or ID of the workspace")
+	callbackURL := flags.String("url", "", "The URL the events are sent to")
+	description := flags.String("description", "", "The description of the subscription")
+	events := flags.String("events", "*:*", "Comma-separated entity:action filters (e.g. time_entry:*,project:created)")
+	secret := flags.String("secret", "", "The secret the events are signed with (default: generated by Toggl)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *callbackURL == "" || *description == "" {
+		return newUsageError("Please specify the callback URL with --url and a --description")
+	}
+
+	filters, filtersError := parseEventFilters(*events)
+	if filtersError != nil {
+		return filtersError
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	subscription, createError := env.webhooks.CreateWebhookSubscription(model.WebhookSubscription{
+		WorkspaceID:  workspace.ID,
+		Description:  *description,
+		URLCallback:  *callbackURL,
+		Secret:       *secret,
+		Enabled:      true,
+		EventFilters: filters,
+	})
+	if createError != nil {
+		return createError
+	}
+
+	fmt.Fprintf(env.stdout, "Created webhook subscription %d (secret: %s)\n", subscription.ID, subscription.Secret)
+	return nil
+}
+
+// changeWebhook pings or deletes the subscription with the given ID.
+func changeWebhook(env *environment, action string, args []string) error {
+	flags := newFlagSet("webhooks " + action)
+	workspaceName := flags.String("workspace", "", "The name or ID of the workspace")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return newUsageError("Usage: toggl webhooks %s [--workspace w] <id>", action)
+	}
+
+	id, parseError := parseID(flags.Arg(0))
+	if parseError != nil {
+		return parseError
+	}
+
+	workspace, workspaceError := resolveWorkspace(env, *workspaceName)
+	if workspaceError != nil {
+		return workspaceError
+	}
+
+	if action == "ping" {
+		if err := env.webhooks.PingWebhookSubscription(workspace.ID, id); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(env.stdout, "Requested a ping of webhook subscription %d\n", id)
+		return nil
+	}
+
+	if err := env.webhooks.DeleteWebhookSubscription(workspace.ID, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "Deleted webhook subscription %d\n", id)
+	return nil
+}
+
+// listenForWebhooks receives the events of a subscription and prints
+// one line per event until it is interrupted.
+func listenForWebhooks(env *environment, args []string) error {
+	flags := newFlagSet("webhooks listen")
+	listen := flags.String("listen", "127.0.0.1:9120", "The address of the HTTP endpoint")
+	path := flags.String("path", "/toggl", "The path of the HTTP endpoint")
+	secret := flags.String("secret", "", "The secret of the subscription")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *secret == "" {
+		return newUsageError("Please specify the secret of the subscription with --secret")
+	}
+
+	handler := webhook.NewHandler(*secret)
+	handler.OnPing(func(event webhook.Event) error {
+		fmt.Fprintf(env.stdout, "%s\tping\tsubscription %d\n", event.Timestamp.In(env.timeZone()).Format("2006-01-02 15:04:05"), event.SubscriptionID)
+		return nil
+	})
+	handler.OnEvent(func(event webhook.Event) error {
+		fmt.Fprintf(env.stdout, "%s\t%s %s\t%s\n", event.Timestamp.In(env.timeZone()).Format("2006-01-02 15:04:05"), event.Metadata.Model, event.Metadata.Action, event.Payload)
+		return nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(*path, handler)
+	server := &http.Server{Addr: *listen, Handler: mux}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		server.Close()
+	}()
+
+	fmt.Fprintf(env.stderr, "Receiving webhook events on http://%s%s (stop with Ctrl+C)\n", *listen, *path)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// parseEventFilters parses comma-separated entity:action pairs.
+func parseEventFilters(value string) ([]model.WebhookEventFilter, error) {
+	var filters []model.WebhookEventFilter
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, newUsageError("%q is not a valid event filter (e.g. time_entry:created or *:*)", pair)
+		}
+
+		filters = append(filters, model.WebhookEventFilter{Entity: parts[0], Action: parts[1]})
+	}
+
+	return filters, nil
+}
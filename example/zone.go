@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/togglapi"
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// cachedUserAPI fetches the Toggl profile of the user on first use and
+// returns it for all later calls.
+type cachedUserAPI struct {
+	model.UserAPI
+
+	once sync.Once
+	user model.User
+	err  error
+}
+
+// GetCurrentUser returns the user the API token belongs to.
+func (users *cachedUserAPI) GetCurrentUser() (model.User, error) {
+	users.once.Do(func() {
+		users.user, users.err = users.UserAPI.GetCurrentUser()
+	})
+
+	return users.user, users.err
+}
+
+// zoneResolver resolves the reporting time zone of a profile on first use,
+// so commands which do not need it (e.g. while offline) send no request.
+type zoneResolver struct {
+	profile profile
+	users   model.UserAPI
+
+	once     sync.Once
+	location *time.Location
+	err      error
+}
+
+// resolve returns the reporting time zone of the profile (nil: local time).
+func (resolver *zoneResolver) resolve() (*time.Location, error) {
+	resolver.once.Do(func() {
+		resolver.location, resolver.err = resolver.profile.location(resolver.users)
+	})
+
+	return resolver.location, resolver.err
+}
+
+// zonedTimeEntryAPI creates the time entry API in the reporting time zone
+// when the first time entry request is sent.
+type zonedTimeEntryAPI struct {
+	requester togglapi.RESTRequester
+	zone      *zoneResolver
+
+	once sync.Once
+	api  model.TimeEntryAPI
+	err  error
+}
+
+// timeEntries returns the time entry API in the reporting time zone.
+func (zoned *zonedTimeEntryAPI) timeEntries() (model.TimeEntryAPI, error) {
+	zoned.once.Do(func() {
+		location, err := zoned.zone.resolve()
+		zoned.api, zoned.err = togglapi.NewAPIWithRequester(zoned.requester, location), err
+	})
+
+	return zoned.api, zoned.err
+}
+
+// CreateTimeEntry creates a new time entry.
+func (zoned *zonedTimeEntryAPI) CreateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.CreateTimeEntry(timeEntry)
+}
+
+// GetTimeEntry returns the time entry with the given ID.
+func (zoned *zonedTimeEntryAPI) GetTimeEntry(id int) (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.GetTimeEntry(id)
+}
+
+// UpdateTimeEntry updates the time entry with the ID of the given time entry.
+func (zoned *zonedTimeEntryAPI) UpdateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.UpdateTimeEntry(timeEntry)
+}
+
+// PatchTimeEntry changes only the given fields of the time entry with the given ID.
+func (zoned *zonedTimeEntryAPI) PatchTimeEntry(id int, changes model.TimeEntryChanges) (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.PatchTimeEntry(id, changes)
+}
+
+// DeleteTimeEntry deletes the time entry with the given ID.
+func (zoned *zonedTimeEntryAPI) DeleteTimeEntry(id int) error {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return err
+	}
+
+	return api.DeleteTimeEntry(id)
+}
+
+// StartTimeEntry starts a new running time entry.
+func (zoned *zonedTimeEntryAPI) StartTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.StartTimeEntry(timeEntry)
+}
+
+// StopTimeEntry stops the running time entry with the given ID.
+func (zoned *zonedTimeEntryAPI) StopTimeEntry(id int) (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.StopTimeEntry(id)
+}
+
+// GetCurrentTimeEntry returns the currently running time entry.
+func (zoned *zonedTimeEntryAPI) GetCurrentTimeEntry() (model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	return api.GetCurrentTimeEntry()
+}
+
+// GetTimeEntries returns the time entries between the given start and end date.
+func (zoned *zonedTimeEntryAPI) GetTimeEntries(start, end time.Time) ([]model.TimeEntry, error) {
+	api, err := zoned.timeEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	return api.GetTimeEntries(start, end)
+}
@@ -0,0 +1,114 @@
+package format
+
+import (
+	"sort"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// column defines a named value of a Toggl entity.
+type column struct {
+	name  string
+	value func(item interface{}, lookup lookup) interface{}
+}
+
+// columnSet contains the available and the default columns of a Toggl entity.
+type columnSet struct {
+	columns  []column
+	defaults []string
+}
+
+// get returns the column with the given name.
+func (set columnSet) get(name string) (column, bool) {
+	for _, column := range set.columns {
+		if column.name == name {
+			return column, true
+		}
+	}
+
+	return column{}, false
+}
+
+// names returns the sorted names of all available columns.
+func (set columnSet) names() []string {
+	var names []string
+	for _, column := range set.columns {
+		names = append(names, column.name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// workspaceColumns contains the columns of model.Workspace.
+var workspaceColumns = columnSet{
+	columns: []column{
+		{"id", func(item interface{}, lookup lookup) interface{} { return item.(model.Workspace).ID }},
+		{"name", func(item interface{}, lookup lookup) interface{} { return item.(model.Workspace).Name }},
+	},
+	defaults: []string{"id", "name"},
+}
+
+// clientColumns contains the columns of model.Client.
+var clientColumns = columnSet{
+	columns: []column{
+		{"id", func(item interface{}, lookup lookup) interface{} { return item.(model.Client).ID }},
+		{"workspace_id", func(item interface{}, lookup lookup) interface{} { return item.(model.Client).WorkspaceID }},
+		{"name", func(item interface{}, lookup lookup) interface{} { return item.(model.Client).Name }},
+		{"notes", func(item interface{}, lookup lookup) interface{} { return item.(model.Client).Notes }},
+	},
+	defaults: []string{"id", "workspace_id", "name"},
+}
+
+// projectColumns contains the columns of model.Project.
+var projectColumns = columnSet{
+	columns: []column{
+		{"id", func(item interface{}, lookup lookup) interface{} { return item.(model.Project).ID }},
+		{"workspace_id", func(item interface{}, lookup lookup) interface{} { return item.(model.Project).WorkspaceID }},
+		{"client_id", func(item interface{}, lookup lookup) interface{} { return item.(model.Project).ClientID }},
+		{"client", func(item interface{}, lookup lookup) interface{} {
+			return lookup.clientName(item.(model.Project).ClientID)
+		}},
+		{"name", func(item interface{}, lookup lookup) interface{} { return item.(model.Project).Name }},
+	},
+	defaults: []string{"id", "client", "name"},
+}
+
+// timeEntryColumns contains the columns of model.TimeEntry.
+var timeEntryColumns = columnSet{
+	columns: []column{
+		{"id", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).ID }},
+		{"workspace_id", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Wid }},
+		{"project_id", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Pid }},
+		{"project", func(item interface{}, lookup lookup) interface{} {
+			return lookup.projectName(item.(model.TimeEntry).Pid)
+		}},
+		{"client", func(item interface{}, lookup lookup) interface{} {
+			return lookup.clientName(lookup.projects[item.(model.TimeEntry).Pid].ClientID)
+		}},
+		{"start", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Start }},
+		{"stop", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Stop }},
+		{"duration", func(item interface{}, lookup lookup) interface{} {
+			return getDuration(item.(model.TimeEntry), lookup.now)
+		}},
+		{"description", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Description }},
+		{"tags", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Tags }},
+		{"billable", func(item interface{}, lookup lookup) interface{} { return item.(model.TimeEntry).Billable }},
+	},
+	defaults: []string{"id", "start", "stop", "duration", "project", "description", "tags"},
+}
+
+// getDuration returns the tracked duration of the given time entry.
+// The duration of running time entries is measured until the given time.
+func getDuration(timeEntry model.TimeEntry, now time.Time) time.Duration {
+	if timeEntry.Duration < 0 {
+		return now.Sub(timeEntry.Start)
+	}
+
+	if timeEntry.Stop.IsZero() {
+		return time.Duration(timeEntry.Duration) * time.Second
+	}
+
+	return timeEntry.Stop.Sub(timeEntry.Start)
+}
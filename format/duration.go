@@ -0,0 +1,84 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DurationStyle defines how durations are rendered.
+type DurationStyle int
+
+const (
+	// Default selects the default style of the output format.
+	Default DurationStyle = iota
+
+	// Seconds renders durations as whole seconds (e.g. 5400).
+	Seconds
+
+	// Human renders durations as hours and minutes (e.g. "1h 30m").
+	Human
+
+	// Clock renders durations as hours and minutes (e.g. "1:30").
+	Clock
+
+	// Decimal renders durations as decimal hours (e.g. 1.50).
+	Decimal
+)
+
+// ParseDurationStyle returns the duration style with the given
+// name (seconds, human, clock or decimal).
+func ParseDurationStyle(name string) (DurationStyle, error) {
+	switch name {
+	case "":
+		return Default, nil
+	case "seconds":
+		return Seconds, nil
+	case "human":
+		return Human, nil
+	case "clock":
+		return Clock, nil
+	case "decimal":
+		return Decimal, nil
+	}
+
+	return Default, fmt.Errorf("Unknown duration style %q (available: seconds, human, clock, decimal)", name)
+}
+
+// Duration returns the given duration rendered in the given style.
+func Duration(duration time.Duration, style DurationStyle) string {
+	switch style {
+	case Human:
+		return humanDuration(duration)
+	case Clock:
+		minutes := int64(duration.Round(time.Minute) / time.Minute)
+		return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+	case Decimal:
+		return strconv.FormatFloat(duration.Hours(), 'f', 2, 64)
+	}
+
+	return strconv.FormatInt(int64(duration/time.Second), 10)
+}
+
+// humanDuration renders the given duration as hours and minutes (e.g. "1h 30m").
+// Durations below one minute are rendered in seconds.
+func humanDuration(duration time.Duration) string {
+	if duration < time.Minute && duration > -time.Minute {
+		return fmt.Sprintf("%ds", int64(duration/time.Second))
+	}
+
+	minutes := int64(duration.Round(time.Minute) / time.Minute)
+	if minutes < 60 && minutes > -60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+
+	if minutes < 0 {
+		return fmt.Sprintf("-%dh %dm", -minutes/60, -minutes%60)
+	}
+
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,248 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// tableDateLayout contains the layout of dates in tables.
+const tableDateLayout = "2006-01-02 15:04"
+
+// tableEncoder writes records as an aligned table.
+type tableEncoder struct{}
+
+func (tableEncoder) encode(w io.Writer, columns []string, records []record, options Options) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, strings.ToUpper(strings.Replace(column, "_", " ", -1)))
+	}
+
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+
+	for _, record := range records {
+		fields := make([]string, 0, len(record.values))
+		for _, value := range record.values {
+			fields = append(fields, strings.Replace(toString(value, tableDateLayout, options), "\t", " ", -1))
+		}
+
+		fmt.Fprintln(table, strings.Join(fields, "\t"))
+	}
+
+	return table.Flush()
+}
+
+// csvEncoder writes records as comma separated values with a header row.
+type csvEncoder struct{}
+
+func (csvEncoder) encode(w io.Writer, columns []string, records []record, options Options) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		fields := make([]string, 0, len(record.values))
+		for _, value := range record.values {
+			fields = append(fields, toString(value, time.RFC3339, options))
+		}
+
+		if err := writer.Write(fields); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// jsonEncoder writes records as a JSON array or as
+// JSON Lines (one object per line).
+type jsonEncoder struct {
+	lines bool
+}
+
+func (encoder jsonEncoder) encode(w io.Writer, columns []string, records []record, options Options) error {
+	var objects []json.RawMessage
+	for _, record := range records {
+		object, err := toJSONObject(columns, record.values, options)
+		if err != nil {
+			return err
+		}
+
+		if encoder.lines {
+			if _, err := fmt.Fprintf(w, "%s\n", object); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		objects = append(objects, object)
+	}
+
+	if encoder.lines {
+		return nil
+	}
+
+	if objects == nil {
+		objects = []json.RawMessage{}
+	}
+
+	content, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", content)
+	return err
+}
+
+// toJSONObject creates a JSON object with the given column names and values
+// which preserves the order of the columns.
+func toJSONObject(columns []string, values []interface{}, options Options) (json.RawMessage, error) {
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("{")
+
+	for index, column := range columns {
+		if index > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(toJSONValue(values[index], options))
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+
+	buffer.WriteString("}")
+	return json.RawMessage(buffer.Bytes()), nil
+}
+
+// toJSONValue converts the given value into a JSON compatible value.
+func toJSONValue(value interface{}, options Options) interface{} {
+	switch typedValue := value.(type) {
+	case time.Time:
+		if typedValue.IsZero() {
+			return nil
+		}
+
+		return typedValue.In(options.Location).Format(time.RFC3339)
+
+	case time.Duration:
+		switch options.DurationStyle {
+		case Seconds:
+			return int64(typedValue / time.Second)
+		case Decimal:
+			hours, _ := strconv.ParseFloat(Duration(typedValue, Decimal), 64)
+			return hours
+		}
+
+		return Duration(typedValue, options.DurationStyle)
+
+	case []string:
+		if typedValue == nil {
+			return []string{}
+		}
+	}
+
+	return value
+}
+
+// templateEncoder executes a Go template for each record.
+type templateEncoder struct {
+	template *template.Template
+}
+
+func (encoder templateEncoder) encode(w io.Writer, columns []string, records []record, options Options) error {
+	for _, record := range records {
+		if err := encoder.template.Execute(w, record.item); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateFunctions returns the helper functions available in templates.
+func templateFunctions(options Options) template.FuncMap {
+	lookup := newLookup(options)
+	return template.FuncMap{
+		"join": strings.Join,
+		"project": func(projectID int) string {
+			return lookup.projectName(projectID)
+		},
+		"client": func(clientID int) string {
+			return lookup.clientName(clientID)
+		},
+		"duration": func(value interface{}) (string, error) {
+			switch typedValue := value.(type) {
+			case model.TimeEntry:
+				return Duration(getDuration(typedValue, options.Now()), Human), nil
+			case time.Duration:
+				return Duration(typedValue, Human), nil
+			case int:
+				return Duration(time.Duration(typedValue)*time.Second, Human), nil
+			}
+
+			return "", fmt.Errorf("duration: unsupported value %v", value)
+		},
+		"date": func(layout string, value time.Time) string {
+			if value.IsZero() {
+				return ""
+			}
+
+			return value.In(options.Location).Format(layout)
+		},
+	}
+}
+
+// toString renders the given value as text using the given date layout.
+func toString(value interface{}, dateLayout string, options Options) string {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue
+
+	case int:
+		return strconv.Itoa(typedValue)
+
+	case bool:
+		return strconv.FormatBool(typedValue)
+
+	case []string:
+		return strings.Join(typedValue, ",")
+
+	case time.Time:
+		if typedValue.IsZero() {
+			return ""
+		}
+
+		return typedValue.In(options.Location).Format(dateLayout)
+
+	case time.Duration:
+		return Duration(typedValue, options.DurationStyle)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,254 @@
+// Package format renders lists of Toggl workspaces, clients, projects and
+// time entries as aligned tables, JSON, JSON Lines, CSV or Go templates.
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// The names of the supported output formats.
+const (
+	Table     = "table"
+	JSON      = "json"
+	JSONLines = "jsonl"
+	CSV       = "csv"
+	Template  = "template"
+)
+
+// A Formatter interface provides functions for writing lists of
+// Toggl entities in a specific output format.
+type Formatter interface {
+	// WriteWorkspaces writes the given workspaces to the given writer.
+	WriteWorkspaces(w io.Writer, workspaces []model.Workspace) error
+
+	// WriteClients writes the given clients to the given writer.
+	WriteClients(w io.Writer, clients []model.Client) error
+
+	// WriteProjects writes the given projects to the given writer.
+	WriteProjects(w io.Writer, projects []model.Project) error
+
+	// WriteTimeEntries writes the given time entries to the given writer.
+	WriteTimeEntries(w io.Writer, timeEntries []model.TimeEntry) error
+}
+
+// Options contains the settings of a Formatter.
+type Options struct {
+	// Columns contains the names of the columns to write (e.g. "id", "name").
+	// The default columns of each entity are used if no columns are given.
+	// Columns are ignored by the template format.
+	Columns []string
+
+	// Template contains the Go template which is executed
+	// for each item if the template format is used.
+	Template string
+
+	// DurationStyle defines how durations are rendered.
+	// Tables use the Human style, all other formats
+	// use the Seconds style by default.
+	DurationStyle DurationStyle
+
+	// Location is used for rendering dates (default: time.Local).
+	Location *time.Location
+
+	// Projects and Clients are used for resolving the project and
+	// client names of time entries and projects.
+	Projects []model.Project
+	Clients  []model.Client
+
+	// Now returns the current time which is used for calculating
+	// the duration of running time entries (default: time.Now).
+	Now func() time.Time
+}
+
+// Names returns the names of all supported output formats.
+func Names() []string {
+	return []string{Table, JSON, JSONLines, CSV, Template}
+}
+
+// New creates a new Formatter for the output format with the given name.
+// Returns an error if the format is unknown or the options are invalid.
+func New(name string, options Options) (Formatter, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	var encoder encoder
+	switch strings.ToLower(name) {
+	case Table, "":
+		encoder = &tableEncoder{}
+		if options.DurationStyle == Default {
+			options.DurationStyle = Human
+		}
+
+	case JSON:
+		encoder = &jsonEncoder{}
+
+	case JSONLines:
+		encoder = &jsonEncoder{lines: true}
+
+	case CSV:
+		encoder = &csvEncoder{}
+
+	case Template:
+		if options.Template == "" {
+			return nil, fmt.Errorf("The template format requires a template")
+		}
+
+		parsedTemplate, parseError := template.New("item").Funcs(templateFunctions(options)).Parse(options.Template)
+		if parseError != nil {
+			return nil, fmt.Errorf("Failed to parse the template: %s", parseError)
+		}
+
+		encoder = &templateEncoder{template: parsedTemplate}
+
+	default:
+		return nil, fmt.Errorf("Unknown output format %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	if options.DurationStyle == Default {
+		options.DurationStyle = Seconds
+	}
+
+	return &formatter{
+		options: options,
+		encoder: encoder,
+	}, nil
+}
+
+// encoder writes a set of records in a specific output format.
+type encoder interface {
+	encode(w io.Writer, columns []string, records []record, options Options) error
+}
+
+// record contains the column values of a single item.
+type record struct {
+	item   interface{}
+	values []interface{}
+}
+
+// formatter converts Toggl entities into records
+// and writes them using an encoder.
+type formatter struct {
+	options Options
+	encoder encoder
+}
+
+// WriteWorkspaces writes the given workspaces to the given writer.
+func (formatter *formatter) WriteWorkspaces(w io.Writer, workspaces []model.Workspace) error {
+	items := make([]interface{}, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		items = append(items, workspace)
+	}
+
+	return formatter.write(w, workspaceColumns, items)
+}
+
+// WriteClients writes the given clients to the given writer.
+func (formatter *formatter) WriteClients(w io.Writer, clients []model.Client) error {
+	items := make([]interface{}, 0, len(clients))
+	for _, client := range clients {
+		items = append(items, client)
+	}
+
+	return formatter.write(w, clientColumns, items)
+}
+
+// WriteProjects writes the given projects to the given writer.
+func (formatter *formatter) WriteProjects(w io.Writer, projects []model.Project) error {
+	items := make([]interface{}, 0, len(projects))
+	for _, project := range projects {
+		items = append(items, project)
+	}
+
+	return formatter.write(w, projectColumns, items)
+}
+
+// WriteTimeEntries writes the given time entries to the given writer.
+func (formatter *formatter) WriteTimeEntries(w io.Writer, timeEntries []model.TimeEntry) error {
+	items := make([]interface{}, 0, len(timeEntries))
+	for _, timeEntry := range timeEntries {
+		items = append(items, timeEntry)
+	}
+
+	return formatter.write(w, timeEntryColumns, items)
+}
+
+// write converts the given items into records using the
+// given column set and passes them to the encoder.
+func (formatter *formatter) write(w io.Writer, available columnSet, items []interface{}) error {
+	names := formatter.options.Columns
+	if len(names) == 0 {
+		names = available.defaults
+	}
+
+	var columns []column
+	for _, name := range names {
+		selectedColumn, exists := available.get(name)
+		if !exists {
+			return fmt.Errorf("Unknown column %q (available: %s)", name, strings.Join(available.names(), ", "))
+		}
+
+		columns = append(columns, selectedColumn)
+	}
+
+	lookup := newLookup(formatter.options)
+
+	records := make([]record, 0, len(items))
+	for _, item := range items {
+		values := make([]interface{}, 0, len(columns))
+		for _, selectedColumn := range columns {
+			values = append(values, selectedColumn.value(item, lookup))
+		}
+
+		records = append(records, record{item, values})
+	}
+
+	columnNames := make([]string, 0, len(columns))
+	for _, selectedColumn := range columns {
+		columnNames = append(columnNames, selectedColumn.name)
+	}
+
+	return formatter.encoder.encode(w, columnNames, records, formatter.options)
+}
+
+// lookup resolves project and client names.
+type lookup struct {
+	projects map[int]model.Project
+	clients  map[int]model.Client
+	now      time.Time
+}
+
+// newLookup creates a new lookup for the projects and clients of the given options.
+func newLookup(options Options) lookup {
+	projects := make(map[int]model.Project)
+	for _, project := range options.Projects {
+		projects[project.ID] = project
+	}
+
+	clients := make(map[int]model.Client)
+	for _, client := range options.Clients {
+		clients[client.ID] = client
+	}
+
+	return lookup{projects, clients, options.Now()}
+}
+
+// projectName returns the name of the project with the given ID.
+func (lookup lookup) projectName(projectID int) string {
+	return lookup.projects[projectID].Name
+}
+
+// clientName returns the name of the client with the given ID.
+func (lookup lookup) clientName(clientID int) string {
+	return lookup.clients[clientID].Name
+}
@@ -0,0 +1,245 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// testTimeEntries returns a list of time entries for testing.
+func testTimeEntries() []model.TimeEntry {
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	return []model.TimeEntry{
+		{
+			ID:          1,
+			Wid:         10,
+			Pid:         100,
+			Start:       start,
+			Stop:        start.Add(90 * time.Minute),
+			Duration:    5400,
+			Description: "Layout, colors",
+			Tags:        []string{"design", "web"},
+			Billable:    true,
+		},
+		{
+			ID:          2,
+			Wid:         10,
+			Start:       start.Add(2 * time.Hour),
+			Stop:        start.Add(2*time.Hour + 15*time.Minute),
+			Duration:    900,
+			Description: "Mails",
+		},
+	}
+}
+
+// testOptions returns formatter options with a fixed location and project list.
+func testOptions() Options {
+	return Options{
+		Location: time.UTC,
+		Projects: []model.Project{{ID: 100, WorkspaceID: 10, ClientID: 1000, Name: "Website"}},
+		Clients:  []model.Client{{ID: 1000, WorkspaceID: 10, Name: "Acme"}},
+	}
+}
+
+func Test_New_UnknownFormat_ErrorIsReturned(t *testing.T) {
+	// act
+	_, err := New("xml", Options{})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("New should return an error for unknown formats")
+	}
+}
+
+func Test_New_TemplateFormatWithoutTemplate_ErrorIsReturned(t *testing.T) {
+	// act
+	_, err := New(Template, Options{})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("New should return an error if the template format is used without a template")
+	}
+}
+
+func Test_WriteTimeEntries_Table_ColumnsAreAligned(t *testing.T) {
+	// arrange
+	options := testOptions()
+	options.Columns = []string{"id", "project", "duration"}
+	formatter, _ := New(Table, options)
+	output := &bytes.Buffer{}
+
+	// act
+	err := formatter.WriteTimeEntries(output, testTimeEntries())
+
+	// assert
+	expected := "ID  PROJECT  DURATION\n" +
+		"1   Website  1h 30m\n" +
+		"2            15m\n"
+
+	if err != nil || output.String() != expected {
+		t.Fail()
+		t.Logf("WriteTimeEntries should have written\n%s\nbut wrote\n%s\n(Error: %v)", expected, output.String(), err)
+	}
+}
+
+func Test_WriteTimeEntries_CSV_ValuesAreQuoted(t *testing.T) {
+	// arrange
+	options := testOptions()
+	options.Columns = []string{"id", "client", "description", "tags", "duration"}
+	formatter, _ := New(CSV, options)
+	output := &bytes.Buffer{}
+
+	// act
+	err := formatter.WriteTimeEntries(output, testTimeEntries())
+
+	// assert
+	expected := "id,client,description,tags,duration\n" +
+		"1,Acme,\"Layout, colors\",\"design,web\",5400\n" +
+		"2,,Mails,,900\n"
+
+	if err != nil || output.String() != expected {
+		t.Fail()
+		t.Logf("WriteTimeEntries should have written\n%s\nbut wrote\n%s\n(Error: %v)", expected, output.String(), err)
+	}
+}
+
+func Test_WriteTimeEntries_JSONLines_OneObjectPerLine(t *testing.T) {
+	// arrange
+	options := testOptions()
+	options.Columns = []string{"id", "start", "stop", "tags", "billable"}
+	options.DurationStyle = Decimal
+	formatter, _ := New(JSONLines, options)
+	output := &bytes.Buffer{}
+
+	// act
+	err := formatter.WriteTimeEntries(output, testTimeEntries())
+
+	// assert
+	expected := `{"id":1,"start":"2016-09-06T08:00:00Z","stop":"2016-09-06T09:30:00Z","tags":["design","web"],"billable":true}` + "\n" +
+		`{"id":2,"start":"2016-09-06T10:00:00Z","stop":"2016-09-06T10:15:00Z","tags":[],"billable":false}` + "\n"
+
+	if err != nil || output.String() != expected {
+		t.Fail()
+		t.Logf("WriteTimeEntries should have written\n%s\nbut wrote\n%s\n(Error: %v)", expected, output.String(), err)
+	}
+}
+
+func Test_WriteWorkspaces_JSON_ArrayIsWritten(t *testing.T) {
+	// arrange
+	formatter, _ := New(JSON, Options{})
+	output := &bytes.Buffer{}
+
+	// act
+	err := formatter.WriteWorkspaces(output, []model.Workspace{{ID: 1, Name: "Acme"}})
+
+	// assert
+	expected := "[\n  {\n    \"id\": 1,\n    \"name\": \"Acme\"\n  }\n]\n"
+	if err != nil || output.String() != expected {
+		t.Fail()
+		t.Logf("WriteWorkspaces should have written\n%s\nbut wrote\n%s\n(Error: %v)", expected, output.String(), err)
+	}
+}
+
+func Test_WriteProjects_JSON_NoProjects_EmptyArrayIsWritten(t *testing.T) {
+	// arrange
+	formatter, _ := New(JSON, Options{})
+	output := &bytes.Buffer{}
+
+	// act
+	formatter.WriteProjects(output, nil)
+
+	// assert
+	if strings.TrimSpace(output.String()) != "[]" {
+		t.Fail()
+		t.Logf("WriteProjects should have written an empty array but wrote %q", output.String())
+	}
+}
+
+func Test_WriteClients_UnknownColumn_ErrorIsReturned(t *testing.T) {
+	// arrange
+	formatter, _ := New(Table, Options{Columns: []string{"id", "color"}})
+
+	// act
+	err := formatter.WriteClients(&bytes.Buffer{}, []model.Client{{ID: 1}})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("WriteClients should return an error for unknown columns")
+	}
+}
+
+func Test_WriteTimeEntries_Template_TemplateIsExecutedForEachEntry(t *testing.T) {
+	// arrange
+	options := testOptions()
+	options.Template = `{{.ID}} {{project .Pid}} {{duration .}} {{date "15:04" .Start}} {{join .Tags "|"}}`
+	formatter, _ := New(Template, options)
+	output := &bytes.Buffer{}
+
+	// act
+	err := formatter.WriteTimeEntries(output, testTimeEntries())
+
+	// assert
+	expected := "1 Website 1h 30m 08:00 design|web\n" +
+		"2  15m 10:00 \n"
+
+	if err != nil || output.String() != expected {
+		t.Fail()
+		t.Logf("WriteTimeEntries should have written\n%s\nbut wrote\n%s\n(Error: %v)", expected, output.String(), err)
+	}
+}
+
+func Test_WriteTimeEntries_RunningEntry_DurationIsMeasuredUntilNow(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	options := Options{
+		Columns: []string{"duration"},
+		Now:     func() time.Time { return start.Add(45 * time.Minute) },
+	}
+
+	formatter, _ := New(CSV, options)
+	output := &bytes.Buffer{}
+
+	// act
+	formatter.WriteTimeEntries(output, []model.TimeEntry{{ID: 1, Start: start, Duration: -1473148800}})
+
+	// assert
+	if output.String() != "duration\n2700\n" {
+		t.Fail()
+		t.Logf("The duration of running entries should be measured until now but was %q", output.String())
+	}
+}
+
+func Test_Duration_AllStyles(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		Duration       time.Duration
+		Style          DurationStyle
+		ExpectedResult string
+	}{
+		{90 * time.Minute, Seconds, "5400"},
+		{90 * time.Minute, Human, "1h 30m"},
+		{2 * time.Hour, Human, "2h"},
+		{42 * time.Second, Human, "42s"},
+		{9 * time.Minute, Human, "9m"},
+		{65 * time.Minute, Clock, "1:05"},
+		{105 * time.Minute, Decimal, "1.75"},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result := Duration(input.Duration, input.Style)
+
+		// assert
+		if result != input.ExpectedResult {
+			t.Fail()
+			t.Logf("Duration(%s, %d) should have returned %q but returned %q", input.Duration, input.Style, input.ExpectedResult, result)
+		}
+	}
+}
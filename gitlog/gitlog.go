@@ -0,0 +1,301 @@
+// Package gitlog proposes time entries from the commit history of local
+// git repositories.
+//
+// The commits of each repository are clustered into work sessions: a
+// session ends when the next commit is more than the configured gap
+// away, and it starts the configured padding before its first commit.
+// Periods which are already covered by existing time entries are left out.
+package gitlog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// LogFormat contains the git log format which is parsed by ParseLog.
+const LogFormat = "%H%x09%at%x09%ae%x09%s"
+
+// The defaults of the session options.
+const (
+	DefaultGap     = 2 * time.Hour
+	DefaultPadding = 30 * time.Minute
+)
+
+// minimumDuration contains the length of the shortest proposed time entry.
+const minimumDuration = time.Minute
+
+// Commit contains the parsed values of a single commit.
+type Commit struct {
+	Repository string
+	Hash       string
+	Author     string
+	Time       time.Time
+	Subject    string
+}
+
+// Read returns the commits of the git repository at the given path
+// between the given start and end (merges are skipped). An empty author
+// selects the configured user.email of the repository.
+func Read(repository string, start, end time.Time, author string) ([]Commit, error) {
+	if author == "" {
+		output, err := exec.Command("git", "-C", repository, "config", "user.email").Output()
+		if err == nil {
+			author = strings.TrimSpace(string(output))
+		}
+	}
+
+	arguments := []string{
+		"-C", repository, "log", "--all", "--no-merges",
+		"--format=" + LogFormat,
+		"--since=" + start.Format(time.RFC3339),
+		"--until=" + end.Format(time.RFC3339),
+	}
+
+	if author != "" {
+		arguments = append(arguments, "--author="+author)
+	}
+
+	command := exec.Command("git", arguments...)
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+
+	output, err := command.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Failed to read the git log of %s: %s", repository, strings.TrimSpace(stderr.String())))
+	}
+
+	return ParseLog(bytes.NewReader(output), repository)
+}
+
+// ParseLog parses git log output in the LogFormat.
+func ParseLog(r io.Reader, repository string) ([]Commit, error) {
+	var commits []Commit
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("Line %d of the git log of %s has an unexpected format", lineNumber, repository)
+		}
+
+		seconds, parseError := strconv.ParseInt(fields[1], 10, 64)
+		if parseError != nil {
+			return nil, fmt.Errorf("Line %d of the git log of %s has an invalid time %q", lineNumber, repository, fields[1])
+		}
+
+		commits = append(commits, Commit{
+			Repository: repository,
+			Hash:       fields[0],
+			Time:       time.Unix(seconds, 0),
+			Author:     fields[2],
+			Subject:    fields[3],
+		})
+	}
+
+	return commits, scanner.Err()
+}
+
+// Options contains the settings for proposing time entries.
+type Options struct {
+	// Gap contains the longest pause between two commits of one session.
+	// Defaults to DefaultGap.
+	Gap time.Duration
+
+	// Padding contains the time added before the first commit of a
+	// session. Negative values select DefaultPadding.
+	Padding time.Duration
+
+	// WorkspaceID contains the workspace of the proposed time entries.
+	WorkspaceID int
+
+	// Projects maps repositories to project IDs.
+	Projects map[string]int
+
+	// Now is used as the end of running time entries. Defaults to the current time.
+	Now time.Time
+}
+
+// Proposal contains a proposed time entry and the commits it is based on.
+type Proposal struct {
+	Repository string
+	Commits    []Commit
+	TimeEntry  model.TimeEntry
+}
+
+// period contains a covered period.
+type period struct {
+	start time.Time
+	end   time.Time
+}
+
+// Propose clusters the given commits into sessions and returns time
+// entries for the parts of the sessions which are not covered by the
+// existing time entries or by earlier proposals. The proposals are
+// ordered by start.
+func Propose(commits []Commit, existing []model.TimeEntry, options Options) []Proposal {
+	if options.Gap <= 0 {
+		options.Gap = DefaultGap
+	}
+
+	if options.Padding < 0 {
+		options.Padding = DefaultPadding
+	}
+
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	var covered []period
+	for _, timeEntry := range existing {
+		end := timeEntry.Stop
+		if timeEntry.Duration < 0 || end.IsZero() {
+			end = options.Now
+		}
+
+		covered = append(covered, period{start: timeEntry.Start, end: end})
+	}
+
+	var proposals []Proposal
+	for _, session := range sessions(commits, options.Gap) {
+		sessionPeriod := period{start: session[0].Time.Add(-options.Padding), end: session[len(session)-1].Time}
+		for _, free := range subtract(sessionPeriod, covered) {
+			if free.end.Sub(free.start) < minimumDuration {
+				continue
+			}
+
+			covered = append(covered, free)
+			proposals = append(proposals, Proposal{
+				Repository: session[0].Repository,
+				Commits:    session,
+				TimeEntry: model.TimeEntry{
+					Wid:         options.WorkspaceID,
+					Pid:         options.Projects[session[0].Repository],
+					Start:       free.start,
+					Stop:        free.end,
+					Duration:    int(free.end.Sub(free.start).Seconds()),
+					Description: describe(session),
+				},
+			})
+		}
+	}
+
+	sort.SliceStable(proposals, func(i, j int) bool { return proposals[i].TimeEntry.Start.Before(proposals[j].TimeEntry.Start) })
+	return proposals
+}
+
+// Create creates the time entries of the given proposals.
+// Returns the time entries created before an error occurred.
+func Create(api model.TimeEntryAPI, proposals []Proposal) ([]model.TimeEntry, error) {
+	var created []model.TimeEntry
+	for _, proposal := range proposals {
+		timeEntry, err := api.CreateTimeEntry(proposal.TimeEntry)
+		if err != nil {
+			return created, errors.Wrap(err, fmt.Sprintf("Failed to create the time entry %q", proposal.TimeEntry.Description))
+		}
+
+		created = append(created, timeEntry)
+	}
+
+	return created, nil
+}
+
+// sessions returns the commits clustered into sessions per repository,
+// ordered by the time of the first commit.
+func sessions(commits []Commit, gap time.Duration) [][]Commit {
+	sorted := append([]Commit(nil), commits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var result [][]Commit
+	open := make(map[string]int)
+	for _, commit := range sorted {
+		index, isOpen := open[commit.Repository]
+		if isOpen {
+			session := result[index]
+			if commit.Time.Sub(session[len(session)-1].Time) <= gap {
+				result[index] = append(session, commit)
+				continue
+			}
+		}
+
+		open[commit.Repository] = len(result)
+		result = append(result, []Commit{commit})
+	}
+
+	return result
+}
+
+// subtract returns the parts of the given period which are not covered.
+func subtract(free period, covered []period) []period {
+	parts := []period{free}
+	for _, cover := range covered {
+		var remaining []period
+		for _, part := range parts {
+			if !cover.start.Before(part.end) || !cover.end.After(part.start) {
+				remaining = append(remaining, part)
+				continue
+			}
+
+			if cover.start.After(part.start) {
+				remaining = append(remaining, period{start: part.start, end: cover.start})
+			}
+
+			if cover.end.Before(part.end) {
+				remaining = append(remaining, period{start: cover.end, end: part.end})
+			}
+		}
+
+		parts = remaining
+	}
+
+	return parts
+}
+
+// describe returns the distinct commit subjects of the session in commit order.
+// Subjects which exceed the maximum description length are replaced by "…".
+func describe(session []Commit) string {
+	const separator, ellipsis = "; ", "…"
+	limit := model.MaxDescriptionLength - utf8.RuneCountInString(separator+ellipsis)
+
+	var subjects []string
+	length := 0
+	seen := make(map[string]bool)
+	for _, commit := range session {
+		if seen[commit.Subject] {
+			continue
+		}
+
+		seen[commit.Subject] = true
+		added := utf8.RuneCountInString(commit.Subject)
+		if len(subjects) > 0 {
+			added += utf8.RuneCountInString(separator)
+		}
+
+		if length+added > limit {
+			if len(subjects) == 0 {
+				return string([]rune(commit.Subject)[:limit]) + ellipsis
+			}
+
+			return strings.Join(subjects, separator) + separator + ellipsis
+		}
+
+		subjects = append(subjects, commit.Subject)
+		length += added
+	}
+
+	return strings.Join(subjects, separator)
+}
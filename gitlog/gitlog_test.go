@@ -0,0 +1,145 @@
+package gitlog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// at returns the given time on 2016-09-06 (UTC).
+func at(hour, minute int) time.Time {
+	return time.Date(2016, 9, 6, hour, minute, 0, 0, time.UTC)
+}
+
+// commit returns a commit of the given repository.
+func commit(repository string, time time.Time, subject string) Commit {
+	return Commit{Repository: repository, Hash: subject, Time: time, Subject: subject}
+}
+
+func Test_ParseLog(t *testing.T) {
+	// arrange
+	output := "3f1c\t1473148800\tjane@example.com\tFix the\tlayout\n\n9a2b\t1473152400\tjane@example.com\tAdd tests\n"
+
+	// act
+	commits, err := ParseLog(strings.NewReader(output), "website")
+
+	// assert
+	if err != nil || len(commits) != 2 || commits[0].Subject != "Fix the\tlayout" || !commits[0].Time.Equal(at(8, 0)) || commits[1].Repository != "website" {
+		t.Fail()
+		t.Logf("ParseLog returned %+v (Error: %v)", commits, err)
+	}
+
+	if _, err := ParseLog(strings.NewReader("3f1c\tyesterday\tjane@example.com\tFix\n"), "website"); err == nil {
+		t.Fail()
+		t.Logf("ParseLog should have rejected the invalid time")
+	}
+}
+
+func Test_Propose_CommitsAreClusteredIntoSessions(t *testing.T) {
+	// arrange
+	commits := []Commit{
+		commit("website", at(10, 0), "Add the header"),
+		commit("website", at(9, 0), "Fix the layout"),
+		commit("website", at(15, 0), "Add tests"),
+		commit("api", at(11, 0), "Add the endpoint"),
+	}
+
+	options := Options{Gap: 2 * time.Hour, Padding: 30 * time.Minute, WorkspaceID: 1, Projects: map[string]int{"website": 7}, Now: at(18, 0)}
+
+	// act
+	proposals := Propose(commits, nil, options)
+
+	// assert
+	if len(proposals) != 3 {
+		t.Fatalf("Propose should have returned three sessions: %+v", proposals)
+	}
+
+	first := proposals[0].TimeEntry
+	if !first.Start.Equal(at(8, 30)) || !first.Stop.Equal(at(10, 0)) || first.Pid != 7 || first.Wid != 1 || first.Description != "Fix the layout; Add the header" {
+		t.Fail()
+		t.Logf("The first session is wrong: %+v", first)
+	}
+
+	if api := proposals[1].TimeEntry; !api.Start.Equal(at(10, 30)) || !api.Stop.Equal(at(11, 0)) || api.Pid != 0 {
+		t.Fail()
+		t.Logf("The session of the api should have started after the first session: %+v", api)
+	}
+}
+
+func Test_Propose_CoveredPeriodsAreSkipped(t *testing.T) {
+	// arrange
+	commits := []Commit{
+		commit("website", at(10, 0), "Fix the layout"),
+		commit("website", at(12, 0), "Add tests"),
+	}
+
+	existing := []model.TimeEntry{
+		{Start: at(9, 0), Stop: at(11, 0), Duration: 7200},
+		{Start: at(11, 30), Duration: -int(at(11, 30).Unix())},
+	}
+
+	// act
+	proposals := Propose(commits, existing, Options{Padding: time.Hour, Now: at(11, 45)})
+
+	// assert
+	if len(proposals) != 2 || !proposals[0].TimeEntry.Start.Equal(at(11, 0)) || !proposals[0].TimeEntry.Stop.Equal(at(11, 30)) || !proposals[1].TimeEntry.Start.Equal(at(11, 45)) {
+		t.Fail()
+		t.Logf("Propose should only have proposed the uncovered periods: %+v", proposals)
+	}
+}
+
+func Test_Propose_ZeroPadding_SessionStartsAtFirstCommit(t *testing.T) {
+	// arrange
+	commits := []Commit{commit("website", at(9, 0), "Fix the layout"), commit("website", at(10, 0), "Add the header")}
+
+	// act
+	proposals := Propose(commits, nil, Options{Padding: 0, Now: at(18, 0)})
+
+	// assert
+	if len(proposals) != 1 || !proposals[0].TimeEntry.Start.Equal(at(9, 0)) {
+		t.Fail()
+		t.Logf("The session should have started at the first commit: %+v", proposals)
+	}
+}
+
+func Test_Propose_ManySubjects_DescriptionIsTruncated(t *testing.T) {
+	// arrange
+	var commits []Commit
+	for minute := 0; minute < 120; minute++ {
+		commits = append(commits, commit("website", at(9, 0).Add(time.Duration(minute)*time.Minute), fmt.Sprintf("Change the layout of the page, part %d", minute)))
+	}
+
+	// act
+	proposals := Propose(commits, nil, Options{Now: at(18, 0)})
+
+	// assert
+	if len(proposals) != 1 {
+		t.Fatalf("Propose should have returned one session: %+v", proposals)
+	}
+
+	description := proposals[0].TimeEntry.Description
+	if length := utf8.RuneCountInString(description); length > model.MaxDescriptionLength || !strings.HasSuffix(description, "; …") {
+		t.Fail()
+		t.Logf("The description should have been truncated (Length: %d, Description: %q)", length, description)
+	}
+}
+
+func Test_Create_ProposedTimeEntriesAreCreated(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	proposals := Propose([]Commit{commit("website", at(10, 0), "Fix the layout")}, nil, Options{WorkspaceID: 1, Padding: -1})
+
+	// act
+	created, err := Create(api, proposals)
+
+	// assert
+	if err != nil || len(created) != 1 || len(api.TimeEntries) != 1 || api.TimeEntries[0].Duration != 1800 {
+		t.Fail()
+		t.Logf("Create should have created the proposed time entry (Created: %+v, Error: %v)", created, err)
+	}
+}
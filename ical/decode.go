@@ -0,0 +1,308 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// DecodeOptions contains the settings for creating time entries from events.
+type DecodeOptions struct {
+	// WorkspaceID contains the workspace of the created time entries.
+	WorkspaceID int
+
+	// ProjectID contains the project of time entries whose events
+	// have no Toggl project ID (optional).
+	ProjectID int
+
+	// Location is used for floating date-times without
+	// time zone (default: time.Local).
+	Location *time.Location
+}
+
+// property contains a parsed content line.
+type property struct {
+	name       string
+	parameters map[string]string
+	value      string
+}
+
+// Decode reads the events of the given iCalendar file and converts them
+// into time entries. All-day events and events without an end are skipped.
+func Decode(r io.Reader, options DecodeOptions) ([]model.TimeEntry, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	events, readError := readEvents(r)
+	if readError != nil {
+		return nil, readError
+	}
+
+	var timeEntries []model.TimeEntry
+	for _, event := range events {
+		timeEntry, isTimedEvent, eventError := toTimeEntry(event, options)
+		if eventError != nil {
+			return nil, eventError
+		}
+
+		if isTimedEvent {
+			timeEntries = append(timeEntries, timeEntry)
+		}
+	}
+
+	return timeEntries, nil
+}
+
+// readEvents returns the properties of all events of the given iCalendar file.
+func readEvents(r io.Reader) ([][]property, error) {
+	lines, readError := readLines(r)
+	if readError != nil {
+		return nil, errors.Wrap(readError, "Failed to read the calendar")
+	}
+
+	var events [][]property
+	var event []property
+	inEvent := false
+
+	for index, line := range lines {
+		contentLine, parseError := parseLine(line)
+		if parseError != nil {
+			return nil, errors.Wrap(parseError, fmt.Sprintf("Failed to parse content line %d", index+1))
+		}
+
+		switch {
+		case contentLine.name == "BEGIN" && strings.EqualFold(contentLine.value, "VEVENT"):
+			inEvent = true
+			event = nil
+
+		case contentLine.name == "END" && strings.EqualFold(contentLine.value, "VEVENT"):
+			inEvent = false
+			events = append(events, event)
+
+		case inEvent:
+			event = append(event, contentLine)
+		}
+	}
+
+	return events, nil
+}
+
+// Import creates a time entry for each timed event of the given
+// iCalendar file. Returns the created time entries.
+func Import(api model.TimeEntryAPI, r io.Reader, options DecodeOptions) ([]model.TimeEntry, error) {
+	timeEntries, decodeError := Decode(r, options)
+	if decodeError != nil {
+		return nil, decodeError
+	}
+
+	var createdTimeEntries []model.TimeEntry
+	for _, timeEntry := range timeEntries {
+		createdTimeEntry, createError := api.CreateTimeEntry(timeEntry)
+		if createError != nil {
+			return createdTimeEntries, errors.Wrap(createError, fmt.Sprintf("Failed to create the time entry for %q", timeEntry.Description))
+		}
+
+		createdTimeEntries = append(createdTimeEntries, createdTimeEntry)
+	}
+
+	return createdTimeEntries, nil
+}
+
+// toTimeEntry converts the given event properties into a time entry.
+// Returns false if the event is an all-day event or has no end.
+func toTimeEntry(event []property, options DecodeOptions) (model.TimeEntry, bool, error) {
+	timeEntry := model.TimeEntry{
+		Wid: options.WorkspaceID,
+		Pid: options.ProjectID,
+	}
+
+	var summary, description string
+	var duration time.Duration
+	hasStart, hasStop := false, false
+
+	for _, eventProperty := range event {
+		switch eventProperty.name {
+		case "DTSTART", "DTEND":
+			if strings.EqualFold(eventProperty.parameters["VALUE"], "DATE") || len(eventProperty.value) == len(dateLayout) {
+				return model.TimeEntry{}, false, nil
+			}
+
+			value, parseError := parseDateTime(eventProperty, options.Location)
+			if parseError != nil {
+				return model.TimeEntry{}, false, parseError
+			}
+
+			if eventProperty.name == "DTSTART" {
+				timeEntry.Start, hasStart = value, true
+			} else {
+				timeEntry.Stop, hasStop = value, true
+			}
+
+		case "DURATION":
+			parsedDuration, parseError := parseDuration(eventProperty.value)
+			if parseError != nil {
+				return model.TimeEntry{}, false, parseError
+			}
+
+			duration = parsedDuration
+
+		case "SUMMARY":
+			summary = unescapeText(eventProperty.value)
+
+		case "DESCRIPTION":
+			description = unescapeText(eventProperty.value)
+
+		case "CATEGORIES":
+			for _, category := range splitList(eventProperty.value) {
+				if category = strings.TrimSpace(category); category != "" {
+					timeEntry.Tags = append(timeEntry.Tags, category)
+				}
+			}
+
+		case projectIDProperty:
+			if projectID, err := strconv.Atoi(eventProperty.value); err == nil {
+				timeEntry.Pid = projectID
+			}
+
+		case billableProperty:
+			timeEntry.Billable = strings.EqualFold(eventProperty.value, "TRUE")
+		}
+	}
+
+	if !hasStart {
+		return model.TimeEntry{}, false, nil
+	}
+
+	if !hasStop {
+		if duration <= 0 {
+			return model.TimeEntry{}, false, nil
+		}
+
+		timeEntry.Stop = timeEntry.Start.Add(duration)
+	}
+
+	// events exported by this package carry the plain
+	// description in addition to the decorated summary
+	timeEntry.Description = summary
+	if description != "" {
+		timeEntry.Description = description
+	}
+
+	timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+	return timeEntry, true, nil
+}
+
+// parseDateTime parses the date-time value of the given property.
+func parseDateTime(dateTimeProperty property, location *time.Location) (time.Time, error) {
+	value := dateTimeProperty.value
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(utcLayout, value)
+	}
+
+	if timeZone := dateTimeProperty.parameters["TZID"]; timeZone != "" {
+		timeZoneLocation, loadError := time.LoadLocation(strings.Trim(timeZone, `"`))
+		if loadError != nil {
+			return time.Time{}, errors.Wrap(loadError, fmt.Sprintf("Unknown time zone %q", timeZone))
+		}
+
+		location = timeZoneLocation
+	}
+
+	return time.ParseInLocation(localLayout, value, location)
+}
+
+// durationPattern matches RFC 5545 durations (e.g. PT1H30M or P1DT2H).
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses the given RFC 5545 duration.
+func parseDuration(value string) (time.Duration, error) {
+	matches := durationPattern.FindStringSubmatch(value)
+	if matches == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("Invalid duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var duration time.Duration
+	for index, unit := range units {
+		if matches[index+2] == "" {
+			continue
+		}
+
+		amount, _ := strconv.Atoi(matches[index+2])
+		duration += time.Duration(amount) * unit
+	}
+
+	if matches[1] == "-" {
+		duration = -duration
+	}
+
+	return duration, nil
+}
+
+// readLines reads the unfolded content lines of the given iCalendar file.
+func readLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// continuation lines start with a space or a tab
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseLine splits the given content line into name, parameters and value.
+func parseLine(line string) (property, error) {
+	valueIndex := -1
+	quoted := false
+	for index, character := range line {
+		if character == '"' {
+			quoted = !quoted
+		}
+
+		if character == ':' && !quoted {
+			valueIndex = index
+			break
+		}
+	}
+
+	if valueIndex < 0 {
+		return property{}, fmt.Errorf("Missing value in %q", line)
+	}
+
+	nameAndParameters := strings.Split(line[:valueIndex], ";")
+	contentLine := property{
+		name:       strings.ToUpper(nameAndParameters[0]),
+		parameters: make(map[string]string),
+		value:      line[valueIndex+1:],
+	}
+
+	for _, parameter := range nameAndParameters[1:] {
+		parts := strings.SplitN(parameter, "=", 2)
+		if len(parts) == 2 {
+			contentLine.parameters[strings.ToUpper(parts[0])] = parts[1]
+		}
+	}
+
+	return contentLine, nil
+}
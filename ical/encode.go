@@ -0,0 +1,189 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// productID identifies the application which created the calendar.
+const productID = "-//andreaskoch//togglapi//EN"
+
+// EncodeOptions contains the settings for encoding time entries.
+type EncodeOptions struct {
+	// Name contains the name of the calendar (optional).
+	Name string
+
+	// Domain is used for creating globally unique event IDs (default: "toggl.com").
+	Domain string
+
+	// Projects and Clients are used for adding the
+	// project and client names to the event summaries.
+	Projects []model.Project
+	Clients  []model.Client
+
+	// Now returns the creation time of the calendar (default: time.Now).
+	Now func() time.Time
+}
+
+// UID returns the stable event ID of the time entry with the given ID.
+func UID(timeEntryID int, domain string) string {
+	return fmt.Sprintf("time-entry-%d@%s", timeEntryID, domain)
+}
+
+// Encode writes the given time entries as an iCalendar file with one
+// event per time entry to the given writer. Running time entries are skipped.
+func Encode(w io.Writer, timeEntries []model.TimeEntry, options EncodeOptions) error {
+	if options.Domain == "" {
+		options.Domain = "toggl.com"
+	}
+
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	projects := make(map[int]model.Project)
+	for _, project := range options.Projects {
+		projects[project.ID] = project
+	}
+
+	clients := make(map[int]model.Client)
+	for _, client := range options.Clients {
+		clients[client.ID] = client
+	}
+
+	writer := &lineWriter{writer: bufio.NewWriter(w)}
+	writer.property("BEGIN", "VCALENDAR")
+	writer.property("VERSION", "2.0")
+	writer.property("PRODID", productID)
+	writer.property("CALSCALE", "GREGORIAN")
+	if options.Name != "" {
+		writer.property("X-WR-CALNAME", escapeText(options.Name))
+	}
+
+	timestamp := formatUTC(options.Now())
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() {
+			continue
+		}
+
+		project := projects[timeEntry.Pid]
+		client := clients[project.ClientID]
+
+		writer.property("BEGIN", "VEVENT")
+		writer.property("UID", UID(timeEntry.ID, options.Domain))
+		writer.property("DTSTAMP", timestamp)
+		writer.property("DTSTART", formatUTC(timeEntry.Start))
+		writer.property("DTEND", formatUTC(timeEntry.Stop))
+		writer.property("SUMMARY", escapeText(getSummary(timeEntry, project, client)))
+
+		if len(timeEntry.Tags) > 0 {
+			var categories []string
+			for _, tag := range timeEntry.Tags {
+				categories = append(categories, escapeText(tag))
+			}
+
+			writer.property("CATEGORIES", strings.Join(categories, ","))
+		}
+
+		if timeEntry.Description != "" {
+			writer.property("DESCRIPTION", escapeText(timeEntry.Description))
+		}
+
+		if timeEntry.Pid != 0 {
+			writer.property(projectIDProperty, strconv.Itoa(timeEntry.Pid))
+		}
+
+		if timeEntry.Billable {
+			writer.property(billableProperty, "TRUE")
+		}
+
+		writer.property("END", "VEVENT")
+	}
+
+	writer.property("END", "VCALENDAR")
+	return writer.flush()
+}
+
+// The names of the non-standard properties containing Toggl specific values.
+const (
+	projectIDProperty = "X-TOGGL-PROJECT-ID"
+	billableProperty  = "X-TOGGL-BILLABLE"
+)
+
+// getSummary returns the event summary for the given time entry
+// (e.g. "Layout (Website, Acme)").
+func getSummary(timeEntry model.TimeEntry, project model.Project, client model.Client) string {
+	var context []string
+	if project.Name != "" {
+		context = append(context, project.Name)
+	}
+
+	if client.Name != "" {
+		context = append(context, client.Name)
+	}
+
+	description := timeEntry.Description
+	if description == "" {
+		description = "(no description)"
+	}
+
+	if len(context) == 0 {
+		return description
+	}
+
+	return fmt.Sprintf("%s (%s)", description, strings.Join(context, ", "))
+}
+
+// lineWriter writes folded content lines terminated by CRLF.
+type lineWriter struct {
+	writer *bufio.Writer
+	err    error
+}
+
+// property writes a content line with the given name and (already escaped) value.
+func (writer *lineWriter) property(name, value string) {
+	if writer.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+
+	// fold lines longer than 75 octets without splitting UTF-8 characters;
+	// continuation lines start with a space which counts towards the limit
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isCharacterStart(line[cut]) {
+			cut--
+		}
+
+		if _, writer.err = writer.writer.WriteString(line[:cut] + "\r\n "); writer.err != nil {
+			return
+		}
+
+		line = line[cut:]
+		limit = maxLineLength - 1
+	}
+
+	_, writer.err = writer.writer.WriteString(line + "\r\n")
+}
+
+// flush writes the buffered lines and returns the first write error.
+func (writer *lineWriter) flush() error {
+	if writer.err != nil {
+		return writer.err
+	}
+
+	return writer.writer.Flush()
+}
+
+// isCharacterStart returns true if the given byte is the first byte of an UTF-8 character.
+func isCharacterStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
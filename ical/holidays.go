@@ -0,0 +1,71 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Holiday contains a single day of an all-day event.
+type Holiday struct {
+	// Date contains the midnight (UTC) of the day.
+	Date time.Time
+
+	Name string
+}
+
+// DecodeHolidays reads the all-day events of the given iCalendar file
+// (e.g. a public holiday calendar) and returns one holiday per day.
+// Timed events are skipped.
+func DecodeHolidays(r io.Reader) ([]Holiday, error) {
+	events, readError := readEvents(r)
+	if readError != nil {
+		return nil, readError
+	}
+
+	var holidays []Holiday
+	for _, event := range events {
+		var name string
+		var start, end time.Time
+		for _, eventProperty := range event {
+			switch eventProperty.name {
+			case "DTSTART", "DTEND":
+				if !strings.EqualFold(eventProperty.parameters["VALUE"], "DATE") && len(eventProperty.value) != len(dateLayout) {
+					continue
+				}
+
+				date, parseError := time.Parse(dateLayout, eventProperty.value)
+				if parseError != nil {
+					return nil, errors.Wrap(parseError, fmt.Sprintf("Invalid date %q", eventProperty.value))
+				}
+
+				if eventProperty.name == "DTSTART" {
+					start = date
+				} else {
+					end = date
+				}
+
+			case "SUMMARY":
+				name = unescapeText(eventProperty.value)
+			}
+		}
+
+		if start.IsZero() {
+			continue
+		}
+
+		// the end date of all-day events is exclusive
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			holidays = append(holidays, Holiday{Date: day, Name: name})
+		}
+	}
+
+	return holidays, nil
+}
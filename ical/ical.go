@@ -0,0 +1,77 @@
+// Package ical converts Toggl time entries into iCalendar (RFC 5545)
+// files and creates time entries from the events of iCalendar files.
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+// utcLayout contains the layout of UTC date-times (e.g. 20160906T080000Z).
+const utcLayout = "20060102T150405Z"
+
+// localLayout contains the layout of floating and TZID date-times (e.g. 20160906T080000).
+const localLayout = "20060102T150405"
+
+// dateLayout contains the layout of dates (e.g. 20160906).
+const dateLayout = "20060102"
+
+// maxLineLength contains the maximum length of a content line in octets.
+const maxLineLength = 75
+
+// escapeText escapes the given value for TEXT properties.
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+
+	return replacer.Replace(value)
+}
+
+// unescapeText reverts the escaping of TEXT property values.
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	)
+
+	return replacer.Replace(value)
+}
+
+// splitList splits the given list of TEXT values at unescaped commas.
+func splitList(value string) []string {
+	var values []string
+	var current strings.Builder
+
+	escaped := false
+	for _, character := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(character)
+			escaped = false
+		case character == '\\':
+			escaped = true
+		case character == ',':
+			values = append(values, unescapeText(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(character)
+		}
+	}
+
+	values = append(values, unescapeText(current.String()))
+	return values
+}
+
+// formatUTC formats the given time as an UTC date-time.
+func formatUTC(value time.Time) string {
+	return value.UTC().Format(utcLayout)
+}
@@ -0,0 +1,203 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+func Test_Encode_OneEventPerTimeEntry(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	timeEntries := []model.TimeEntry{
+		{ID: 1, Pid: 100, Start: start, Stop: start.Add(time.Hour), Duration: 3600, Description: "Layout; colors", Tags: []string{"design", "a,b"}, Billable: true},
+		{ID: 2, Start: start.Add(2 * time.Hour), Duration: -1473148800, Description: "Running"},
+	}
+
+	options := EncodeOptions{
+		Domain:   "example.com",
+		Projects: []model.Project{{ID: 100, ClientID: 10, Name: "Website"}},
+		Clients:  []model.Client{{ID: 10, Name: "Acme"}},
+		Now:      func() time.Time { return start },
+	}
+
+	output := &bytes.Buffer{}
+
+	// act
+	err := Encode(output, timeEntries, options)
+
+	// assert
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//andreaskoch//togglapi//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:time-entry-1@example.com\r\n" +
+		"DTSTAMP:20160906T080000Z\r\n" +
+		"DTSTART:20160906T080000Z\r\n" +
+		"DTEND:20160906T090000Z\r\n" +
+		"SUMMARY:Layout\\; colors (Website\\, Acme)\r\n" +
+		"CATEGORIES:design,a\\,b\r\n" +
+		"DESCRIPTION:Layout\\; colors\r\n" +
+		"X-TOGGL-PROJECT-ID:100\r\n" +
+		"X-TOGGL-BILLABLE:TRUE\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	if err != nil || output.String() != expected {
+		t.Fail()
+		t.Logf("Encode should have written\n%q\nbut wrote\n%q\n(Error: %v)", expected, output.String(), err)
+	}
+}
+
+func Test_Encode_LongLinesAreFolded(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	description := strings.Repeat("äbc ", 40)
+	timeEntries := []model.TimeEntry{{ID: 1, Start: start, Stop: start.Add(time.Hour), Description: description}}
+	output := &bytes.Buffer{}
+
+	// act
+	Encode(output, timeEntries, EncodeOptions{})
+
+	// assert
+	for _, line := range strings.Split(output.String(), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Fail()
+			t.Logf("The line %q is longer than %d octets", line, maxLineLength)
+		}
+	}
+
+	decoded, err := Decode(output, DecodeOptions{})
+	if err != nil || len(decoded) != 1 || decoded[0].Description != description {
+		t.Fail()
+		t.Logf("The folded description should have been decoded (Error: %v)", err)
+	}
+}
+
+func Test_Decode_EncodedTimeEntries_TimeEntriesAreRestored(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	original := model.TimeEntry{ID: 1, Pid: 100, Start: start, Stop: start.Add(90 * time.Minute), Description: "Review, part 2", Tags: []string{"x,y", "z"}, Billable: true}
+	calendar := &bytes.Buffer{}
+	Encode(calendar, []model.TimeEntry{original}, EncodeOptions{Projects: []model.Project{{ID: 100, Name: "Website"}}})
+
+	// act
+	timeEntries, err := Decode(calendar, DecodeOptions{WorkspaceID: 5})
+
+	// assert
+	if err != nil || len(timeEntries) != 1 {
+		t.Fatalf("Decode should have returned one time entry (Error: %v)", err)
+	}
+
+	decoded := timeEntries[0]
+	if decoded.Wid != 5 || decoded.Pid != 100 || !decoded.Start.Equal(original.Start) || !decoded.Stop.Equal(original.Stop) ||
+		decoded.Description != original.Description || strings.Join(decoded.Tags, "|") != "x,y|z" || !decoded.Billable || decoded.Duration != 5400 {
+		t.Fail()
+		t.Logf("Decode returned %#v", decoded)
+	}
+}
+
+func Test_Decode_ForeignCalendar_TimedEventsAreConverted(t *testing.T) {
+	// arrange
+	calendar := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;TZID=Europe/Berlin:20160906T100000\n" +
+		"DURATION:PT1H30M\n" +
+		"SUMMARY:Standup\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20160907\n" +
+		"SUMMARY:Holiday\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART:20160908T090000\n" +
+		"DTEND:20160908T100000\n" +
+		"SUMMARY:Floating\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	// act
+	timeEntries, err := Decode(strings.NewReader(calendar), DecodeOptions{ProjectID: 7, Location: time.UTC})
+
+	// assert
+	if err != nil || len(timeEntries) != 2 {
+		t.Fatalf("Decode should have returned two time entries but returned %d (Error: %v)", len(timeEntries), err)
+	}
+
+	if timeEntries[0].Start.UTC().Hour() != 8 || timeEntries[0].Duration != 5400 || timeEntries[0].Pid != 7 {
+		t.Fail()
+		t.Logf("The TZID event was not converted correctly: %#v", timeEntries[0])
+	}
+
+	if timeEntries[1].Start.UTC().Hour() != 9 || timeEntries[1].Description != "Floating" {
+		t.Fail()
+		t.Logf("The floating event was not converted correctly: %#v", timeEntries[1])
+	}
+}
+
+func Test_Decode_InvalidDate_ErrorIsReturned(t *testing.T) {
+	// arrange
+	calendar := "BEGIN:VEVENT\nDTSTART:2016-09-06\nDTEND:20160906T100000Z\nEND:VEVENT\n"
+
+	// act
+	_, err := Decode(strings.NewReader(calendar), DecodeOptions{})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("Decode should return an error for invalid dates")
+	}
+}
+
+func Test_Import_TimeEntriesAreCreated(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	calendar := "BEGIN:VEVENT\r\nDTSTART:20160906T080000Z\r\nDTEND:20160906T090000Z\r\nSUMMARY:Call\r\nEND:VEVENT\r\n"
+
+	// act
+	created, err := Import(api, strings.NewReader(calendar), DecodeOptions{WorkspaceID: 1})
+
+	// assert
+	if err != nil || len(created) != 1 || len(api.TimeEntries) != 1 || api.TimeEntries[0].Description != "Call" {
+		t.Fail()
+		t.Logf("Import should have created one time entry (Error: %v)", err)
+	}
+}
+
+func Test_DecodeHolidays_AllDayEventsAreReturnedPerDay(t *testing.T) {
+	// arrange
+	calendar := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20161224\n" +
+		"DTEND;VALUE=DATE:20161227\n" +
+		"SUMMARY:Christmas\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20161003\n" +
+		"SUMMARY:Day of German Unity\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART:20161004T090000Z\n" +
+		"DTEND:20161004T100000Z\n" +
+		"SUMMARY:Meeting\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	// act
+	holidays, err := DecodeHolidays(strings.NewReader(calendar))
+
+	// assert
+	if err != nil || len(holidays) != 4 {
+		t.Fatalf("DecodeHolidays should have returned four days but returned %v (Error: %v)", holidays, err)
+	}
+
+	if holidays[2].Date != time.Date(2016, 12, 26, 0, 0, 0, 0, time.UTC) || holidays[3].Name != "Day of German Unity" {
+		t.Fail()
+		t.Logf("DecodeHolidays returned %v", holidays)
+	}
+}
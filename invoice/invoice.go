@@ -0,0 +1,329 @@
+// Package invoice creates invoices from the billable time entries of a
+// client and renders them as HTML, Markdown or plain text.
+//
+// The amounts are calculated with the billing package. Invoiced time
+// entries can be tagged (e.g. "invoiced-2016-09") so that they are
+// skipped by later invoices.
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/billing"
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// TagPrefix contains the prefix of the tags of invoiced time entries.
+const TagPrefix = "invoiced-"
+
+// Tax defines a tax which is added to the subtotal.
+type Tax struct {
+	Name    string
+	Percent float64
+}
+
+// ParseTax parses a tax like "VAT=19" or "VAT=7.5".
+func ParseTax(value string) (Tax, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return Tax{}, fmt.Errorf("%q is not a valid tax (e.g. VAT=19)", value)
+	}
+
+	var percent float64
+	if _, err := fmt.Sscanf(strings.TrimSpace(parts[1]), "%g", &percent); err != nil || percent < 0 {
+		return Tax{}, fmt.Errorf("%q is not a valid tax percentage", parts[1])
+	}
+
+	return Tax{Name: strings.TrimSpace(parts[0]), Percent: percent}, nil
+}
+
+// Options contains the settings of an invoice.
+type Options struct {
+	// Number contains the invoice number.
+	Number string
+
+	// WorkspaceID and ClientID select the billed client.
+	WorkspaceID int
+	ClientID    int
+
+	// Start and End define the billed period.
+	Start time.Time
+	End   time.Time
+
+	// Issuer contains the name and address of the issuer (one line per entry).
+	Issuer []string
+
+	// Taxes contains the taxes which are added to the subtotal.
+	Taxes []Tax
+
+	// Billing contains the rates and rounding rules.
+	// The projects are filled in automatically.
+	Billing billing.Options
+
+	// Now returns the invoice date. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Line contains a single line item of an invoice.
+type Line struct {
+	Date        time.Time
+	Project     string
+	Description string
+	Billed      time.Duration
+	Rate        int64
+	Amount      int64
+}
+
+// Hours returns the billed hours of the line.
+func (line Line) Hours() float64 {
+	return line.Billed.Hours()
+}
+
+// TaxLine contains the amount of a tax.
+type TaxLine struct {
+	Name    string
+	Percent float64
+	Amount  int64
+}
+
+// Invoice contains the line items and totals of an invoice.
+// All amounts are in minor currency units (e.g. cents).
+type Invoice struct {
+	Number   string
+	Date     time.Time
+	Start    time.Time
+	End      time.Time
+	Issuer   []string
+	Client   model.Client
+	Currency string
+	Lines    []Line
+	Subtotal int64
+	Taxes    []TaxLine
+	Total    int64
+
+	// TimeEntries contains the invoiced time entries.
+	TimeEntries []model.TimeEntry
+}
+
+// LastDay returns the last day of the invoiced period.
+// The End of the period is exclusive.
+func (invoice Invoice) LastDay() time.Time {
+	return invoice.End.Add(-time.Nanosecond)
+}
+
+// Create creates an invoice for the billable time entries of the client
+// of the given options. Time entries of the client's projects which have
+// already been invoiced (tagged with TagPrefix) are skipped.
+func Create(api model.TogglAPI, options Options) (Invoice, error) {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	clients, clientsError := api.GetClients()
+	if clientsError != nil {
+		return Invoice{}, errors.Wrap(clientsError, "Failed to fetch the clients")
+	}
+
+	var client model.Client
+	for _, candidate := range clients {
+		if candidate.ID == options.ClientID {
+			client = candidate
+		}
+	}
+
+	if client.ID == 0 {
+		return Invoice{}, fmt.Errorf("Client %d does not exist", options.ClientID)
+	}
+
+	projects, projectsError := api.GetProjects(options.WorkspaceID)
+	if projectsError != nil {
+		return Invoice{}, errors.Wrap(projectsError, "Failed to fetch the projects")
+	}
+
+	projectNames := make(map[int]string)
+	for _, project := range projects {
+		if project.ClientID == client.ID {
+			projectNames[project.ID] = project.Name
+		}
+	}
+
+	allTimeEntries, timeEntriesError := api.GetTimeEntries(options.Start, options.End)
+	if timeEntriesError != nil {
+		return Invoice{}, errors.Wrap(timeEntriesError, "Failed to fetch the time entries")
+	}
+
+	var timeEntries []model.TimeEntry
+	for _, timeEntry := range allTimeEntries {
+		if _, ofClient := projectNames[timeEntry.Pid]; ofClient && timeEntry.Billable && !IsInvoiced(timeEntry) {
+			timeEntries = append(timeEntries, timeEntry)
+		}
+	}
+
+	billingOptions := options.Billing
+	billingOptions.Projects = projects
+	billingOptions.IncludeNonBillable = false
+	bill, billError := billing.Calculate(timeEntries, billingOptions)
+	if billError != nil {
+		return Invoice{}, billError
+	}
+
+	if len(bill.Totals) > 1 {
+		return Invoice{}, fmt.Errorf("The time entries are billed in %d different currencies", len(bill.Totals))
+	}
+
+	invoice := Invoice{
+		Number: options.Number,
+		Date:   options.Now(),
+		Start:  options.Start,
+		End:    options.End,
+		Issuer: options.Issuer,
+		Client: client,
+	}
+
+	for currency, total := range bill.Totals {
+		invoice.Currency = currency
+		invoice.Subtotal = total
+	}
+
+	// only time entries with a line item are invoiced
+	billed := make(map[int]bool)
+	for _, lineItem := range bill.LineItems {
+		invoice.Lines = append(invoice.Lines, Line{
+			Date:        lineItem.Date,
+			Project:     projectNames[lineItem.ProjectID],
+			Description: lineItem.Description,
+			Billed:      lineItem.Billed,
+			Rate:        lineItem.Rate.Hourly,
+			Amount:      lineItem.Amount,
+		})
+
+		for _, id := range lineItem.TimeEntryIDs {
+			billed[id] = true
+		}
+	}
+
+	for _, timeEntry := range timeEntries {
+		if billed[timeEntry.ID] {
+			invoice.TimeEntries = append(invoice.TimeEntries, timeEntry)
+		}
+	}
+
+	invoice.Total = invoice.Subtotal
+	for _, tax := range options.Taxes {
+		amount := int64(math.Round(float64(invoice.Subtotal) * tax.Percent / 100))
+		invoice.Taxes = append(invoice.Taxes, TaxLine{Name: tax.Name, Percent: tax.Percent, Amount: amount})
+		invoice.Total += amount
+	}
+
+	return invoice, nil
+}
+
+// IsInvoiced returns true if the given time entry has a TagPrefix tag.
+func IsInvoiced(timeEntry model.TimeEntry) bool {
+	for _, tag := range timeEntry.Tags {
+		if strings.HasPrefix(tag, TagPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tag returns the default tag for invoices of the given
+// period end (e.g. "invoiced-2016-09").
+func Tag(end time.Time) string {
+	return TagPrefix + end.Add(-time.Nanosecond).Format("2006-01")
+}
+
+// MarkInvoiced adds the given tag to all time entries of the given invoice.
+// Returns the number of updated time entries.
+func MarkInvoiced(api model.TimeEntryAPI, invoice Invoice, tag string) (int, error) {
+	updated := 0
+	for _, timeEntry := range invoice.TimeEntries {
+		tags := append(append([]string(nil), timeEntry.Tags...), tag)
+		if _, err := api.PatchTimeEntry(timeEntry.ID, model.TimeEntryChanges{Tags: model.Strings(tags)}); err != nil {
+			return updated, errors.Wrap(err, fmt.Sprintf("Failed to tag time entry %d", timeEntry.ID))
+		}
+
+		updated++
+	}
+
+	return updated, nil
+}
+
+// numbersKey contains the store key of the invoice numbers.
+const numbersKey = "invoice/numbers"
+
+// NextNumber returns the next invoice number with the given prefix
+// for the year of the given date (e.g. "INV-2016-0001") and persists
+// the sequence in the given store.
+func NextNumber(store cache.Store, prefix string, date time.Time) (string, error) {
+	reservation, err := ReserveNumber(store, prefix, date)
+	if err != nil {
+		return "", err
+	}
+
+	if err := reservation.Commit(); err != nil {
+		return "", err
+	}
+
+	return reservation.Number, nil
+}
+
+// A NumberReservation contains an invoice number which is only
+// persisted once the invoice has been issued.
+type NumberReservation struct {
+	// Number contains the reserved invoice number (e.g. "INV-2016-0001").
+	Number string
+
+	store    cache.Store
+	key      string
+	sequence int
+}
+
+// ReserveNumber returns the next invoice number with the given prefix
+// for the year of the given date without persisting it. Call Commit
+// after the invoice has been issued; an uncommitted number is reused
+// by the next reservation.
+func ReserveNumber(store cache.Store, prefix string, date time.Time) (NumberReservation, error) {
+	sequences := make(map[string]int)
+	if _, _, err := store.Get(numbersKey, &sequences); err != nil {
+		return NumberReservation{}, errors.Wrap(err, "Failed to read the invoice numbers")
+	}
+
+	key := fmt.Sprintf("%s%d", prefix, date.Year())
+	sequence := sequences[key] + 1
+
+	return NumberReservation{
+		Number:   fmt.Sprintf("%s-%04d", key, sequence),
+		store:    store,
+		key:      key,
+		sequence: sequence,
+	}, nil
+}
+
+// Commit persists the reserved number in the store. Returns an error if
+// the number has been committed in the meantime (e.g. by a concurrent
+// run), so the caller can reserve a new number.
+func (reservation NumberReservation) Commit() error {
+	sequences := make(map[string]int)
+	if _, _, err := reservation.store.Get(numbersKey, &sequences); err != nil {
+		return errors.Wrap(err, "Failed to read the invoice numbers")
+	}
+
+	if sequences[reservation.key] >= reservation.sequence {
+		return fmt.Errorf("The invoice number %s has already been used", reservation.Number)
+	}
+
+	sequences[reservation.key] = reservation.sequence
+	if err := reservation.store.Set(numbersKey, sequences); err != nil {
+		return errors.Wrap(err, "Failed to write the invoice numbers")
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package invoice
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/billing"
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// newTestAPI returns an in-memory API with billable and non-billable time
+// entries of client Acme, an entry of another client and an invoiced entry.
+func newTestAPI() (*togglapitest.API, model.Client) {
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	acme, _ := api.CreateClient(model.Client{WorkspaceID: 1, Name: "Acme <Corp>"})
+	globex, _ := api.CreateClient(model.Client{WorkspaceID: 1, Name: "Globex"})
+	website, _ := api.CreateProject(model.Project{WorkspaceID: 1, ClientID: acme.ID, Name: "Website"})
+	shop, _ := api.CreateProject(model.Project{WorkspaceID: 1, ClientID: globex.ID, Name: "Shop"})
+
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: website.ID, Start: start, Stop: start.Add(90 * time.Minute), Billable: true, Description: "Layout"})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: website.ID, Start: start.Add(24 * time.Hour), Stop: start.Add(25 * time.Hour), Billable: true, Description: "Review"})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: website.ID, Start: start, Stop: start.Add(time.Hour), Billable: false, Description: "Internal"})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: website.ID, Start: start, Stop: start.Add(time.Hour), Billable: true, Tags: []string{"invoiced-2016-08"}})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: shop.ID, Start: start, Stop: start.Add(time.Hour), Billable: true})
+	return api, acme
+}
+
+// testOptions returns the invoice options for September 2016.
+func testOptions(clientID int) Options {
+	return Options{
+		Number:      "INV-2016-0001",
+		WorkspaceID: 1,
+		ClientID:    clientID,
+		Start:       time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC),
+		Issuer:      []string{"Jane Doe", "Main Street 1"},
+		Taxes:       []Tax{{Name: "VAT", Percent: 19}},
+		Billing: billing.Options{
+			Rates:    []billing.Rate{{Hourly: 10000, Currency: "EUR"}},
+			Location: time.UTC,
+		},
+		Now: func() time.Time { return time.Date(2016, 10, 2, 0, 0, 0, 0, time.UTC) },
+	}
+}
+
+func Test_Create_BillableUninvoicedEntriesOfClientAreInvoiced(t *testing.T) {
+	// arrange
+	api, acme := newTestAPI()
+
+	// act
+	invoice, err := Create(api, testOptions(acme.ID))
+
+	// assert
+	if err != nil || len(invoice.Lines) != 2 || len(invoice.TimeEntries) != 2 {
+		t.Fatalf("Create should have invoiced the two billable entries of Acme (Invoice: %+v, Error: %v)", invoice, err)
+	}
+
+	if invoice.Subtotal != 25000 || invoice.Taxes[0].Amount != 4750 || invoice.Total != 29750 || invoice.Currency != "EUR" {
+		t.Fail()
+		t.Logf("Create should have calculated the totals: %+v", invoice)
+	}
+}
+
+func Test_Create_UnknownClient_ErrorIsReturned(t *testing.T) {
+	// arrange
+	api, _ := newTestAPI()
+
+	// act
+	_, err := Create(api, testOptions(4711))
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("Create should have returned an error for the unknown client")
+	}
+}
+
+func Test_MarkInvoiced_EntriesAreSkippedByTheNextInvoice(t *testing.T) {
+	// arrange
+	api, acme := newTestAPI()
+	options := testOptions(acme.ID)
+	invoice, _ := Create(api, options)
+
+	// act
+	updated, err := MarkInvoiced(api, invoice, Tag(options.End))
+	nextInvoice, _ := Create(api, options)
+
+	// assert
+	if err != nil || updated != 2 || len(nextInvoice.Lines) != 0 {
+		t.Fail()
+		t.Logf("MarkInvoiced should have tagged the entries (Updated: %d, Next invoice: %+v, Error: %v)", updated, nextInvoice, err)
+	}
+
+	if api.TimeEntries[0].Tags[0] != "invoiced-2016-09" {
+		t.Fail()
+		t.Logf("MarkInvoiced should have added the tag of the period: %v", api.TimeEntries[0].Tags)
+	}
+}
+
+func Test_Render_AllFormats(t *testing.T) {
+	// arrange
+	api, acme := newTestAPI()
+	invoice, _ := Create(api, testOptions(acme.ID))
+
+	expectations := map[string][]string{
+		Text:     {"INVOICE INV-2016-0001", "Period: 2016-09-01 - 2016-09-30", "Acme <Corp>", "1.50 h", "150.00 EUR", "VAT (19%): 47.50 EUR", "Total:    297.50 EUR"},
+		Markdown: {"# Invoice INV-2016-0001", "| 2016-09-06 | Website | Layout | 1.50 | 100.00 EUR/h | 150.00 EUR |", "| **Total** | **297.50 EUR** |"},
+		HTML:     {"<h1>Invoice INV-2016-0001</h1>", "Acme &lt;Corp&gt;", "<strong>297.50 EUR</strong>"},
+	}
+
+	for format, expected := range expectations {
+		output := &bytes.Buffer{}
+
+		// act
+		err := Render(output, invoice, format)
+
+		// assert
+		for _, text := range expected {
+			if err != nil || !strings.Contains(output.String(), text) {
+				t.Fail()
+				t.Logf("Render(%q) should have written %q but wrote\n%s\n(Error: %v)", format, text, output.String(), err)
+			}
+		}
+	}
+}
+
+func Test_Render_UnknownFormat_ErrorIsReturned(t *testing.T) {
+	if err := Render(&bytes.Buffer{}, Invoice{}, "pdf"); err == nil {
+		t.Fail()
+		t.Logf("Render should have rejected the unknown format")
+	}
+}
+
+func Test_NextNumber_NumbersArePersistedPerYear(t *testing.T) {
+	// arrange
+	directory, err := ioutil.TempDir("", "togglapi-invoice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "invoices.json")
+
+	// act
+	first, _ := NextNumber(cache.NewFileStore(path), "INV-", time.Date(2016, 9, 30, 0, 0, 0, 0, time.UTC))
+	second, _ := NextNumber(cache.NewFileStore(path), "INV-", time.Date(2016, 10, 2, 0, 0, 0, 0, time.UTC))
+	nextYear, _ := NextNumber(cache.NewFileStore(path), "INV-", time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	// assert
+	if first != "INV-2016-0001" || second != "INV-2016-0002" || nextYear != "INV-2017-0001" {
+		t.Fail()
+		t.Logf("NextNumber returned %q, %q and %q", first, second, nextYear)
+	}
+}
+
+func Test_ReserveNumber_NumberIsOnlyPersistedOnCommit(t *testing.T) {
+	// arrange
+	directory, err := ioutil.TempDir("", "togglapi-invoice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "invoices.json")
+	date := time.Date(2016, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	// act
+	abandoned, _ := ReserveNumber(cache.NewFileStore(path), "INV-", date)
+	issued, _ := ReserveNumber(cache.NewFileStore(path), "INV-", date)
+	commitError := issued.Commit()
+	next, _ := ReserveNumber(cache.NewFileStore(path), "INV-", date)
+
+	// assert
+	if commitError != nil || abandoned.Number != "INV-2016-0001" || issued.Number != "INV-2016-0001" || next.Number != "INV-2016-0002" {
+		t.Fail()
+		t.Logf("ReserveNumber returned %q, %q and %q (commit error: %v)", abandoned.Number, issued.Number, next.Number, commitError)
+	}
+}
+
+func Test_NumberReservation_Commit_NumberAlreadyUsed_ErrorIsReturned(t *testing.T) {
+	// arrange
+	directory, err := ioutil.TempDir("", "togglapi-invoice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "invoices.json")
+	date := time.Date(2016, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	first, _ := ReserveNumber(cache.NewFileStore(path), "INV-", date)
+	second, _ := ReserveNumber(cache.NewFileStore(path), "INV-", date)
+
+	// act
+	firstError := first.Commit()
+	secondError := second.Commit()
+
+	// assert
+	if firstError != nil || secondError == nil || !strings.Contains(secondError.Error(), "INV-2016-0001") {
+		t.Fail()
+		t.Logf("Only the first commit of %s should have succeeded (errors: %v, %v)", first.Number, firstError, secondError)
+	}
+}
+
+func Test_ParseTax(t *testing.T) {
+	if tax, err := ParseTax("VAT=7.5"); err != nil || tax.Name != "VAT" || tax.Percent != 7.5 {
+		t.Fail()
+		t.Logf("ParseTax returned %+v (Error: %v)", tax, err)
+	}
+
+	if _, err := ParseTax("VAT"); err == nil {
+		t.Fail()
+		t.Logf("ParseTax should have rejected the tax without percentage")
+	}
+}
@@ -0,0 +1,139 @@
+package invoice
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"text/template"
+
+	"github.com/andreaskoch/togglapi/billing"
+)
+
+// The names of the available output formats.
+const (
+	Text     = "text"
+	Markdown = "markdown"
+	HTML     = "html"
+)
+
+// templates contains the built-in templates per format.
+var templates = map[string]string{
+	Text: `INVOICE {{.Number}}
+{{range .Issuer}}{{.}}
+{{end}}
+Date:   {{date .Date}}
+Period: {{date .Start}} - {{date .LastDay}}
+Client: {{.Client.Name}}{{if .Client.Notes}}
+        {{.Client.Notes}}{{end}}
+
+{{range .Lines}}{{date .Date}}  {{printf "%-20s" .Project}}  {{printf "%6.2f" .Hours}} h  {{printf "%14s" (rate .Rate $.Currency)}}  {{printf "%14s" (amount .Amount $.Currency)}}  {{.Description}}
+{{end}}
+Subtotal: {{amount .Subtotal .Currency}}
+{{range .Taxes}}{{.Name}} ({{.Percent}}%): {{amount .Amount $.Currency}}
+{{end}}Total:    {{amount .Total .Currency}}
+`,
+
+	Markdown: `# Invoice {{.Number}}
+{{range .Issuer}}
+{{.}}  {{end}}
+
+| | |
+|---|---|
+| Date | {{date .Date}} |
+| Period | {{date .Start}} - {{date .LastDay}} |
+| Client | {{.Client.Name}} |
+
+| Date | Project | Description | Hours | Rate | Amount |
+|---|---|---|--:|--:|--:|
+{{range .Lines}}| {{date .Date}} | {{.Project}} | {{.Description}} | {{printf "%.2f" .Hours}} | {{rate .Rate $.Currency}} | {{amount .Amount $.Currency}} |
+{{end}}
+| | |
+|---|--:|
+| Subtotal | {{amount .Subtotal .Currency}} |
+{{range .Taxes}}| {{.Name}} ({{.Percent}}%) | {{amount .Amount $.Currency}} |
+{{end}}| **Total** | **{{amount .Total .Currency}}** |
+`,
+
+	HTML: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<address>{{range .Issuer}}{{.}}<br>{{end}}</address>
+<dl>
+<dt>Date</dt><dd>{{date .Date}}</dd>
+<dt>Period</dt><dd>{{date .Start}} - {{date .LastDay}}</dd>
+<dt>Client</dt><dd>{{.Client.Name}}</dd>
+</dl>
+<table>
+<thead><tr><th>Date</th><th>Project</th><th>Description</th><th>Hours</th><th>Rate</th><th>Amount</th></tr></thead>
+<tbody>
+{{range .Lines}}<tr><td>{{date .Date}}</td><td>{{.Project}}</td><td>{{.Description}}</td><td>{{printf "%.2f" .Hours}}</td><td>{{rate .Rate $.Currency}}</td><td>{{amount .Amount $.Currency}}</td></tr>
+{{end}}</tbody>
+<tfoot>
+<tr><th colspan="5">Subtotal</th><td>{{amount .Subtotal .Currency}}</td></tr>
+{{range .Taxes}}<tr><th colspan="5">{{.Name}} ({{.Percent}}%)</th><td>{{amount .Amount $.Currency}}</td></tr>
+{{end}}<tr><th colspan="5">Total</th><td><strong>{{amount .Total .Currency}}</strong></td></tr>
+</tfoot>
+</table>
+</body>
+</html>
+`,
+}
+
+// Formats returns the names of the available output formats.
+func Formats() []string {
+	var names []string
+	for name := range templates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// templateFunctions contains the functions available in the templates.
+var templateFunctions = map[string]interface{}{
+	"date": func(t interface{ Format(string) string }) string {
+		return t.Format("2006-01-02")
+	},
+	"amount": billing.FormatAmount,
+	"rate": func(hourly int64, currency string) string {
+		return billing.FormatAmount(hourly, currency) + "/h"
+	},
+}
+
+// Render writes the given invoice in the given format (text, markdown or html).
+func Render(w io.Writer, invoice Invoice, format string) error {
+	source, exists := templates[format]
+	if !exists {
+		return fmt.Errorf("Unknown invoice format %q (available: html, markdown, text)", format)
+	}
+
+	return RenderTemplate(w, invoice, source, format == HTML)
+}
+
+// RenderTemplate writes the given invoice with the given Go template.
+// HTML templates escape the invoice data. The functions date, amount
+// and rate are available in the template.
+func RenderTemplate(w io.Writer, invoice Invoice, source string, html bool) error {
+	if html {
+		tmpl, parseError := htmltemplate.New("invoice").Funcs(templateFunctions).Parse(source)
+		if parseError != nil {
+			return parseError
+		}
+
+		return tmpl.Execute(w, invoice)
+	}
+
+	tmpl, parseError := template.New("invoice").Funcs(templateFunctions).Parse(source)
+	if parseError != nil {
+		return parseError
+	}
+
+	return tmpl.Execute(w, invoice)
+}
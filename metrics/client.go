@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets contains the upper bounds (in seconds) of the request latency histogram.
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// idPattern matches the IDs in routes like "time_entries/123/stop".
+var idPattern = regexp.MustCompile(`/\d+(/|$)`)
+
+// NewClient creates a new collector for the metrics of a Toggl API client.
+func NewClient() *Client {
+	return &Client{
+		requests:  make(map[requestKey]int),
+		latencies: make(map[requestKey]*latencyHistogram),
+	}
+}
+
+// Client collects the request counts, latencies and rate limit waits of a
+// Toggl API client. It implements togglapi.RequestObserver.
+type Client struct {
+	lock      sync.Mutex
+	requests  map[requestKey]int
+	latencies map[requestKey]*latencyHistogram
+	waits     int
+	waitTime  time.Duration
+}
+
+// requestKey identifies the requests of a single method and route.
+type requestKey struct {
+	method string
+	route  string
+	result string
+}
+
+// latencyHistogram contains the cumulative counts of the DefaultBuckets.
+type latencyHistogram struct {
+	buckets []int
+	count   int
+	sum     float64
+}
+
+// ObserveRequest records a finished request. The query and the IDs of the
+// route are removed (e.g. "time_entries/:id").
+func (client *Client) ObserveRequest(method, route string, duration, wait time.Duration, err error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	route = normalizeRoute(route)
+	client.requests[requestKey{method, route, result}]++
+
+	latencyKey := requestKey{method: method, route: route}
+	latency, exists := client.latencies[latencyKey]
+	if !exists {
+		latency = &latencyHistogram{buckets: make([]int, len(DefaultBuckets))}
+		client.latencies[latencyKey] = latency
+	}
+
+	seconds := duration.Seconds()
+	for index, bound := range DefaultBuckets {
+		if seconds <= bound {
+			latency.buckets[index]++
+		}
+	}
+
+	latency.count++
+	latency.sum += seconds
+
+	if wait > 0 {
+		client.waits++
+		client.waitTime += wait
+	}
+}
+
+// Write writes the client metrics in the Prometheus text format to the given writer.
+func (client *Client) Write(w io.Writer) error {
+	for _, family := range client.families() {
+		if err := family.write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// families returns the metric families of the recorded requests.
+func (client *Client) families() []family {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	requests := family{name: "toggl_client_requests_total", help: "The number of requests sent to the Toggl API.", kind: counter}
+	for key, count := range client.requests {
+		requests.samples = append(requests.samples, sample{
+			labels: []label{{"method", key.method}, {"route", key.route}, {"result", key.result}},
+			value:  float64(count),
+		})
+	}
+
+	latencies := family{name: "toggl_client_request_duration_seconds", help: "The duration of the requests sent to the Toggl API.", kind: histogram}
+	var latencyKeys []requestKey
+	for key := range client.latencies {
+		latencyKeys = append(latencyKeys, key)
+	}
+
+	sort.Slice(latencyKeys, func(i, j int) bool {
+		return latencyKeys[i].method+" "+latencyKeys[i].route < latencyKeys[j].method+" "+latencyKeys[j].route
+	})
+
+	for _, key := range latencyKeys {
+		latency := client.latencies[key]
+		labels := []label{{"method", key.method}, {"route", key.route}}
+		for index, bound := range DefaultBuckets {
+			latencies.samples = append(latencies.samples, sample{
+				suffix: "_bucket",
+				labels: append(append([]label(nil), labels...), label{"le", formatValue(bound)}),
+				value:  float64(latency.buckets[index]),
+			})
+		}
+
+		latencies.samples = append(latencies.samples,
+			sample{suffix: "_bucket", labels: append(append([]label(nil), labels...), label{"le", "+Inf"}), value: float64(latency.count)},
+			sample{suffix: "_sum", labels: labels, value: latency.sum},
+			sample{suffix: "_count", labels: labels, value: float64(latency.count)},
+		)
+	}
+
+	return []family{
+		requests.sortSamples(),
+		latencies,
+		{name: "toggl_client_rate_limit_waits_total", help: "The number of requests delayed by the rate limit.", kind: counter, samples: []sample{{value: float64(client.waits)}}},
+		{name: "toggl_client_rate_limit_wait_seconds_total", help: "The time spent waiting for the rate limit.", kind: counter, samples: []sample{{value: client.waitTime.Seconds()}}},
+	}
+}
+
+// normalizeRoute removes the query and replaces the IDs of the given route.
+func normalizeRoute(route string) string {
+	if index := strings.Index(route, "?"); index >= 0 {
+		route = route[:index]
+	}
+
+	return idPattern.ReplaceAllString(route, "/:id$1")
+}
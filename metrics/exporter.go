@@ -0,0 +1,307 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/togglapi/analytics"
+	"github.com/andreaskoch/togglapi/date"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// DefaultInterval contains the default time between two polls.
+const DefaultInterval = time.Minute
+
+// The periods of the tracked hours and the ranges (date.ParseRange) they cover.
+var periods = []struct {
+	name       string
+	expression string
+}{
+	{"today", "today"},
+	{"week", "this week"},
+	{"month", "this month"},
+}
+
+// Options contains the settings of an exporter.
+type Options struct {
+	// Location is used for the boundaries of the periods. Defaults to time.Local.
+	Location *time.Location
+
+	// WeekStart contains the first day of the week. Defaults to Monday.
+	WeekStart *time.Weekday
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Exporter polls the time entries of a Toggl API and serves the tracked
+// hours as Prometheus metrics.
+type Exporter struct {
+	api     model.TogglAPI
+	client  *Client
+	options Options
+
+	lock       sync.Mutex
+	hours      []family
+	polls      int
+	pollErrors int
+	lastPoll   time.Time
+}
+
+// NewExporter creates a new exporter for the time entries of the given API.
+// The metrics of the given client (optional) are served as well.
+func NewExporter(api model.TogglAPI, client *Client, options Options) *Exporter {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	return &Exporter{
+		api:     api,
+		client:  client,
+		options: options,
+	}
+}
+
+// Poll fetches the time entries of the current month and week and updates
+// the tracked hours. The metrics of the previous poll are kept on errors.
+func (exporter *Exporter) Poll() error {
+	hours, err := exporter.collect()
+
+	exporter.lock.Lock()
+	defer exporter.lock.Unlock()
+
+	exporter.polls++
+	if err != nil {
+		exporter.pollErrors++
+		return err
+	}
+
+	exporter.hours = hours
+	exporter.lastPoll = exporter.options.Now()
+	return nil
+}
+
+// Watch polls the time entries every interval until the stop channel is
+// closed. The error of every poll is passed to the given report function.
+func (exporter *Exporter) Watch(interval time.Duration, stop <-chan struct{}, report func(error)) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report(exporter.Poll())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Write writes all metrics in the Prometheus text format to the given writer.
+func (exporter *Exporter) Write(w io.Writer) error {
+	exporter.lock.Lock()
+	families := append([]family(nil), exporter.hours...)
+	families = append(families,
+		family{name: "toggl_exporter_polls_total", help: "The number of polls of the time entries.", kind: counter, samples: []sample{{value: float64(exporter.polls)}}},
+		family{name: "toggl_exporter_poll_errors_total", help: "The number of failed polls of the time entries.", kind: counter, samples: []sample{{value: float64(exporter.pollErrors)}}},
+	)
+
+	if !exporter.lastPoll.IsZero() {
+		families = append(families, family{name: "toggl_exporter_last_poll_timestamp_seconds", help: "The time of the last successful poll.", kind: gauge, samples: []sample{{value: float64(exporter.lastPoll.Unix())}}})
+	}
+
+	exporter.lock.Unlock()
+
+	for _, family := range families {
+		if err := family.write(w); err != nil {
+			return err
+		}
+	}
+
+	if exporter.client == nil {
+		return nil
+	}
+
+	return exporter.client.Write(w)
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (exporter *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buffer bytes.Buffer
+	if err := exporter.Write(&buffer); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buffer.Bytes())
+}
+
+// collect fetches the time entries and returns the families of the tracked hours.
+func (exporter *Exporter) collect() ([]family, error) {
+	now := exporter.options.Now().In(exporter.options.Location)
+	rangeOptions := date.RangeOptions{Location: exporter.options.Location, WeekStart: exporter.options.WeekStart, Now: now}
+
+	ranges := make([]date.Range, len(periods))
+	start := now
+	for index, period := range periods {
+		ranges[index], _ = date.ParseRange(period.expression, rangeOptions)
+		if ranges[index].Start.Before(start) {
+			start = ranges[index].Start
+		}
+	}
+
+	// time entries are selected by their start, so the day before is
+	// included for the time entries running into the periods
+	timeEntries, timeEntriesError := exporter.api.GetTimeEntries(start.AddDate(0, 0, -1), ranges[0].End)
+	if timeEntriesError != nil {
+		return nil, errors.Wrap(timeEntriesError, "Failed to fetch the time entries")
+	}
+
+	current, currentError := exporter.api.GetCurrentTimeEntry()
+	if currentError != nil {
+		return nil, errors.Wrap(currentError, "Failed to fetch the running time entry")
+	}
+
+	analyticsOptions := analytics.Options{Location: exporter.options.Location, WeekStart: exporter.options.WeekStart, Now: now}
+	if err := exporter.resolveNames(timeEntries, &analyticsOptions); err != nil {
+		return nil, err
+	}
+
+	total := family{name: "toggl_tracked_hours", help: "The hours tracked in the period.", kind: gauge}
+	projects := family{name: "toggl_project_tracked_hours", help: "The hours tracked per project in the period.", kind: gauge}
+	clients := family{name: "toggl_client_tracked_hours", help: "The hours tracked per client in the period.", kind: gauge}
+	tags := family{name: "toggl_tag_tracked_hours", help: "The hours tracked per tag in the period.", kind: gauge}
+	users := family{name: "toggl_user_tracked_hours", help: "The hours tracked per user in the period.", kind: gauge}
+
+	for index, period := range periods {
+		periodEntries := clip(timeEntries, ranges[index], now)
+		periodLabel := label{"period", period.name}
+
+		total.samples = append(total.samples, sample{labels: []label{periodLabel}, value: analytics.Sum(periodEntries, analyticsOptions).Duration.Hours()})
+		projects.samples = append(projects.samples, groupSamples(periodEntries, analytics.ByProject, analyticsOptions, periodLabel, "project")...)
+		clients.samples = append(clients.samples, groupSamples(periodEntries, analytics.ByClient, analyticsOptions, periodLabel, "client")...)
+		tags.samples = append(tags.samples, groupSamples(periodEntries, analytics.ByTag, analyticsOptions, periodLabel, "tag")...)
+		users.samples = append(users.samples, userSamples(periodEntries, periodLabel)...)
+	}
+
+	running := family{name: "toggl_timer_running", help: "1 if a time entry is running.", kind: gauge, samples: []sample{{value: 0}}}
+	runningSeconds := family{name: "toggl_timer_running_seconds", help: "The duration of the running time entry.", kind: gauge, samples: []sample{{value: 0}}}
+	if current.ID != 0 {
+		running.samples[0].value = 1
+		runningSeconds.samples[0].value = now.Sub(current.Start).Seconds()
+	}
+
+	return []family{total, projects.sortSamples(), clients.sortSamples(), tags.sortSamples(), users.sortSamples(), running, runningSeconds}, nil
+}
+
+// resolveNames fetches the projects and clients of the given time entries
+// and adds them to the given options.
+func (exporter *Exporter) resolveNames(timeEntries []model.TimeEntry, options *analytics.Options) error {
+	workspaces := make(map[int]bool)
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Pid != 0 && !workspaces[timeEntry.Wid] {
+			workspaces[timeEntry.Wid] = true
+
+			projects, projectsError := exporter.api.GetProjects(timeEntry.Wid)
+			if projectsError != nil {
+				return errors.Wrap(projectsError, fmt.Sprintf("Failed to fetch the projects of workspace %d", timeEntry.Wid))
+			}
+
+			options.Projects = append(options.Projects, projects...)
+		}
+	}
+
+	for _, project := range options.Projects {
+		if project.ClientID != 0 {
+			clients, clientsError := exporter.api.GetClients()
+			if clientsError != nil {
+				return errors.Wrap(clientsError, "Failed to fetch the clients")
+			}
+
+			options.Clients = clients
+			break
+		}
+	}
+
+	return nil
+}
+
+// groupSamples returns the hours of the given time entries grouped by the given grouping.
+func groupSamples(timeEntries []model.TimeEntry, grouping analytics.Grouping, options analytics.Options, periodLabel label, name string) []sample {
+	var samples []sample
+	for _, total := range analytics.Group(timeEntries, grouping, options) {
+		samples = append(samples, sample{labels: []label{periodLabel, {name, total.Label}}, value: total.Duration.Hours()})
+	}
+
+	return samples
+}
+
+// userSamples returns the hours of the given clipped time entries per user ID.
+func userSamples(timeEntries []model.TimeEntry, periodLabel label) []sample {
+	durations := make(map[int]time.Duration)
+	for _, timeEntry := range timeEntries {
+		durations[timeEntry.Uid] += timeEntry.Stop.Sub(timeEntry.Start)
+	}
+
+	var users []int
+	for user := range durations {
+		users = append(users, user)
+	}
+
+	sort.Ints(users)
+
+	var samples []sample
+	for _, user := range users {
+		samples = append(samples, sample{labels: []label{periodLabel, {"user", strconv.Itoa(user)}}, value: durations[user].Hours()})
+	}
+
+	return samples
+}
+
+// clip returns the parts of the given time entries within the given range.
+// Running time entries end now; time entries starting in the future are dropped.
+func clip(timeEntries []model.TimeEntry, period date.Range, now time.Time) []model.TimeEntry {
+	end := period.End.Add(time.Second)
+
+	var clipped []model.TimeEntry
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() {
+			timeEntry.Stop = now
+			timeEntry.Duration = int(now.Sub(timeEntry.Start).Seconds())
+		}
+
+		if !timeEntry.Stop.After(timeEntry.Start) || !timeEntry.Stop.After(period.Start) || !timeEntry.Start.Before(end) {
+			continue
+		}
+
+		if timeEntry.Start.Before(period.Start) {
+			timeEntry.Start = period.Start
+		}
+
+		if timeEntry.Stop.After(end) {
+			timeEntry.Stop = end
+		}
+
+		timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+		clipped = append(clipped, timeEntry)
+	}
+
+	return clipped
+}
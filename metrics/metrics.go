@@ -0,0 +1,99 @@
+// Package metrics exposes the tracked time of a Toggl account and the
+// requests of the Toggl API client as Prometheus metrics in the text
+// exposition format.
+//
+// The Exporter polls the time entries periodically and serves the hours
+// tracked today, this week and this month per project, client, tag and
+// user. The Client collects the request counts, latencies and rate limit
+// waits of a REST requester created with togglapi.NewObservedRESTRequester.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The types of metric families.
+const (
+	counter   = "counter"
+	gauge     = "gauge"
+	histogram = "histogram"
+)
+
+// label contains the name and value of a single label.
+type label struct {
+	name  string
+	value string
+}
+
+// sample contains a single value of a metric family.
+type sample struct {
+	// suffix is appended to the name of the family (e.g. "_bucket").
+	suffix string
+	labels []label
+	value  float64
+}
+
+// family contains the samples of a single metric.
+type family struct {
+	name    string
+	help    string
+	kind    string
+	samples []sample
+}
+
+// write writes the family in the Prometheus text format.
+// Families without samples are skipped.
+func (family family) write(w io.Writer) error {
+	if len(family.samples) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", family.name, family.help, family.name, family.kind); err != nil {
+		return err
+	}
+
+	for _, sample := range family.samples {
+		if _, err := fmt.Fprintf(w, "%s%s%s %s\n", family.name, sample.suffix, formatLabels(sample.labels), formatValue(sample.value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortSamples sorts the samples of the family by their labels.
+func (family family) sortSamples() family {
+	sort.SliceStable(family.samples, func(i, j int) bool {
+		return formatLabels(family.samples[i].labels) < formatLabels(family.samples[j].labels)
+	})
+
+	return family
+}
+
+// formatLabels returns the given labels in the Prometheus text format (e.g. {period="today"}).
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var pairs []string
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label.name, escapeLabelValue(label.value)))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeLabelValue escapes backslashes, quotes and line breaks in label values.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatValue returns the shortest decimal representation of the given value.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// newTestExporter returns an exporter for a Wednesday noon in Berlin
+// (2016-09-07 12:00) and the in-memory API it polls.
+func newTestExporter() (*Exporter, *togglapitest.API) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	now := time.Date(2016, 9, 7, 12, 0, 0, 0, berlin)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2016, month, day, hour, minute, 0, 0, berlin)
+	}
+
+	api := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Acme"})
+	api.Clients = []model.Client{{ID: 5, WorkspaceID: 1, Name: "Acme Corp"}}
+	api.Projects = []model.Project{{ID: 10, WorkspaceID: 1, ClientID: 5, Name: "Website"}}
+	api.TimeEntries = []model.TimeEntry{
+		{ID: 1, Wid: 1, Pid: 10, Uid: 1, Start: at(9, 5, 9, 0), Stop: at(9, 5, 11, 0), Duration: 7200, Tags: []string{"dev"}},
+		{ID: 2, Wid: 1, Uid: 2, Start: at(9, 7, 8, 0), Stop: at(9, 7, 9, 30), Duration: 5400},
+		{ID: 3, Wid: 1, Pid: 10, Uid: 1, Start: at(9, 7, 11, 0), Duration: -int(at(9, 7, 11, 0).Unix())},
+		{ID: 4, Wid: 1, Pid: 10, Uid: 1, Start: at(8, 31, 23, 0), Stop: at(9, 1, 1, 0), Duration: 7200},
+	}
+
+	exporter := NewExporter(api, nil, Options{Location: berlin, Now: func() time.Time { return now }})
+	return exporter, api
+}
+
+func Test_Exporter_Poll_TrackedHoursAreExported(t *testing.T) {
+	// arrange
+	exporter, _ := newTestExporter()
+
+	// act
+	pollError := exporter.Poll()
+	var output bytes.Buffer
+	writeError := exporter.Write(&output)
+
+	// assert
+	if pollError != nil || writeError != nil {
+		t.Fatalf("Poll and Write should not fail but returned %v and %v", pollError, writeError)
+	}
+
+	expectedLines := []string{
+		"# TYPE toggl_tracked_hours gauge",
+		`toggl_tracked_hours{period="today"} 2.5`,
+		`toggl_tracked_hours{period="week"} 4.5`,
+		`toggl_tracked_hours{period="month"} 5.5`,
+		`toggl_project_tracked_hours{period="month",project="Website"} 4`,
+		`toggl_project_tracked_hours{period="today",project="(no project)"} 1.5`,
+		`toggl_client_tracked_hours{period="week",client="Acme Corp"} 3`,
+		`toggl_tag_tracked_hours{period="week",tag="dev"} 2`,
+		`toggl_user_tracked_hours{period="today",user="2"} 1.5`,
+		`toggl_user_tracked_hours{period="month",user="1"} 4`,
+		"toggl_timer_running 1",
+		"toggl_timer_running_seconds 3600",
+		"toggl_exporter_polls_total 1",
+		"toggl_exporter_poll_errors_total 0",
+	}
+
+	lines := strings.Split(output.String(), "\n")
+	for _, expected := range expectedLines {
+		found := false
+		for _, line := range lines {
+			found = found || line == expected
+		}
+
+		if !found {
+			t.Fail()
+			t.Logf("The metrics should contain %q:\n%s", expected, output.String())
+		}
+	}
+}
+
+func Test_Exporter_PollFails_PreviousMetricsAreKept(t *testing.T) {
+	// arrange
+	exporter, api := newTestExporter()
+	exporter.Poll()
+	api.Errors["GetTimeEntries"] = fmt.Errorf("Toggl is down")
+
+	// act
+	err := exporter.Poll()
+	var output bytes.Buffer
+	exporter.Write(&output)
+
+	// assert
+	if err == nil || !strings.Contains(output.String(), "toggl_exporter_poll_errors_total 1\n") || !strings.Contains(output.String(), `toggl_tracked_hours{period="today"} 2.5`) {
+		t.Fail()
+		t.Logf("Poll should have returned an error and kept the previous metrics but returned %v:\n%s", err, output.String())
+	}
+}
+
+func Test_Exporter_ServeHTTP_MetricsAreServedInTextFormat(t *testing.T) {
+	// arrange
+	exporter, _ := newTestExporter()
+	exporter.Poll()
+	recorder := httptest.NewRecorder()
+
+	// act
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	// assert
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") || !strings.Contains(recorder.Body.String(), "toggl_timer_running 1") {
+		t.Fail()
+		t.Logf("ServeHTTP should have served the metrics as text but served %q:\n%s", recorder.Header().Get("Content-Type"), recorder.Body.String())
+	}
+}
+
+func Test_Client_ObserveRequest_RequestMetricsAreExported(t *testing.T) {
+	// arrange
+	client := NewClient()
+
+	// act
+	client.ObserveRequest("GET", "time_entries?start_date=2016-09-01", 200*time.Millisecond, 0, nil)
+	client.ObserveRequest("PUT", "time_entries/123/stop", 2*time.Second, 500*time.Millisecond, nil)
+	client.ObserveRequest("PUT", "time_entries/456/stop", time.Second, 0, fmt.Errorf("Some error"))
+
+	var output bytes.Buffer
+	err := client.Write(&output)
+
+	// assert
+	expectedLines := []string{
+		`toggl_client_requests_total{method="GET",route="time_entries",result="success"} 1`,
+		`toggl_client_requests_total{method="PUT",route="time_entries/:id/stop",result="error"} 1`,
+		`toggl_client_requests_total{method="PUT",route="time_entries/:id/stop",result="success"} 1`,
+		`toggl_client_request_duration_seconds_bucket{method="GET",route="time_entries",le="0.25"} 1`,
+		`toggl_client_request_duration_seconds_bucket{method="PUT",route="time_entries/:id/stop",le="1"} 1`,
+		`toggl_client_request_duration_seconds_bucket{method="PUT",route="time_entries/:id/stop",le="+Inf"} 2`,
+		`toggl_client_request_duration_seconds_sum{method="PUT",route="time_entries/:id/stop"} 3`,
+		`toggl_client_request_duration_seconds_count{method="PUT",route="time_entries/:id/stop"} 2`,
+		"toggl_client_rate_limit_waits_total 1",
+		"toggl_client_rate_limit_wait_seconds_total 0.5",
+	}
+
+	for _, expected := range expectedLines {
+		if err != nil || !strings.Contains(output.String(), expected+"\n") {
+			t.Fail()
+			t.Logf("The client metrics should contain %q (%v):\n%s", expected, err, output.String())
+		}
+	}
+}
+
+func Test_formatLabels_SpecialCharactersAreEscaped(t *testing.T) {
+	// act
+	result := formatLabels([]label{{"project", "A \"quoted\" \\ name\nwith a line break"}})
+
+	// assert
+	expected := `{project="A \"quoted\" \\ name\nwith a line break"}`
+	if result != expected {
+		t.Fail()
+		t.Logf("formatLabels should have returned %s but returned %s", expected, result)
+	}
+}
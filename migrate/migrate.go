@@ -0,0 +1,418 @@
+// Package migrate provides functions for copying time entries between
+// two Toggl accounts (e.g. when a contractor joins or leaves a team).
+//
+// Clients and projects are mapped by name; missing ones are created in
+// the destination workspace. Time entries which already exist in the
+// destination workspace (same start, stop, description and project) are
+// skipped so a migration can be run again without creating duplicates.
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// The actions taken for a source time entry.
+const (
+	// Copied time entries have been created in the destination workspace.
+	Copied = "copied"
+
+	// Duplicate time entries already exist in the destination workspace.
+	Duplicate = "duplicate"
+
+	// Running time entries are not copied.
+	Running = "running"
+)
+
+// Options contains the settings of a migration.
+type Options struct {
+	// SourceWorkspaceID restricts the migration to the time entries of
+	// the source workspace with the given ID. Zero copies the time
+	// entries of all source workspaces.
+	SourceWorkspaceID int
+
+	// DestinationWorkspaceID contains the ID of the workspace the time entries are copied to.
+	DestinationWorkspaceID int
+
+	// Start and End define the period of the copied time entries.
+	Start time.Time
+	End   time.Time
+
+	// DryRun only reports the actions without creating anything.
+	DryRun bool
+}
+
+// EntryResult contains the action taken for a single source time entry.
+type EntryResult struct {
+	Source        model.TimeEntry
+	Action        string
+	DestinationID int
+	ProjectName   string
+}
+
+// Report contains the outcome of a migration.
+type Report struct {
+	DryRun          bool
+	CreatedClients  []string
+	CreatedProjects []string
+	Entries         []EntryResult
+}
+
+// Count returns the number of time entries with the given action.
+func (report Report) Count(action string) int {
+	count := 0
+	for _, entry := range report.Entries {
+		if entry.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Write prints the actions of the report to the given writer.
+func (report Report) Write(w io.Writer) error {
+	prefix := ""
+	if report.DryRun {
+		prefix = "would "
+	}
+
+	for _, name := range report.CreatedClients {
+		if _, err := fmt.Fprintf(w, "%screate client  %q\n", prefix, name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range report.CreatedProjects {
+		if _, err := fmt.Fprintf(w, "%screate project %q\n", prefix, name); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range report.Entries {
+		action := entry.Action
+		if report.DryRun && action == Copied {
+			action = "would copy"
+		}
+
+		if _, err := fmt.Fprintf(w, "%-10s %d %s %q project=%q\n",
+			action,
+			entry.Source.ID,
+			entry.Source.Start.Format("2006-01-02 15:04"),
+			entry.Source.Description,
+			entry.ProjectName,
+		); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d copied, %d duplicates, %d running\n", report.Count(Copied), report.Count(Duplicate), report.Count(Running))
+	return err
+}
+
+// Migrate copies the time entries of the given period from the source to
+// the destination API. The report contains the actions taken before an
+// error occurred.
+func Migrate(source, destination model.TogglAPI, options Options) (Report, error) {
+	if options.DestinationWorkspaceID == 0 {
+		return Report{}, fmt.Errorf("No destination workspace specified")
+	}
+
+	if !options.End.After(options.Start) {
+		return Report{}, fmt.Errorf("The end of the period must be after its start")
+	}
+
+	migrator := &migrator{
+		source:      source,
+		destination: destination,
+		options:     options,
+		report:      Report{DryRun: options.DryRun},
+		clientIDs:   make(map[int]int),
+		projectIDs:  make(map[int]int),
+		existing:    make(map[string]bool),
+	}
+
+	timeEntries, loadError := migrator.load()
+	if loadError != nil {
+		return migrator.report, loadError
+	}
+
+	for _, timeEntry := range timeEntries {
+		if err := migrator.copyTimeEntry(timeEntry); err != nil {
+			return migrator.report, err
+		}
+	}
+
+	return migrator.report, nil
+}
+
+// migrator contains the state of a single migration.
+type migrator struct {
+	source      model.TogglAPI
+	destination model.TogglAPI
+	options     Options
+	report      Report
+
+	sourceClients  map[int]model.Client
+	sourceProjects map[int]model.Project
+
+	destinationClients  []model.Client
+	destinationProjects []model.Project
+
+	// clientIDs and projectIDs map source IDs to destination IDs.
+	clientIDs  map[int]int
+	projectIDs map[int]int
+
+	// existing contains the keys of the destination time entries.
+	existing map[string]bool
+
+	// lastPlaceholderID is decremented for clients and projects
+	// which would be created in a dry run.
+	lastPlaceholderID int
+}
+
+// load fetches the source time entries sorted by start and the
+// clients, projects and time entries used for mapping and dedupe.
+func (migrator *migrator) load() ([]model.TimeEntry, error) {
+	options := migrator.options
+
+	allTimeEntries, timeEntriesError := migrator.source.GetTimeEntries(options.Start, options.End)
+	if timeEntriesError != nil {
+		return nil, errors.Wrap(timeEntriesError, "Failed to fetch the source time entries")
+	}
+
+	var timeEntries []model.TimeEntry
+	workspaceIDs := make(map[int]bool)
+	for _, timeEntry := range allTimeEntries {
+		if options.SourceWorkspaceID != 0 && timeEntry.Wid != options.SourceWorkspaceID {
+			continue
+		}
+
+		timeEntries = append(timeEntries, timeEntry)
+		workspaceIDs[timeEntry.Wid] = true
+	}
+
+	sort.SliceStable(timeEntries, func(i, j int) bool {
+		return timeEntries[i].Start.Before(timeEntries[j].Start)
+	})
+
+	sourceClients, sourceClientsError := migrator.source.GetClients()
+	if sourceClientsError != nil {
+		return nil, errors.Wrap(sourceClientsError, "Failed to fetch the source clients")
+	}
+
+	migrator.sourceClients = make(map[int]model.Client)
+	for _, client := range sourceClients {
+		migrator.sourceClients[client.ID] = client
+	}
+
+	migrator.sourceProjects = make(map[int]model.Project)
+	for workspaceID := range workspaceIDs {
+		projects, projectsError := migrator.source.GetProjects(workspaceID)
+		if projectsError != nil {
+			return nil, errors.Wrap(projectsError, fmt.Sprintf("Failed to fetch the projects of source workspace %d", workspaceID))
+		}
+
+		for _, project := range projects {
+			migrator.sourceProjects[project.ID] = project
+		}
+	}
+
+	destinationClients, destinationClientsError := migrator.destination.GetClients()
+	if destinationClientsError != nil {
+		return nil, errors.Wrap(destinationClientsError, "Failed to fetch the destination clients")
+	}
+
+	for _, client := range destinationClients {
+		if client.WorkspaceID == options.DestinationWorkspaceID {
+			migrator.destinationClients = append(migrator.destinationClients, client)
+		}
+	}
+
+	destinationProjects, destinationProjectsError := migrator.destination.GetProjects(options.DestinationWorkspaceID)
+	if destinationProjectsError != nil {
+		return nil, errors.Wrap(destinationProjectsError, "Failed to fetch the destination projects")
+	}
+
+	migrator.destinationProjects = destinationProjects
+
+	destinationTimeEntries, destinationTimeEntriesError := migrator.destination.GetTimeEntries(options.Start, options.End)
+	if destinationTimeEntriesError != nil {
+		return nil, errors.Wrap(destinationTimeEntriesError, "Failed to fetch the destination time entries")
+	}
+
+	for _, timeEntry := range destinationTimeEntries {
+		if timeEntry.Wid == options.DestinationWorkspaceID {
+			migrator.existing[timeEntryKey(timeEntry)] = true
+		}
+	}
+
+	return timeEntries, nil
+}
+
+// copyTimeEntry copies the given source time entry to the
+// destination workspace unless it already exists there.
+func (migrator *migrator) copyTimeEntry(source model.TimeEntry) error {
+	result := EntryResult{
+		Source:      source,
+		ProjectName: migrator.sourceProjects[source.Pid].Name,
+	}
+
+	if source.Duration < 0 || source.Stop.IsZero() {
+		result.Action = Running
+		migrator.report.Entries = append(migrator.report.Entries, result)
+		return nil
+	}
+
+	projectID, projectError := migrator.mapProject(source.Pid)
+	if projectError != nil {
+		return projectError
+	}
+
+	timeEntry := source
+	timeEntry.ID = 0
+	timeEntry.Wid = migrator.options.DestinationWorkspaceID
+	timeEntry.Pid = projectID
+
+	key := timeEntryKey(timeEntry)
+	if migrator.existing[key] {
+		result.Action = Duplicate
+		migrator.report.Entries = append(migrator.report.Entries, result)
+		return nil
+	}
+
+	if !migrator.options.DryRun {
+		createdTimeEntry, createError := migrator.destination.CreateTimeEntry(timeEntry)
+		if createError != nil {
+			return errors.Wrap(createError, fmt.Sprintf("Failed to copy time entry %d", source.ID))
+		}
+
+		result.DestinationID = createdTimeEntry.ID
+	}
+
+	migrator.existing[key] = true
+	result.Action = Copied
+	migrator.report.Entries = append(migrator.report.Entries, result)
+	return nil
+}
+
+// mapProject returns the ID of the destination project with the name
+// and client of the source project with the given ID and creates the
+// project if it does not exist. Projects without a client only match
+// destination projects without a client.
+func (migrator *migrator) mapProject(sourceID int) (int, error) {
+	if sourceID == 0 {
+		return 0, nil
+	}
+
+	if id, mapped := migrator.projectIDs[sourceID]; mapped {
+		return id, nil
+	}
+
+	sourceProject, exists := migrator.sourceProjects[sourceID]
+	if !exists {
+		return 0, fmt.Errorf("Source project %d does not exist", sourceID)
+	}
+
+	clientID, clientError := migrator.mapClient(sourceProject.ClientID)
+	if clientError != nil {
+		return 0, clientError
+	}
+
+	for _, project := range migrator.destinationProjects {
+		if normalize(project.Name) == normalize(sourceProject.Name) && project.ClientID == clientID {
+			migrator.projectIDs[sourceID] = project.ID
+			return project.ID, nil
+		}
+	}
+
+	project := model.Project{
+		WorkspaceID: migrator.options.DestinationWorkspaceID,
+		ClientID:    clientID,
+		Name:        sourceProject.Name,
+	}
+
+	if migrator.options.DryRun {
+		project.ID = migrator.placeholderID()
+	} else {
+		createdProject, createError := migrator.destination.CreateProject(project)
+		if createError != nil {
+			return 0, errors.Wrap(createError, fmt.Sprintf("Failed to create project %q", project.Name))
+		}
+
+		project = createdProject
+	}
+
+	migrator.destinationProjects = append(migrator.destinationProjects, project)
+	migrator.report.CreatedProjects = append(migrator.report.CreatedProjects, project.Name)
+	migrator.projectIDs[sourceID] = project.ID
+	return project.ID, nil
+}
+
+// mapClient returns the ID of the destination client with the name of the
+// source client with the given ID and creates the client if it does not exist.
+func (migrator *migrator) mapClient(sourceID int) (int, error) {
+	if sourceID == 0 {
+		return 0, nil
+	}
+
+	if id, mapped := migrator.clientIDs[sourceID]; mapped {
+		return id, nil
+	}
+
+	sourceClient, exists := migrator.sourceClients[sourceID]
+	if !exists {
+		return 0, fmt.Errorf("Source client %d does not exist", sourceID)
+	}
+
+	for _, client := range migrator.destinationClients {
+		if normalize(client.Name) == normalize(sourceClient.Name) {
+			migrator.clientIDs[sourceID] = client.ID
+			return client.ID, nil
+		}
+	}
+
+	client := model.Client{
+		WorkspaceID: migrator.options.DestinationWorkspaceID,
+		Name:        sourceClient.Name,
+		Notes:       sourceClient.Notes,
+	}
+
+	if migrator.options.DryRun {
+		client.ID = migrator.placeholderID()
+	} else {
+		createdClient, createError := migrator.destination.CreateClient(client)
+		if createError != nil {
+			return 0, errors.Wrap(createError, fmt.Sprintf("Failed to create client %q", client.Name))
+		}
+
+		client = createdClient
+	}
+
+	migrator.destinationClients = append(migrator.destinationClients, client)
+	migrator.report.CreatedClients = append(migrator.report.CreatedClients, client.Name)
+	migrator.clientIDs[sourceID] = client.ID
+	return client.ID, nil
+}
+
+// placeholderID returns a new negative ID for dry runs.
+func (migrator *migrator) placeholderID() int {
+	migrator.lastPlaceholderID--
+	return migrator.lastPlaceholderID
+}
+
+// timeEntryKey returns the key which identifies duplicate time entries.
+func timeEntryKey(timeEntry model.TimeEntry) string {
+	return fmt.Sprintf("%d|%d|%d|%s", timeEntry.Start.Unix(), timeEntry.Stop.Unix(), timeEntry.Pid, normalize(timeEntry.Description))
+}
+
+// normalize returns the given name in lower case without surrounding white space.
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
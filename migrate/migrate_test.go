@@ -0,0 +1,170 @@
+package migrate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+var (
+	periodStart = time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd   = time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newAccounts returns a source account with two time entries on a
+// project of a client and an empty destination account.
+func newAccounts() (*togglapitest.API, *togglapitest.API) {
+	source := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Contractor"})
+	client, _ := source.CreateClient(model.Client{WorkspaceID: 1, Name: "Acme"})
+	project, _ := source.CreateProject(model.Project{WorkspaceID: 1, ClientID: client.ID, Name: "Website"})
+
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	source.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: project.ID, Start: start, Stop: start.Add(time.Hour), Description: "Layout"})
+	source.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start.Add(2 * time.Hour), Stop: start.Add(3 * time.Hour), Description: "Meeting"})
+
+	destination := togglapitest.NewAPI(model.Workspace{ID: 9, Name: "Team"})
+	return source, destination
+}
+
+func Test_Migrate_MissingClientsAndProjectsAreCreated(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+
+	// act
+	report, err := Migrate(source, destination, Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd})
+
+	// assert
+	if err != nil || len(destination.Clients) != 1 || len(destination.Projects) != 1 || len(destination.TimeEntries) != 2 || report.Count(Copied) != 2 {
+		t.Fatalf("Migrate should have copied the client, the project and the time entries (Report: %+v, Error: %v)", report, err)
+	}
+
+	project := destination.Projects[0]
+	if project.WorkspaceID != 9 || project.ClientID != destination.Clients[0].ID || destination.TimeEntries[0].Pid != project.ID {
+		t.Fail()
+		t.Logf("Migrate should have mapped the IDs to the destination workspace")
+	}
+}
+
+func Test_Migrate_ExistingClientsAndProjectsAreMappedByName(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+	client, _ := destination.CreateClient(model.Client{WorkspaceID: 9, Name: "ACME"})
+	project, _ := destination.CreateProject(model.Project{WorkspaceID: 9, ClientID: client.ID, Name: "website"})
+
+	// act
+	report, err := Migrate(source, destination, Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd})
+
+	// assert
+	if err != nil || len(report.CreatedClients) != 0 || len(report.CreatedProjects) != 0 || destination.TimeEntries[0].Pid != project.ID {
+		t.Fail()
+		t.Logf("Migrate should have used the existing client and project (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Migrate_ProjectWithoutClient_ProjectOfClientIsNotUsed(t *testing.T) {
+	// arrange
+	source := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Contractor"})
+	sourceProject, _ := source.CreateProject(model.Project{WorkspaceID: 1, Name: "Website"})
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	source.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: sourceProject.ID, Start: start, Stop: start.Add(time.Hour), Description: "Layout"})
+
+	destination := togglapitest.NewAPI(model.Workspace{ID: 9, Name: "Team"})
+	client, _ := destination.CreateClient(model.Client{WorkspaceID: 9, Name: "Acme"})
+	destination.CreateProject(model.Project{WorkspaceID: 9, ClientID: client.ID, Name: "Website"})
+
+	// act
+	report, err := Migrate(source, destination, Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd})
+
+	// assert
+	if err != nil || len(report.CreatedProjects) != 1 || len(destination.Projects) != 2 || destination.TimeEntries[0].Pid != destination.Projects[1].ID || destination.Projects[1].ClientID != 0 {
+		t.Fail()
+		t.Logf("Migrate should have created a project without a client (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Migrate_SecondRun_NoDuplicatesAreCreated(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+	options := Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd}
+	Migrate(source, destination, options)
+
+	// act
+	report, err := Migrate(source, destination, options)
+
+	// assert
+	if err != nil || len(destination.TimeEntries) != 2 || report.Count(Duplicate) != 2 || report.Count(Copied) != 0 {
+		t.Fail()
+		t.Logf("Migrate should have detected the copied time entries (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Migrate_DryRun_NothingIsCreated(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+
+	// act
+	report, err := Migrate(source, destination, Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd, DryRun: true})
+
+	// assert
+	if err != nil || len(destination.Clients) != 0 || len(destination.Projects) != 0 || len(destination.TimeEntries) != 0 {
+		t.Fail()
+		t.Logf("Migrate should not have created anything in a dry run (Error: %v)", err)
+	}
+
+	if len(report.CreatedClients) != 1 || len(report.CreatedProjects) != 1 || report.Count(Copied) != 2 {
+		t.Fail()
+		t.Logf("Migrate should have reported the actions of the dry run: %+v", report)
+	}
+}
+
+func Test_Migrate_RunningTimeEntry_TimeEntryIsSkipped(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+	start := time.Date(2016, 9, 7, 8, 0, 0, 0, time.UTC)
+	source.TimeEntries = append(source.TimeEntries, model.TimeEntry{ID: 1, Wid: 1, Start: start, Duration: -int(start.Unix()), Description: "Running"})
+
+	// act
+	report, err := Migrate(source, destination, Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd})
+
+	// assert
+	if err != nil || report.Count(Running) != 1 || len(destination.TimeEntries) != 2 {
+		t.Fail()
+		t.Logf("Migrate should have skipped the running time entry (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Migrate_NoDestinationWorkspace_ErrorIsReturned(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+
+	// act
+	_, err := Migrate(source, destination, Options{Start: periodStart, End: periodEnd})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("Migrate should have returned an error for the missing destination workspace")
+	}
+}
+
+func Test_Report_Write_ActionsAreListed(t *testing.T) {
+	// arrange
+	source, destination := newAccounts()
+	report, _ := Migrate(source, destination, Options{DestinationWorkspaceID: 9, Start: periodStart, End: periodEnd, DryRun: true})
+	output := &bytes.Buffer{}
+
+	// act
+	err := report.Write(output)
+
+	// assert
+	for _, expected := range []string{`would create client  "Acme"`, `would create project "Website"`, `"Layout" project="Website"`, "2 copied, 0 duplicates, 0 running"} {
+		if err != nil || !strings.Contains(output.String(), expected) {
+			t.Fail()
+			t.Logf("Write should have printed %q but printed\n%s", expected, output.String())
+		}
+	}
+}
@@ -2,20 +2,40 @@ package model
 
 import "time"
 
-// The ProjectAPI interface provides functions for creating and fetching projects.
+// The ProjectAPI interface provides functions for creating, fetching,
+// updating and deleting projects.
 type ProjectAPI interface {
 	// CreateProject creates a new project.
 	CreateProject(project Project) (Project, error)
 
+	// UpdateProject updates the project with the ID of the given project.
+	UpdateProject(project Project) (Project, error)
+
+	// PatchProject changes only the given fields of the project with the given ID.
+	PatchProject(id int, changes ProjectChanges) (Project, error)
+
+	// DeleteProject deletes the project with the given ID.
+	DeleteProject(id int) error
+
 	// GetProjects returns all projects for the given workspace.
 	GetProjects(workspaceID int) ([]Project, error)
 }
 
-// The ClientAPI interface provides functions for creating and fetching clients.
+// The ClientAPI interface provides functions for creating, fetching,
+// updating and deleting clients.
 type ClientAPI interface {
 	// CreateClient creates a new client.
 	CreateClient(client Client) (Client, error)
 
+	// UpdateClient updates the client with the ID of the given client.
+	UpdateClient(client Client) (Client, error)
+
+	// PatchClient changes only the given fields of the client with the given ID.
+	PatchClient(id int, changes ClientChanges) (Client, error)
+
+	// DeleteClient deletes the client with the given ID.
+	DeleteClient(id int) error
+
 	// GetClients returns all clients.
 	GetClients() ([]Client, error)
 }
@@ -26,11 +46,68 @@ type WorkspaceAPI interface {
 	GetWorkspaces() ([]Workspace, error)
 }
 
-// The TimeEntryAPI interface provides functions for fetching and creating time entries.
+// The UserAPI interface provides functions for fetching the current
+// user and the changes of its records.
+type UserAPI interface {
+	// GetCurrentUser returns the user the API token belongs to.
+	GetCurrentUser() (User, error)
+
+	ChangesAPI
+}
+
+// The ChangesAPI interface provides a function for fetching the
+// records which have been changed since a point in time.
+type ChangesAPI interface {
+	// GetChanges returns the workspaces, clients, projects and time entries
+	// of the current user which have been changed or deleted since the
+	// given time. A zero time returns all records.
+	GetChanges(since time.Time) (ChangeSet, error)
+}
+
+// The WebhookAPI interface provides functions for creating, fetching,
+// pinging and deleting webhook subscriptions.
+type WebhookAPI interface {
+	// CreateWebhookSubscription creates a new subscription in the workspace of the given subscription.
+	CreateWebhookSubscription(subscription WebhookSubscription) (WebhookSubscription, error)
+
+	// GetWebhookSubscriptions returns all subscriptions of the given workspace.
+	GetWebhookSubscriptions(workspaceID int) ([]WebhookSubscription, error)
+
+	// PingWebhookSubscription asks Toggl to send a ping event to the given subscription.
+	PingWebhookSubscription(workspaceID, subscriptionID int) error
+
+	// DeleteWebhookSubscription deletes the given subscription.
+	DeleteWebhookSubscription(workspaceID, subscriptionID int) error
+}
+
+// The TimeEntryAPI interface provides functions for fetching, creating,
+// updating, deleting and tracking time entries.
 type TimeEntryAPI interface {
 	// CreateTimeEntry creates a new time entry.
 	CreateTimeEntry(timeEntry TimeEntry) (TimeEntry, error)
 
+	// GetTimeEntry returns the time entry with the given ID.
+	GetTimeEntry(id int) (TimeEntry, error)
+
+	// UpdateTimeEntry updates the time entry with the ID of the given time entry.
+	UpdateTimeEntry(timeEntry TimeEntry) (TimeEntry, error)
+
+	// PatchTimeEntry changes only the given fields of the time entry with the given ID.
+	PatchTimeEntry(id int, changes TimeEntryChanges) (TimeEntry, error)
+
+	// DeleteTimeEntry deletes the time entry with the given ID.
+	DeleteTimeEntry(id int) error
+
+	// StartTimeEntry starts a new running time entry.
+	StartTimeEntry(timeEntry TimeEntry) (TimeEntry, error)
+
+	// StopTimeEntry stops the running time entry with the given ID.
+	StopTimeEntry(id int) (TimeEntry, error)
+
+	// GetCurrentTimeEntry returns the currently running time entry.
+	// Returns an empty time entry if no time entry is running.
+	GetCurrentTimeEntry() (TimeEntry, error)
+
 	// GetTimeEntries returns all time entries created between the given start and end date.
 	// Returns nil and an error if the time entries could not be retrieved.
 	GetTimeEntries(start, end time.Time) ([]TimeEntry, error)
@@ -0,0 +1,129 @@
+package model
+
+import "time"
+
+// TimeEntryChanges contains the fields changed by a partial time entry
+// update. Only non-nil fields are sent, so zero values (e.g. a project ID
+// of 0 or an empty list of tags) can be set explicitly.
+type TimeEntryChanges struct {
+	Wid         *int       `json:"wid,omitempty"`
+	Pid         *int       `json:"pid,omitempty"`
+	Start       *time.Time `json:"start,omitempty"`
+	Stop        *time.Time `json:"stop,omitempty"`
+	Duration    *int       `json:"duration,omitempty"`
+	Billable    *bool      `json:"billable,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Tags        *[]string  `json:"tags,omitempty"`
+}
+
+// Apply returns the given time entry with the changes applied. If the
+// start or the stop are changed without a duration, the duration of
+// stopped time entries is calculated from them.
+func (changes TimeEntryChanges) Apply(timeEntry TimeEntry) TimeEntry {
+	if changes.Wid != nil {
+		timeEntry.Wid = *changes.Wid
+	}
+
+	if changes.Pid != nil {
+		timeEntry.Pid = *changes.Pid
+	}
+
+	if changes.Start != nil {
+		timeEntry.Start = *changes.Start
+	}
+
+	if changes.Stop != nil {
+		timeEntry.Stop = *changes.Stop
+	}
+
+	switch {
+	case changes.Duration != nil:
+		timeEntry.Duration = *changes.Duration
+	case (changes.Start != nil || changes.Stop != nil) && !timeEntry.Stop.IsZero():
+		timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+	}
+
+	if changes.Billable != nil {
+		timeEntry.Billable = *changes.Billable
+	}
+
+	if changes.Description != nil {
+		timeEntry.Description = *changes.Description
+	}
+
+	if changes.Tags != nil {
+		timeEntry.Tags = append([]string(nil), *changes.Tags...)
+	}
+
+	return timeEntry
+}
+
+// ProjectChanges contains the fields changed by a partial project update.
+// Only non-nil fields are sent.
+type ProjectChanges struct {
+	ClientID *int    `json:"cid,omitempty"`
+	Name     *string `json:"name,omitempty"`
+}
+
+// Apply returns the given project with the changes applied.
+func (changes ProjectChanges) Apply(project Project) Project {
+	if changes.ClientID != nil {
+		project.ClientID = *changes.ClientID
+	}
+
+	if changes.Name != nil {
+		project.Name = *changes.Name
+	}
+
+	return project
+}
+
+// ClientChanges contains the fields changed by a partial client update.
+// Only non-nil fields are sent.
+type ClientChanges struct {
+	Name  *string `json:"name,omitempty"`
+	Notes *string `json:"notes,omitempty"`
+}
+
+// Apply returns the given client with the changes applied.
+func (changes ClientChanges) Apply(client Client) Client {
+	if changes.Name != nil {
+		client.Name = *changes.Name
+	}
+
+	if changes.Notes != nil {
+		client.Notes = *changes.Notes
+	}
+
+	return client
+}
+
+// Int returns a pointer to the given value for optional fields.
+func Int(value int) *int {
+	return &value
+}
+
+// Bool returns a pointer to the given value for optional fields.
+func Bool(value bool) *bool {
+	return &value
+}
+
+// String returns a pointer to the given value for optional fields.
+func String(value string) *string {
+	return &value
+}
+
+// Time returns a pointer to the given value for optional fields.
+func Time(value time.Time) *time.Time {
+	return &value
+}
+
+// Strings returns a pointer to the given value for optional fields.
+// A nil value is sent as an empty list.
+func Strings(value []string) *[]string {
+	if value == nil {
+		value = []string{}
+	}
+
+	return &value
+}
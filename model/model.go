@@ -5,16 +5,33 @@ import "time"
 
 // Project defines the key properties of a Toggl project
 type Project struct {
-	ID          int    `json:"id"`
-	WorkspaceID int    `json:"wid"`
-	ClientID    int    `json:"cid"`
-	Name        string `json:"name"`
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"wid"`
+	ClientID    int       `json:"cid"`
+	Name        string    `json:"name"`
+	At          time.Time `json:"at"`
 }
 
 // Workspace defines the key properties of a Toggl workspace
 type Workspace struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID   int       `json:"id"`
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+}
+
+// User defines the key properties of a Toggl user
+type User struct {
+	ID       int    `json:"id"`
+	Email    string `json:"email"`
+	Fullname string `json:"fullname"`
+
+	// Timezone contains the IANA name of the time zone of the user (e.g. "Europe/Berlin").
+	Timezone string `json:"timezone"`
+
+	// BeginningOfWeek contains the first day of the week (0 = Sunday).
+	BeginningOfWeek int `json:"beginning_of_week"`
+
+	At time.Time `json:"at"`
 }
 
 // TimeEntry represents a single Toggle time tracking record
@@ -26,6 +43,9 @@ type TimeEntry struct {
 	// Wid contains the workspace ID
 	Wid int `json:"wid"`
 
+	// Uid contains the ID of the user who tracked the time entry
+	Uid int `json:"uid"`
+
 	// Pid contains the project id
 	Pid int `json:"pid"`
 
@@ -35,6 +55,10 @@ type TimeEntry struct {
 	// Stop contains the end time of the entry.
 	Stop time.Time `json:"stop"`
 
+	// Duration contains the duration of the entry in seconds.
+	// Running entries have a negative duration.
+	Duration int `json:"duration"`
+
 	// Billable contains a flag indicating whether this time entry is billable or not.
 	Billable bool `json:"billable"`
 
@@ -45,12 +69,35 @@ type TimeEntry struct {
 	Tags []string `json:"tags"`
 
 	CreatedWith string `json:"created_with"`
+
+	// At contains the time of the last modification.
+	At time.Time `json:"at"`
+}
+
+// ChangeSet contains the records which have been changed
+// or deleted on the server since a point in time.
+type ChangeSet struct {
+	// Since contains the server time of the change set. Pass it to
+	// the next request to get the changes made afterwards.
+	Since time.Time
+
+	Workspaces  []Workspace
+	Clients     []Client
+	Projects    []Project
+	TimeEntries []TimeEntry
+
+	// DeletedClients, DeletedProjects and DeletedTimeEntries
+	// contain the IDs of the records deleted on the server.
+	DeletedClients     []int
+	DeletedProjects    []int
+	DeletedTimeEntries []int
 }
 
 // Client defines the key properties of a Toggl client
 type Client struct {
-	ID          int    `json:"id"`
-	WorkspaceID int    `json:"wid"`
-	Name        string `json:"name"`
-	Notes       string `json:"notes"`
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"wid"`
+	Name        string    `json:"name"`
+	Notes       string    `json:"notes"`
+	At          time.Time `json:"at"`
 }
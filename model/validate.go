@@ -0,0 +1,178 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The limits of the Toggl API.
+const (
+	// MaxNameLength contains the maximum number of characters of project and client names.
+	MaxNameLength = 255
+
+	// MaxDescriptionLength contains the maximum number of characters of time entry descriptions.
+	MaxDescriptionLength = 3000
+
+	// MaxTagLength contains the maximum number of characters of a tag.
+	MaxTagLength = 128
+
+	// MaxDuration contains the maximum duration of a time entry.
+	MaxDuration = 999 * time.Hour
+)
+
+// FieldError describes the problem of a single field.
+type FieldError struct {
+	// Field contains the JSON name of the field (e.g. "wid").
+	Field string
+
+	Message string
+}
+
+// ValidationError lists all problems of an invalid model.
+type ValidationError struct {
+	// Model contains the name of the validated model (e.g. "time entry").
+	Model string
+
+	Fields []FieldError
+}
+
+// Error returns the problems of all fields in a single line.
+func (err *ValidationError) Error() string {
+	var problems []string
+	for _, field := range err.Fields {
+		problems = append(problems, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+
+	return fmt.Sprintf("Invalid %s (%s)", err.Model, strings.Join(problems, "; "))
+}
+
+// add records a problem of the given field.
+func (err *ValidationError) add(field, format string, args ...interface{}) {
+	err.Fields = append(err.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// result returns the validation error or nil if no problems have been recorded.
+func (err *ValidationError) result() error {
+	if len(err.Fields) == 0 {
+		return nil
+	}
+
+	return err
+}
+
+// Validate checks the project before it is created.
+// Returns a *ValidationError listing all problems.
+func (project Project) Validate() error {
+	err := &ValidationError{Model: "project"}
+	if project.WorkspaceID == 0 {
+		err.add("wid", "The workspace is missing")
+	}
+
+	validateName(err, project.Name)
+	return err.result()
+}
+
+// Validate checks the client before it is created.
+// Returns a *ValidationError listing all problems.
+func (client Client) Validate() error {
+	err := &ValidationError{Model: "client"}
+	if client.WorkspaceID == 0 {
+		err.add("wid", "The workspace is missing")
+	}
+
+	validateName(err, client.Name)
+	return err.result()
+}
+
+// Validate checks the time entry before it is created. Time entries without
+// stop time are only valid if they are running (negative duration).
+// Returns a *ValidationError listing all problems.
+func (timeEntry TimeEntry) Validate() error {
+	err := &ValidationError{Model: "time entry"}
+	if timeEntry.Wid == 0 && timeEntry.Pid == 0 {
+		err.add("wid", "The workspace is missing")
+	}
+
+	if timeEntry.Start.IsZero() {
+		err.add("start", "The start is missing")
+	}
+
+	switch {
+	case timeEntry.Stop.IsZero() && timeEntry.Duration >= 0:
+		err.add("stop", "The stop is missing")
+	case timeEntry.Stop.IsZero() || timeEntry.Start.IsZero():
+		// running time entries and missing starts have no duration
+	case timeEntry.Stop.Before(timeEntry.Start):
+		err.add("stop", "The stop (%s) is before the start (%s)", timeEntry.Stop.Format(time.RFC3339), timeEntry.Start.Format(time.RFC3339))
+	case timeEntry.Stop.Sub(timeEntry.Start) > MaxDuration:
+		err.add("duration", "The duration %s exceeds the limit of %s", timeEntry.Stop.Sub(timeEntry.Start), MaxDuration)
+	}
+
+	if length := utf8.RuneCountInString(timeEntry.Description); length > MaxDescriptionLength {
+		err.add("description", "The description has %d characters (limit: %d)", length, MaxDescriptionLength)
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range timeEntry.Tags {
+		switch {
+		case strings.TrimSpace(tag) == "":
+			err.add("tags", "Tags must not be empty")
+		case utf8.RuneCountInString(tag) > MaxTagLength:
+			err.add("tags", "The tag %q exceeds the limit of %d characters", tag, MaxTagLength)
+		case seen[strings.ToLower(tag)]:
+			err.add("tags", "The tag %q is duplicated", tag)
+		}
+
+		seen[strings.ToLower(tag)] = true
+	}
+
+	return err.result()
+}
+
+// Validate checks the webhook subscription before it is created.
+// Returns a *ValidationError listing all problems.
+func (subscription WebhookSubscription) Validate() error {
+	err := &ValidationError{Model: "webhook subscription"}
+	if subscription.WorkspaceID == 0 {
+		err.add("workspace_id", "The workspace is missing")
+	}
+
+	if strings.TrimSpace(subscription.Description) == "" {
+		err.add("description", "The description is missing")
+	}
+
+	callbackURL, urlError := url.Parse(subscription.URLCallback)
+	switch {
+	case subscription.URLCallback == "":
+		err.add("url_callback", "The callback URL is missing")
+	case urlError != nil || (callbackURL.Scheme != "http" && callbackURL.Scheme != "https") || callbackURL.Host == "":
+		err.add("url_callback", "The callback URL %q is not an absolute HTTP(S) URL", subscription.URLCallback)
+	}
+
+	if len(subscription.EventFilters) == 0 {
+		err.add("event_filters", "The event filters are missing")
+	}
+
+	for _, filter := range subscription.EventFilters {
+		if filter.Entity == "" || filter.Action == "" {
+			err.add("event_filters", "The event filter %q/%q needs an entity and an action", filter.Entity, filter.Action)
+		}
+	}
+
+	return err.result()
+}
+
+// validateName records the problems of the given project or client name.
+func validateName(err *ValidationError, name string) {
+	if strings.TrimSpace(name) == "" {
+		err.add("name", "The name is missing")
+		return
+	}
+
+	if length := utf8.RuneCountInString(name); length > MaxNameLength {
+		err.add("name", "The name has %d characters (limit: %d)", length, MaxNameLength)
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_TimeEntry_Validate(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 9, 0, 0, 0, time.UTC)
+
+	inputs := []struct {
+		Name           string
+		TimeEntry      TimeEntry
+		ExpectedFields []string
+	}{
+		{"valid", TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour), Tags: []string{"a", "b"}}, nil},
+		{"project instead of workspace", TimeEntry{Pid: 2, Start: start, Stop: start.Add(time.Hour)}, nil},
+		{"running", TimeEntry{Wid: 1, Start: start, Duration: -int(start.Unix())}, nil},
+		{"empty", TimeEntry{}, []string{"wid", "start", "stop"}},
+		{"stop before start", TimeEntry{Wid: 1, Start: start, Stop: start.Add(-time.Minute)}, []string{"stop"}},
+		{"too long", TimeEntry{Wid: 1, Start: start, Stop: start.Add(MaxDuration + time.Second)}, []string{"duration"}},
+		{"long description", TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour), Description: strings.Repeat("ä", MaxDescriptionLength+1)}, []string{"description"}},
+		{"bad tags", TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour), Tags: []string{" ", "a", "A", strings.Repeat("x", MaxTagLength+1)}}, []string{"tags", "tags", "tags"}},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		err := input.TimeEntry.Validate()
+
+		// assert
+		var fields []string
+		if validationError, ok := err.(*ValidationError); ok {
+			for _, field := range validationError.Fields {
+				fields = append(fields, field.Field)
+			}
+		} else if err != nil {
+			t.Errorf("%s: Validate() returned %T instead of *ValidationError", input.Name, err)
+		}
+
+		if strings.Join(fields, ",") != strings.Join(input.ExpectedFields, ",") {
+			t.Fail()
+			t.Logf("%s: Validate() should have reported %v but reported %v (%v)", input.Name, input.ExpectedFields, fields, err)
+		}
+	}
+}
+
+func Test_Project_Validate_MissingWorkspaceAndName_BothAreReported(t *testing.T) {
+	// act
+	err := Project{Name: "  "}.Validate()
+
+	// assert
+	expected := "Invalid project (wid: The workspace is missing; name: The name is missing)"
+	if err == nil || err.Error() != expected {
+		t.Fail()
+		t.Logf("Validate() should have returned %q but returned %v", expected, err)
+	}
+}
+
+func Test_Client_Validate(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		Client  Client
+		IsValid bool
+	}{
+		{Client{WorkspaceID: 1, Name: "Acme"}, true},
+		{Client{WorkspaceID: 1, Name: strings.Repeat("x", MaxNameLength)}, true},
+		{Client{WorkspaceID: 1, Name: strings.Repeat("x", MaxNameLength+1)}, false},
+		{Client{Name: "Acme"}, false},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		err := input.Client.Validate()
+
+		// assert
+		if (err == nil) != input.IsValid {
+			t.Fail()
+			t.Logf("Validate() of %q (workspace %d) returned %v", input.Client.Name, input.Client.WorkspaceID, err)
+		}
+	}
+}
+
+func Test_WebhookSubscription_Validate(t *testing.T) {
+	// arrange
+	filters := []WebhookEventFilter{{Entity: "time_entry", Action: "*"}}
+
+	inputs := []struct {
+		Name         string
+		Subscription WebhookSubscription
+		Expected     string
+	}{
+		{"valid", WebhookSubscription{WorkspaceID: 1, Description: "sync", URLCallback: "https://example.com/toggl", EventFilters: filters}, ""},
+		{"empty", WebhookSubscription{}, "Invalid webhook subscription (workspace_id: The workspace is missing; description: The description is missing; url_callback: The callback URL is missing; event_filters: The event filters are missing)"},
+		{"relative URL", WebhookSubscription{WorkspaceID: 1, Description: "sync", URLCallback: "/toggl", EventFilters: filters}, `Invalid webhook subscription (url_callback: The callback URL "/toggl" is not an absolute HTTP(S) URL)`},
+		{"incomplete filter", WebhookSubscription{WorkspaceID: 1, Description: "sync", URLCallback: "http://example.com", EventFilters: []WebhookEventFilter{{Entity: "tag"}}}, `Invalid webhook subscription (event_filters: The event filter "tag"/"" needs an entity and an action)`},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		err := input.Subscription.Validate()
+
+		// assert
+		var message string
+		if err != nil {
+			message = err.Error()
+		}
+
+		if message != input.Expected {
+			t.Errorf("%s: Validate() should have returned %q but returned %q", input.Name, input.Expected, message)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Tag defines the key properties of a Toggl tag
+type Tag struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"workspace_id"`
+	Name        string    `json:"name"`
+	At          time.Time `json:"at"`
+}
+
+// WebhookEventFilter selects the events a webhook subscription receives.
+// An asterisk matches all entities or actions.
+type WebhookEventFilter struct {
+	// Entity contains the kind of the record (e.g. "time_entry", "project", "client", "tag" or "*").
+	Entity string `json:"entity"`
+
+	// Action contains the kind of the change (e.g. "created", "updated", "deleted" or "*").
+	Action string `json:"action"`
+}
+
+// WebhookSubscription defines the key properties of a Toggl webhook subscription
+type WebhookSubscription struct {
+	ID          int    `json:"subscription_id,omitempty"`
+	WorkspaceID int    `json:"workspace_id"`
+	UserID      int    `json:"user_id,omitempty"`
+	Description string `json:"description"`
+
+	// URLCallback contains the URL the events are sent to.
+	URLCallback string `json:"url_callback"`
+
+	// Secret is used to sign the events. Toggl generates one if it is empty.
+	Secret string `json:"secret,omitempty"`
+
+	Enabled      bool                 `json:"enabled"`
+	EventFilters []WebhookEventFilter `json:"event_filters"`
+
+	// ValidatedAt contains the time the callback URL has been validated.
+	// Toggl only sends events to validated subscriptions.
+	ValidatedAt *time.Time `json:"validated_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
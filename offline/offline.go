@@ -0,0 +1,474 @@
+// Package offline provides a time entry API which keeps working without
+// network connectivity.
+//
+// Creates, updates, patches and deletes which fail because the Toggl API cannot be
+// reached are persisted in a queue and replayed in order as soon as the
+// Toggl API can be reached again. Time entries created while offline get
+// negative temporary IDs which are mapped to the server IDs once the
+// queued operations have been replayed.
+package offline
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// The kinds of queued operations.
+const (
+	Create = "create"
+	Update = "update"
+	Patch  = "patch"
+	Delete = "delete"
+)
+
+// queueKey contains the store key of the queue.
+const queueKey = "offline/queue"
+
+// Operation contains a queued create, update, patch or delete call.
+type Operation struct {
+	Kind      string          `json:"kind"`
+	TimeEntry model.TimeEntry `json:"time_entry"`
+	QueuedAt  time.Time       `json:"queued_at"`
+
+	// Changes contains the changed fields of a patch.
+	Changes *model.TimeEntryChanges `json:"changes,omitempty"`
+}
+
+// Conflict contains a queued operation which has
+// been rejected by the Toggl API during replay.
+type Conflict struct {
+	Operation Operation `json:"operation"`
+	Error     string    `json:"error"`
+}
+
+// SyncResult contains the outcome of replaying the queue.
+type SyncResult struct {
+	// Replayed contains the number of successfully replayed operations.
+	Replayed int
+
+	// Pending contains the number of operations which are still queued.
+	Pending int
+
+	// Conflicts contains the operations rejected by the Toggl API.
+	Conflicts []Conflict
+}
+
+// queue contains the persisted state of the offline API.
+type queue struct {
+	Operations []Operation `json:"operations"`
+
+	// IDs maps temporary IDs to server IDs.
+	IDs map[int]int `json:"ids"`
+
+	// Conflicts contains the conflicts of automatic replays
+	// which have not been cleared yet.
+	Conflicts []Conflict `json:"conflicts"`
+
+	LastTemporaryID int `json:"last_temporary_id"`
+}
+
+// IsOffline returns true if the given error indicates that the
+// Toggl API could not be reached (e.g. DNS or connection errors).
+func IsOffline(err error) bool {
+	_, isNetworkError := errors.Cause(err).(net.Error)
+	return isNetworkError
+}
+
+// New creates a new time entry API which queues the writes to the
+// given API in the given store while the Toggl API cannot be reached.
+// The store must not be shared with a cache which might be cleared.
+func New(api model.TimeEntryAPI, store cache.Store) *TimeEntryAPI {
+	return &TimeEntryAPI{
+		api:       api,
+		store:     store,
+		isOffline: IsOffline,
+		now:       time.Now,
+	}
+}
+
+// TimeEntryAPI is a model.TimeEntryAPI which queues writes while offline.
+type TimeEntryAPI struct {
+	api       model.TimeEntryAPI
+	store     cache.Store
+	isOffline func(err error) bool
+	now       func() time.Time
+
+	mutex sync.Mutex
+}
+
+// CreateTimeEntry creates a new time entry. If the Toggl API cannot be
+// reached the time entry is queued and returned with a temporary ID.
+func (api *TimeEntryAPI) CreateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, online, loadError := api.prepareWrite()
+	if loadError != nil {
+		return model.TimeEntry{}, loadError
+	}
+
+	if online {
+		createdTimeEntry, createError := api.api.CreateTimeEntry(timeEntry)
+		if createError == nil || !api.isOffline(createError) {
+			return createdTimeEntry, createError
+		}
+	}
+
+	state.LastTemporaryID--
+	timeEntry.ID = state.LastTemporaryID
+	timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+
+	return timeEntry, api.enqueue(state, Operation{Kind: Create, TimeEntry: timeEntry})
+}
+
+// UpdateTimeEntry updates the given time entry. If the Toggl API cannot
+// be reached the update is queued and the given time entry is returned.
+func (api *TimeEntryAPI) UpdateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, online, loadError := api.prepareWrite()
+	if loadError != nil {
+		return model.TimeEntry{}, loadError
+	}
+
+	if online {
+		serverTimeEntry := timeEntry
+		serverTimeEntry.ID = state.serverID(timeEntry.ID)
+
+		updatedTimeEntry, updateError := api.api.UpdateTimeEntry(serverTimeEntry)
+		if updateError == nil || !api.isOffline(updateError) {
+			return updatedTimeEntry, updateError
+		}
+	}
+
+	return timeEntry, api.enqueue(state, Operation{Kind: Update, TimeEntry: timeEntry})
+}
+
+// PatchTimeEntry changes the given fields of a time entry. If the Toggl
+// API cannot be reached the changes are queued and returned applied to
+// the queued time entry or to an otherwise empty time entry.
+func (api *TimeEntryAPI) PatchTimeEntry(id int, changes model.TimeEntryChanges) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, online, loadError := api.prepareWrite()
+	if loadError != nil {
+		return model.TimeEntry{}, loadError
+	}
+
+	if online {
+		patchedTimeEntry, patchError := api.api.PatchTimeEntry(state.serverID(id), changes)
+		if patchError == nil || !api.isOffline(patchError) {
+			return patchedTimeEntry, patchError
+		}
+	}
+
+	timeEntry := state.queued(id)
+	if timeEntry.ID == 0 {
+		timeEntry.ID = id
+	}
+
+	timeEntry = changes.Apply(timeEntry)
+	return timeEntry, api.enqueue(state, Operation{Kind: Patch, TimeEntry: timeEntry, Changes: &changes})
+}
+
+// DeleteTimeEntry deletes the time entry with the given ID. If the
+// Toggl API cannot be reached the deletion is queued.
+func (api *TimeEntryAPI) DeleteTimeEntry(id int) error {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, online, loadError := api.prepareWrite()
+	if loadError != nil {
+		return loadError
+	}
+
+	if online {
+		deleteError := api.api.DeleteTimeEntry(state.serverID(id))
+		if deleteError == nil || !api.isOffline(deleteError) {
+			return deleteError
+		}
+	}
+
+	return api.enqueue(state, Operation{Kind: Delete, TimeEntry: model.TimeEntry{ID: id}})
+}
+
+// GetTimeEntry returns the time entry with the given ID. Queued time
+// entries with temporary IDs are returned from the queue.
+func (api *TimeEntryAPI) GetTimeEntry(id int) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	state, loadError := api.load()
+	api.mutex.Unlock()
+
+	if loadError != nil {
+		return model.TimeEntry{}, loadError
+	}
+
+	if serverID := state.serverID(id); serverID > 0 {
+		return api.api.GetTimeEntry(serverID)
+	}
+
+	timeEntry := state.queued(id)
+	if timeEntry.ID == 0 {
+		return model.TimeEntry{}, fmt.Errorf("The queued time entry %d does not exist", id)
+	}
+
+	return timeEntry, nil
+}
+
+// StartTimeEntry starts a new running time entry.
+// Starting time entries requires connectivity.
+func (api *TimeEntryAPI) StartTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	return api.api.StartTimeEntry(timeEntry)
+}
+
+// StopTimeEntry stops the running time entry with the given ID.
+// Stopping time entries requires connectivity.
+func (api *TimeEntryAPI) StopTimeEntry(id int) (model.TimeEntry, error) {
+	return api.api.StopTimeEntry(id)
+}
+
+// GetCurrentTimeEntry returns the currently running time entry.
+func (api *TimeEntryAPI) GetCurrentTimeEntry() (model.TimeEntry, error) {
+	return api.api.GetCurrentTimeEntry()
+}
+
+// GetTimeEntries returns all time entries created between the given start
+// and end date. Queued operations are not reflected in the result.
+func (api *TimeEntryAPI) GetTimeEntries(start, end time.Time) ([]model.TimeEntry, error) {
+	return api.api.GetTimeEntries(start, end)
+}
+
+// Pending returns the queued operations.
+func (api *TimeEntryAPI) Pending() ([]Operation, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, loadError := api.load()
+	if loadError != nil {
+		return nil, loadError
+	}
+
+	return state.Operations, nil
+}
+
+// ServerID returns the server ID of the time entry with the given temporary ID.
+// Returns false if the time entry has not been replayed yet.
+func (api *TimeEntryAPI) ServerID(temporaryID int) (int, bool, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, loadError := api.load()
+	if loadError != nil {
+		return 0, false, loadError
+	}
+
+	serverID, exists := state.IDs[temporaryID]
+	return serverID, exists, nil
+}
+
+// Conflicts returns the operations which have been rejected
+// by the Toggl API during automatic replays.
+func (api *TimeEntryAPI) Conflicts() ([]Conflict, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, loadError := api.load()
+	if loadError != nil {
+		return nil, loadError
+	}
+
+	return state.Conflicts, nil
+}
+
+// ClearConflicts removes all recorded conflicts.
+func (api *TimeEntryAPI) ClearConflicts() error {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, loadError := api.load()
+	if loadError != nil {
+		return loadError
+	}
+
+	state.Conflicts = nil
+	return api.store.Set(queueKey, state)
+}
+
+// Sync replays the queued operations in order. Replaying stops at the
+// first operation which fails because the Toggl API cannot be reached.
+// Operations rejected by the Toggl API are removed from the queue and
+// reported as conflicts.
+func (api *TimeEntryAPI) Sync() (SyncResult, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	state, loadError := api.load()
+	if loadError != nil {
+		return SyncResult{}, loadError
+	}
+
+	result, replayError := api.replay(state)
+	if replayError != nil {
+		return result, replayError
+	}
+
+	return result, api.store.Set(queueKey, state)
+}
+
+// prepareWrite loads the queue and replays pending operations.
+// Returns false if operations are still pending which means
+// new writes must be queued to preserve their order.
+func (api *TimeEntryAPI) prepareWrite() (*queue, bool, error) {
+	state, loadError := api.load()
+	if loadError != nil {
+		return nil, false, loadError
+	}
+
+	if len(state.Operations) == 0 {
+		return state, true, nil
+	}
+
+	result, replayError := api.replay(state)
+	if replayError != nil {
+		return nil, false, replayError
+	}
+
+	state.Conflicts = append(state.Conflicts, result.Conflicts...)
+	if err := api.store.Set(queueKey, state); err != nil {
+		return nil, false, err
+	}
+
+	return state, result.Pending == 0, nil
+}
+
+// replay sends the queued operations of the given queue to the Toggl API
+// and removes the replayed operations from the queue.
+func (api *TimeEntryAPI) replay(state *queue) (SyncResult, error) {
+	var result SyncResult
+
+	for len(state.Operations) > 0 {
+		operation := state.Operations[0]
+
+		timeEntry := operation.TimeEntry
+		timeEntry.ID = state.serverID(timeEntry.ID)
+
+		var err error
+		switch operation.Kind {
+		case Create:
+			timeEntry.ID = 0
+
+			var createdTimeEntry model.TimeEntry
+			createdTimeEntry, err = api.api.CreateTimeEntry(timeEntry)
+			if err == nil {
+				state.IDs[operation.TimeEntry.ID] = createdTimeEntry.ID
+			}
+
+		case Update:
+			if timeEntry.ID < 0 {
+				err = fmt.Errorf("The time entry %d has not been created", operation.TimeEntry.ID)
+				break
+			}
+
+			_, err = api.api.UpdateTimeEntry(timeEntry)
+
+		case Patch:
+			if timeEntry.ID < 0 {
+				err = fmt.Errorf("The time entry %d has not been created", operation.TimeEntry.ID)
+				break
+			}
+
+			if operation.Changes == nil {
+				err = fmt.Errorf("The patch of time entry %d has no changes", operation.TimeEntry.ID)
+				break
+			}
+
+			_, err = api.api.PatchTimeEntry(timeEntry.ID, *operation.Changes)
+
+		case Delete:
+			if timeEntry.ID < 0 {
+				err = fmt.Errorf("The time entry %d has not been created", operation.TimeEntry.ID)
+				break
+			}
+
+			err = api.api.DeleteTimeEntry(timeEntry.ID)
+
+		default:
+			err = fmt.Errorf("Unknown operation %q", operation.Kind)
+		}
+
+		if err != nil && api.isOffline(err) {
+			break
+		}
+
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, Conflict{operation, err.Error()})
+		} else {
+			result.Replayed++
+		}
+
+		state.Operations = state.Operations[1:]
+	}
+
+	result.Pending = len(state.Operations)
+	return result, nil
+}
+
+// enqueue appends the given operation to the given queue and persists the queue.
+func (api *TimeEntryAPI) enqueue(state *queue, operation Operation) error {
+	operation.QueuedAt = api.now()
+	state.Operations = append(state.Operations, operation)
+
+	return errors.Wrap(api.store.Set(queueKey, state), "Failed to persist the offline queue")
+}
+
+// load reads the queue from the store.
+func (api *TimeEntryAPI) load() (*queue, error) {
+	state := &queue{}
+	if _, _, err := api.store.Get(queueKey, state); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the offline queue")
+	}
+
+	if state.IDs == nil {
+		state.IDs = make(map[int]int)
+	}
+
+	return state, nil
+}
+
+// serverID returns the server ID of the given ID. Temporary IDs
+// which have not been replayed yet are returned unchanged.
+func (state *queue) serverID(id int) int {
+	if serverID, exists := state.IDs[id]; exists {
+		return serverID
+	}
+
+	return id
+}
+
+// queued returns the latest queued version of the time entry with the
+// given ID. Returns an empty time entry if it is not queued or deleted.
+func (state *queue) queued(id int) model.TimeEntry {
+	var timeEntry model.TimeEntry
+	for _, operation := range state.Operations {
+		if operation.TimeEntry.ID != id {
+			continue
+		}
+
+		switch operation.Kind {
+		case Create, Update, Patch:
+			timeEntry = operation.TimeEntry
+		case Delete:
+			timeEntry = model.TimeEntry{}
+		}
+	}
+
+	return timeEntry
+}
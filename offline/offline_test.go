@@ -0,0 +1,267 @@
+package offline
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+	"github.com/pkg/errors"
+)
+
+// the offline API must implement the time entry API
+var _ model.TimeEntryAPI = &TimeEntryAPI{}
+
+// networkError is returned by the test API while it is offline.
+var networkError = errors.Wrap(&net.DNSError{Err: "no such host", Name: "www.toggl.com"}, "Failed to create time entry")
+
+// newTestAPI creates an offline API for an in-memory API using a
+// file store in a temporary directory.
+func newTestAPI(t *testing.T) (*TimeEntryAPI, *togglapitest.API, cache.Store) {
+	directory, err := ioutil.TempDir("", "togglapi-offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(directory) })
+
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	store := cache.NewFileStore(filepath.Join(directory, "queue.json"))
+	return New(api, store), api, store
+}
+
+// goOffline makes all write calls of the given API fail with a network error.
+func goOffline(api *togglapitest.API) {
+	for _, method := range []string{"CreateTimeEntry", "UpdateTimeEntry", "PatchTimeEntry", "DeleteTimeEntry"} {
+		api.Errors[method] = networkError
+	}
+}
+
+// goOnline removes the network errors of the given API.
+func goOnline(api *togglapitest.API) {
+	for method := range api.Errors {
+		delete(api.Errors, method)
+	}
+}
+
+// testTimeEntry returns a time entry for testing.
+func testTimeEntry(description string) model.TimeEntry {
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	return model.TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour), Description: description}
+}
+
+func Test_IsOffline(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		Error          error
+		ExpectedResult bool
+	}{
+		{networkError, true},
+		{&net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}, true},
+		{fmt.Errorf("The POST request against time_entries failed (400 Bad Request)"), false},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result := IsOffline(input.Error)
+
+		// assert
+		if result != input.ExpectedResult {
+			t.Fail()
+			t.Logf("IsOffline(%q) should have returned %t", input.Error, input.ExpectedResult)
+		}
+	}
+}
+
+func Test_CreateTimeEntry_Online_TimeEntryIsCreated(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+
+	// act
+	created, err := offlineAPI.CreateTimeEntry(testTimeEntry("Online"))
+
+	// assert
+	if err != nil || created.ID <= 0 || len(api.TimeEntries) != 1 {
+		t.Fail()
+		t.Logf("CreateTimeEntry should have created the time entry directly (Error: %v)", err)
+	}
+}
+
+func Test_CreateTimeEntry_Offline_TemporaryIDIsReturnedAndOperationIsQueued(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	goOffline(api)
+
+	// act
+	first, firstError := offlineAPI.CreateTimeEntry(testTimeEntry("Train 1"))
+	second, secondError := offlineAPI.CreateTimeEntry(testTimeEntry("Train 2"))
+	pending, _ := offlineAPI.Pending()
+
+	// assert
+	if firstError != nil || secondError != nil || first.ID != -1 || second.ID != -2 || len(pending) != 2 {
+		t.Fail()
+		t.Logf("CreateTimeEntry should have queued both time entries with temporary IDs (IDs: %d, %d)", first.ID, second.ID)
+	}
+}
+
+func Test_CreateTimeEntry_ServerRejectsTimeEntry_ErrorIsReturned(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	api.Errors["CreateTimeEntry"] = fmt.Errorf("400 Bad Request")
+
+	// act
+	_, err := offlineAPI.CreateTimeEntry(testTimeEntry("Invalid"))
+	pending, _ := offlineAPI.Pending()
+
+	// assert
+	if err == nil || len(pending) != 0 {
+		t.Fail()
+		t.Logf("CreateTimeEntry should return errors which are not caused by missing connectivity")
+	}
+}
+
+func Test_Sync_QueuedOperationsAreReplayedInOrder(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	goOffline(api)
+
+	created, _ := offlineAPI.CreateTimeEntry(testTimeEntry("Draft"))
+	created.Description = "Final"
+	offlineAPI.UpdateTimeEntry(created)
+	deleted, _ := offlineAPI.CreateTimeEntry(testTimeEntry("Mistake"))
+	offlineAPI.DeleteTimeEntry(deleted.ID)
+
+	goOnline(api)
+
+	// act
+	result, err := offlineAPI.Sync()
+
+	// assert
+	if err != nil || result.Replayed != 4 || result.Pending != 0 || len(result.Conflicts) != 0 {
+		t.Fatalf("Sync should have replayed all operations but returned %#v (Error: %v)", result, err)
+	}
+
+	serverID, exists, _ := offlineAPI.ServerID(created.ID)
+	if !exists || len(api.TimeEntries) != 1 || api.TimeEntries[0].ID != serverID || api.TimeEntries[0].Description != "Final" {
+		t.Fail()
+		t.Logf("The server should contain the updated time entry only: %#v", api.TimeEntries)
+	}
+}
+
+func Test_PatchTimeEntry_Offline_ChangesAreQueuedAndReplayed(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	existing, _ := api.CreateTimeEntry(testTimeEntry("Review"))
+	goOffline(api)
+
+	created, _ := offlineAPI.CreateTimeEntry(testTimeEntry("Draft"))
+
+	// act
+	patchedQueued, queuedError := offlineAPI.PatchTimeEntry(created.ID, model.TimeEntryChanges{Description: model.String("Final")})
+	patchedExisting, existingError := offlineAPI.PatchTimeEntry(existing.ID, model.TimeEntryChanges{Tags: model.Strings([]string{"invoiced"})})
+
+	goOnline(api)
+	result, syncError := offlineAPI.Sync()
+
+	// assert
+	if queuedError != nil || existingError != nil || patchedQueued.Description != "Final" || patchedQueued.Wid != 1 || patchedExisting.ID != existing.ID {
+		t.Fatalf("PatchTimeEntry should have applied the changes to the queued time entries (%#v, %#v)", patchedQueued, patchedExisting)
+	}
+
+	if syncError != nil || result.Replayed != 3 || len(api.TimeEntries) != 2 ||
+		api.TimeEntries[0].Description != "Review" || fmt.Sprint(api.TimeEntries[0].Tags) != "[invoiced]" ||
+		api.TimeEntries[1].Description != "Final" {
+		t.Fail()
+		t.Logf("Sync should have replayed the patches (Result: %#v, Error: %v): %#v", result, syncError, api.TimeEntries)
+	}
+}
+
+func Test_Sync_StillOffline_OperationsStayQueued(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	goOffline(api)
+	offlineAPI.CreateTimeEntry(testTimeEntry("Train"))
+
+	// act
+	result, err := offlineAPI.Sync()
+
+	// assert
+	if err != nil || result.Replayed != 0 || result.Pending != 1 {
+		t.Fail()
+		t.Logf("Sync should keep the operations queued while offline: %#v (Error: %v)", result, err)
+	}
+}
+
+func Test_Sync_ServerRejectsOperation_ConflictIsReported(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	goOffline(api)
+	offlineAPI.DeleteTimeEntry(4711)
+	offlineAPI.CreateTimeEntry(testTimeEntry("Train"))
+	goOnline(api)
+
+	// act
+	result, err := offlineAPI.Sync()
+
+	// assert
+	if err != nil || result.Replayed != 1 || len(result.Conflicts) != 1 || result.Conflicts[0].Operation.Kind != Delete {
+		t.Fail()
+		t.Logf("Sync should have reported the rejected deletion as a conflict: %#v (Error: %v)", result, err)
+	}
+}
+
+func Test_CreateTimeEntry_BackOnline_QueueIsReplayedFirst(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	goOffline(api)
+	offlineAPI.CreateTimeEntry(testTimeEntry("First"))
+	goOnline(api)
+
+	// act
+	created, err := offlineAPI.CreateTimeEntry(testTimeEntry("Second"))
+
+	// assert
+	if err != nil || created.ID <= 0 || len(api.TimeEntries) != 2 || api.TimeEntries[0].Description != "First" {
+		t.Fail()
+		t.Logf("CreateTimeEntry should have replayed the queue before creating the new time entry (Error: %v)", err)
+	}
+}
+
+func Test_GetTimeEntry_QueuedTimeEntry_TimeEntryIsReturnedFromQueue(t *testing.T) {
+	// arrange
+	offlineAPI, api, _ := newTestAPI(t)
+	goOffline(api)
+	created, _ := offlineAPI.CreateTimeEntry(testTimeEntry("Train"))
+
+	// act
+	timeEntry, err := offlineAPI.GetTimeEntry(created.ID)
+
+	// assert
+	if err != nil || timeEntry.Description != "Train" {
+		t.Fail()
+		t.Logf("GetTimeEntry should have returned the queued time entry (Error: %v)", err)
+	}
+}
+
+func Test_New_QueueIsPersisted(t *testing.T) {
+	// arrange
+	offlineAPI, api, store := newTestAPI(t)
+	goOffline(api)
+	offlineAPI.CreateTimeEntry(testTimeEntry("Train"))
+
+	// act
+	pending, err := New(api, store).Pending()
+
+	// assert
+	if err != nil || len(pending) != 1 {
+		t.Fail()
+		t.Logf("The queue should have been read from the store (Error: %v)", err)
+	}
+}
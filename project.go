@@ -27,33 +27,73 @@ type ProjectAPI struct {
 }
 
 // CreateProject creates a new project.
+// Returns a *model.ValidationError without sending a request if the project is invalid.
 func (repository *ProjectAPI) CreateProject(project model.Project) (model.Project, error) {
+	if err := project.Validate(); err != nil {
+		return model.Project{}, err
+	}
 
-	projectRequest := struct {
-		Project model.Project `json:"project"`
+	projectModel := struct {
+		WorkspaceID int    `json:"wid"`
+		ClientID    int    `json:"cid,omitempty"`
+		Name        string `json:"name"`
 	}{
-		Project: project,
+		WorkspaceID: project.WorkspaceID,
+		ClientID:    project.ClientID,
+		Name:        project.Name,
 	}
 
-	jsonBody, marshalError := json.Marshal(projectRequest)
-	if marshalError != nil {
-		return model.Project{}, errors.Wrap(marshalError, "Failed to serialize the project")
+	content, err := repository.sendProject(http.MethodPost, "projects", projectModel)
+	if err != nil {
+		return model.Project{}, errors.Wrap(err, "Failed to create project")
+	}
+
+	return repository.decodeProject(content, "created")
+}
+
+// UpdateProject replaces the project with the ID of the given project.
+// An unset client removes it; use PatchProject to change single fields.
+func (repository *ProjectAPI) UpdateProject(project model.Project) (model.Project, error) {
+
+	projectModel := struct {
+		WorkspaceID int    `json:"wid,omitempty"`
+		ClientID    int    `json:"cid"`
+		Name        string `json:"name"`
+	}{
+		WorkspaceID: project.WorkspaceID,
+		ClientID:    project.ClientID,
+		Name:        project.Name,
 	}
 
-	content, err := repository.restClient.Request(http.MethodPost, "projects", bytes.NewBuffer(jsonBody))
+	route := fmt.Sprintf("projects/%d", project.ID)
+	content, err := repository.sendProject(http.MethodPut, route, projectModel)
 	if err != nil {
-		return model.Project{}, errors.Wrap(err, "Failed to create project")
+		return model.Project{}, errors.Wrap(err, fmt.Sprintf("Failed to update project %d", project.ID))
 	}
 
-	var projectResponse struct {
-		Project model.Project `json:"data"`
+	return repository.decodeProject(content, "updated")
+}
+
+// PatchProject changes only the given fields of the project with the given ID.
+func (repository *ProjectAPI) PatchProject(id int, changes model.ProjectChanges) (model.Project, error) {
+	route := fmt.Sprintf("projects/%d", id)
+	content, err := repository.sendProject(http.MethodPut, route, changes)
+	if err != nil {
+		return model.Project{}, errors.Wrap(err, fmt.Sprintf("Failed to update project %d", id))
 	}
 
-	if unmarshalError := json.Unmarshal(content, &projectResponse); unmarshalError != nil {
-		return model.Project{}, errors.Wrap(unmarshalError, "Failed to deserialize the created project")
+	return repository.decodeProject(content, "updated")
+}
+
+// DeleteProject deletes the project with the given ID.
+func (repository *ProjectAPI) DeleteProject(id int) error {
+	route := fmt.Sprintf("projects/%d", id)
+
+	if _, err := repository.restClient.Request(http.MethodDelete, route, nil); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to delete project %d", id))
 	}
 
-	return projectResponse.Project, nil
+	return nil
 }
 
 // GetProjects returns all projects for the given workspace.
@@ -76,3 +116,34 @@ func (repository *ProjectAPI) GetProjects(workspaceID int) ([]model.Project, err
 
 	return projects, nil
 }
+
+// sendProject wraps the given project model into a project request
+// and sends it to the given route.
+func (repository *ProjectAPI) sendProject(method, route string, projectModel interface{}) ([]byte, error) {
+	projectRequest := struct {
+		Project interface{} `json:"project"`
+	}{
+		Project: projectModel,
+	}
+
+	jsonBody, marshalError := json.Marshal(projectRequest)
+	if marshalError != nil {
+		return nil, errors.Wrap(marshalError, "Failed to serialize the project")
+	}
+
+	return repository.restClient.Request(method, route, bytes.NewBuffer(jsonBody))
+}
+
+// decodeProject deserializes the project contained in the data attribute
+// of the given API response. The action (e.g. "created") is used in errors.
+func (repository *ProjectAPI) decodeProject(content []byte, action string) (model.Project, error) {
+	var projectResponse struct {
+		Project model.Project `json:"data"`
+	}
+
+	if unmarshalError := json.Unmarshal(content, &projectResponse); unmarshalError != nil {
+		return model.Project{}, errors.Wrap(unmarshalError, fmt.Sprintf("Failed to deserialize the %s project", action))
+	}
+
+	return projectResponse.Project, nil
+}
@@ -3,6 +3,7 @@ package togglapi
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
 	"testing"
 
@@ -21,7 +22,7 @@ func Test_CreateProject_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Project{}
+	input := model.Project{WorkspaceID: 1, Name: "Meetings"}
 
 	// act
 	_, err := projectAPI.CreateProject(input)
@@ -47,7 +48,7 @@ func Test_CreateProject_InvalidJSONIsReturned_ErrorIsReturned(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Project{}
+	input := model.Project{WorkspaceID: 1, Name: "Meetings"}
 
 	// act
 	_, err := projectAPI.CreateProject(input)
@@ -73,7 +74,7 @@ func Test_CreateProject_NoProjectsReturned_EmptyProjectIsReturned(t *testing.T)
 		restClient: restClient,
 	}
 
-	input := model.Project{}
+	input := model.Project{WorkspaceID: 1, Name: "Meetings"}
 
 	// act
 	_, err := projectAPI.CreateProject(input)
@@ -103,7 +104,7 @@ func Test_CreateProject_HTTPMethodIsPOST(t *testing.T) {
 		restClient: restClient,
 	}
 
-	input := model.Project{}
+	input := model.Project{WorkspaceID: 1, Name: "Meetings"}
 
 	// act
 	projectAPI.CreateProject(input)
@@ -139,7 +140,7 @@ func Test_CreateProject_ValidJSONIsReturned_CreatedProjectIsReturned(t *testing.
 		restClient: restClient,
 	}
 
-	input := model.Project{}
+	input := model.Project{WorkspaceID: 1, Name: "Meetings"}
 
 	// act
 	project, err := projectAPI.CreateProject(input)
@@ -155,3 +156,52 @@ func Test_CreateProject_ValidJSONIsReturned_CreatedProjectIsReturned(t *testing.
 		}
 	}
 }
+
+func Test_CreateProject_InvalidProject_NoRequestIsSent(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			t.Errorf("CreateProject should not send a request for an invalid project")
+			return nil, nil
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := projectAPI.CreateProject(model.Project{WorkspaceID: 1})
+
+	// assert
+	if _, ok := err.(*model.ValidationError); !ok {
+		t.Fail()
+		t.Logf("CreateProject should have returned a validation error but returned %v", err)
+	}
+}
+
+func Test_CreateProject_UnsetFieldsAreOmitted(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 1}}`), nil
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	projectAPI.CreateProject(model.Project{WorkspaceID: 1, Name: "Meetings"})
+
+	// assert
+	expected := `{"project":{"wid":1,"name":"Meetings"}}`
+	if body != expected {
+		t.Fail()
+		t.Logf("CreateProject should have sent %s but sent %s", expected, body)
+	}
+}
@@ -0,0 +1,140 @@
+package togglapi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_UpdateProject_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := projectAPI.UpdateProject(model.Project{ID: 1})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("UpdateProject should return an error if the REST client returned an error")
+	}
+}
+
+func Test_UpdateProject_PUTRequestIsSentToClientRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "PUT" || route != "projects/1239455" {
+				t.Fail()
+				t.Logf("UpdateProject should send a PUT request to projects/1239455 but sent %s %s", method, route)
+			}
+
+			return []byte(`{"data": {"id": 1239455, "name": "Website Redesign"}}`), nil
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	project, err := projectAPI.UpdateProject(model.Project{ID: 1239455, Name: "Website Redesign"})
+
+	// assert
+	if err != nil || project.Name != "Website Redesign" {
+		t.Fail()
+		t.Logf("UpdateProject should have returned the updated project (Error: %v)", err)
+	}
+}
+
+func Test_UpdateProject_UnsetClientIsCleared(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 7}}`), nil
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	projectAPI.UpdateProject(model.Project{ID: 7, WorkspaceID: 1, Name: "Meetings"})
+
+	// assert
+	expected := `{"project":{"wid":1,"cid":0,"name":"Meetings"}}`
+	if body != expected {
+		t.Fail()
+		t.Logf("UpdateProject should have sent %s but sent %s", expected, body)
+	}
+}
+
+func Test_DeleteProject_DELETERequestIsSentToClientRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "DELETE" || route != "projects/1239455" {
+				t.Fail()
+				t.Logf("DeleteProject should send a DELETE request to projects/1239455 but sent %s %s", method, route)
+			}
+
+			return nil, nil
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	err := projectAPI.DeleteProject(1239455)
+
+	// assert
+	if err != nil {
+		t.Fail()
+		t.Logf("DeleteProject should not have returned an error: %s", err)
+	}
+}
+
+func Test_PatchProject_OnlyChangedFieldsAreSent(t *testing.T) {
+	// arrange
+	var route, body string
+	restClient := &mockRESTRequester{
+		request: func(method, requestRoute string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			route, body = requestRoute, string(content)
+			return []byte(`{"data": {"id": 7, "name": "Meetings"}}`), nil
+		},
+	}
+
+	projectAPI := &ProjectAPI{
+		restClient: restClient,
+	}
+
+	// act
+	project, err := projectAPI.PatchProject(7, model.ProjectChanges{ClientID: model.Int(0)})
+
+	// assert
+	expected := `{"project":{"cid":0}}`
+	if err != nil || route != "projects/7" || body != expected || project.Name != "Meetings" {
+		t.Fail()
+		t.Logf("PatchProject should have sent %s to projects/7 but sent %s to %s (%v)", expected, body, route, err)
+	}
+}
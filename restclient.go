@@ -18,12 +18,43 @@ type RESTRequester interface {
 	Request(method, route string, payload io.Reader) ([]byte, error)
 }
 
+// A RequestObserver is notified about the requests of a REST requester
+// (e.g. for collecting metrics).
+type RequestObserver interface {
+	// ObserveRequest receives the method and route of a finished request,
+	// its duration, the time waited for the rate limit before it and its error.
+	ObserveRequest(method, route string, duration, wait time.Duration, err error)
+}
+
+// NewRESTRequester creates a RESTRequester which sends the requests to the
+// Toggl API with the given base URL and token. It pauses between requests
+// to respect the rate limit of the Toggl API.
+func NewRESTRequester(baseURL, token string) RESTRequester {
+	return &togglRESTAPIClient{
+		baseURL:              baseURL,
+		token:                token,
+		pauseBetweenRequests: pauseBetweenRequests,
+	}
+}
+
+// NewObservedRESTRequester creates a RESTRequester like NewRESTRequester
+// which reports every request to the given observer.
+func NewObservedRESTRequester(baseURL, token string, observer RequestObserver) RESTRequester {
+	return &togglRESTAPIClient{
+		baseURL:              baseURL,
+		token:                token,
+		pauseBetweenRequests: pauseBetweenRequests,
+		observer:             observer,
+	}
+}
+
 // The togglRESTAPIClient perform the HTTP requests against the Toggl API and
 // returns the APIs' response.
 type togglRESTAPIClient struct {
 	baseURL              string
 	token                string
 	pauseBetweenRequests time.Duration // e.g. time.Millisecond * 1000
+	observer             RequestObserver
 
 	lastRequestTimestamp time.Time
 }
@@ -34,16 +65,22 @@ func (client *togglRESTAPIClient) Request(method, route string, payload io.Reade
 
 	// pause between requests to make sure not
 	// more than ~ one request per second.
+	var waitTime time.Duration
 	timeSinceLastRequest := time.Since(client.lastRequestTimestamp)
 	if timeSinceLastRequest < client.pauseBetweenRequests {
-		waitTime := client.pauseBetweenRequests - timeSinceLastRequest
+		waitTime = client.pauseBetweenRequests - timeSinceLastRequest
 		time.Sleep(waitTime)
 	}
 
 	// capture the request time
 	client.lastRequestTimestamp = time.Now()
 
-	return client.request(method, route, payload)
+	content, err := client.request(method, route, payload)
+	if client.observer != nil {
+		client.observer.ObserveRequest(method, route, time.Since(client.lastRequestTimestamp), waitTime, err)
+	}
+
+	return content, err
 }
 
 // request sends an HTTP request with the given parameters (method, route, payload) to the Toggl
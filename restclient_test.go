@@ -112,3 +112,53 @@ func Test_Request_CalledMultipleTimes_RequestRateLimitApplies(t *testing.T) {
 		t.Logf("Issueing 3 requests should have taken at least 66ms but took only %s", duration)
 	}
 }
+
+type recordingObserver struct {
+	routes []string
+	waits  []time.Duration
+	errors []error
+}
+
+func (observer *recordingObserver) ObserveRequest(method, route string, duration, wait time.Duration, err error) {
+	observer.routes = append(observer.routes, method+" "+route)
+	observer.waits = append(observer.waits, wait)
+	observer.errors = append(observer.errors, err)
+}
+
+func Test_Request_ObserverGiven_RequestsAndRateLimitWaitsAreReported(t *testing.T) {
+	// arrange
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+
+	defer testServer.Close()
+
+	observer := &recordingObserver{}
+	restClient := &togglRESTAPIClient{
+		baseURL:              testServer.URL,
+		token:                "21das6d567a5d67s",
+		pauseBetweenRequests: 50 * time.Millisecond,
+		observer:             observer,
+	}
+
+	// act
+	restClient.Request("GET", "workspaces", nil)
+	restClient.Request("GET", "missing", nil)
+
+	// assert
+	if len(observer.routes) != 2 || observer.routes[1] != "GET missing" {
+		t.Fatalf("The observer should have received both requests but received %v", observer.routes)
+	}
+
+	if observer.waits[0] != 0 || observer.waits[1] <= 0 {
+		t.Fail()
+		t.Logf("Only the second request should have waited for the rate limit but the waits were %v", observer.waits)
+	}
+
+	if observer.errors[0] != nil || observer.errors[1] == nil {
+		t.Fail()
+		t.Logf("Only the second request should have failed but the errors were %v", observer.errors)
+	}
+}
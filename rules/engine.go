@@ -0,0 +1,196 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// DefaultWindow contains the default period which is checked for new
+// time entries by Watch.
+const DefaultWindow = 24 * time.Hour
+
+// Options contains the settings of an engine.
+type Options struct {
+	// Location is used for the weekday and time of day conditions. Defaults to time.Local.
+	Location *time.Location
+
+	// DryRun only reports the changes without updating the time entries.
+	DryRun bool
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Update contains a time entry changed by rules.
+type Update struct {
+	// TimeEntry contains the time entry with the assignments of the rules.
+	TimeEntry model.TimeEntry
+
+	// Rules contains the names of the matching rules.
+	Rules []string
+}
+
+// Result contains the outcome of a run.
+type Result struct {
+	Checked int
+	Updates []Update
+}
+
+// Engine applies rules to the time entries of an API.
+type Engine struct {
+	api      model.TogglAPI
+	set      *Set
+	options  Options
+	projects map[int][]model.Project
+}
+
+// New creates a new engine which applies the given rules to the time entries of the given API.
+func New(api model.TogglAPI, set *Set, options Options) *Engine {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	return &Engine{
+		api:      api,
+		set:      set,
+		options:  options,
+		projects: make(map[int][]model.Project),
+	}
+}
+
+// Run applies the rules to the stopped time entries between the given start and end.
+// The result contains the updates made before an error occurred.
+func (engine *Engine) Run(start, end time.Time) (Result, error) {
+	return engine.run(start, end, time.Time{})
+}
+
+// Watch applies the rules to new and modified time entries of the given
+// window every interval until the stop channel is closed. The result of
+// every run is passed to the given report function.
+func (engine *Engine) Watch(interval, window time.Duration, stop <-chan struct{}, report func(Result, error)) {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	var modifiedSince time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := engine.options.Now()
+		result, err := engine.run(now.Add(-window), now, modifiedSince)
+		report(result, err)
+		if err == nil {
+			modifiedSince = now
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run applies the rules to the stopped time entries between the given
+// start and end which have been modified after the given time.
+func (engine *Engine) run(start, end, modifiedSince time.Time) (Result, error) {
+	result := Result{}
+	timeEntries, timeEntriesError := engine.api.GetTimeEntries(start, end)
+	if timeEntriesError != nil {
+		return result, errors.Wrap(timeEntriesError, "Failed to fetch the time entries")
+	}
+
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Duration < 0 || timeEntry.At.Before(modifiedSince) {
+			continue
+		}
+
+		result.Checked++
+
+		projects, projectsError := engine.getProjects(timeEntry.Wid)
+		if projectsError != nil {
+			return result, projectsError
+		}
+
+		updated, matched, applyError := engine.set.Apply(timeEntry, engine.options.Location, projects)
+		if applyError != nil {
+			return result, errors.Wrap(applyError, fmt.Sprintf("Failed to apply the rules to time entry %d", timeEntry.ID))
+		}
+
+		if !changed(timeEntry, updated) {
+			continue
+		}
+
+		if !engine.options.DryRun {
+			if _, err := engine.api.PatchTimeEntry(timeEntry.ID, changesOf(timeEntry, updated)); err != nil {
+				return result, errors.Wrap(err, fmt.Sprintf("Failed to update time entry %d", timeEntry.ID))
+			}
+		}
+
+		result.Updates = append(result.Updates, Update{TimeEntry: updated, Rules: matched})
+	}
+
+	return result, nil
+}
+
+// getProjects returns the projects of the given workspace.
+// The projects are fetched once per workspace.
+func (engine *Engine) getProjects(workspaceID int) ([]model.Project, error) {
+	if projects, ok := engine.projects[workspaceID]; ok {
+		return projects, nil
+	}
+
+	projects, err := engine.api.GetProjects(workspaceID)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Failed to fetch the projects of workspace %d", workspaceID))
+	}
+
+	engine.projects[workspaceID] = projects
+	return projects, nil
+}
+
+// changed returns true if the rules modified the project, the tags or the billable flag.
+func changed(original, updated model.TimeEntry) bool {
+	return original.Pid != updated.Pid || original.Billable != updated.Billable || !sameTags(original.Tags, updated.Tags)
+}
+
+// changesOf returns the project, tags and billable flag modified by the rules.
+func changesOf(original, updated model.TimeEntry) model.TimeEntryChanges {
+	var changes model.TimeEntryChanges
+	if original.Pid != updated.Pid {
+		changes.Pid = model.Int(updated.Pid)
+	}
+
+	if original.Billable != updated.Billable {
+		changes.Billable = model.Bool(updated.Billable)
+	}
+
+	if !sameTags(original.Tags, updated.Tags) {
+		changes.Tags = model.Strings(updated.Tags)
+	}
+
+	return changes
+}
+
+// sameTags returns true if both lists contain the same tags.
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, tag := range a {
+		if !containsTag(b, tag) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,250 @@
+// Package rules assigns projects, tags and the billable flag to time
+// entries by rules which match the description, the weekday and the time
+// of day of the time entries.
+//
+// Rules are declared in JSON:
+//
+//	[
+//	  {
+//	    "name": "standup",
+//	    "description": "(?i)stand-?up",
+//	    "weekdays": ["mon", "tue", "wed", "thu", "fri"],
+//	    "after": "09:00",
+//	    "before": "10:00",
+//	    "without_project": true,
+//	    "project": "Internal",
+//	    "tags": ["meeting"],
+//	    "billable": false
+//	  }
+//	]
+//
+// All matching rules are applied in the declared order.
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/date"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// Rule defines the conditions and the assignments of a single rule.
+// Empty conditions match all time entries.
+type Rule struct {
+	Name string `json:"name"`
+
+	// Description contains a regular expression for the description.
+	Description string `json:"description,omitempty"`
+
+	// Weekdays contains the weekdays of the start (e.g. "mon" or "monday").
+	Weekdays []string `json:"weekdays,omitempty"`
+
+	// After and Before restrict the time of day of the start (e.g. "09:00").
+	// Periods across midnight (e.g. after 22:00, before 06:00) are supported.
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+
+	// WithoutProject only matches time entries without project.
+	WithoutProject bool `json:"without_project,omitempty"`
+
+	// Project contains the name or ID of the assigned project.
+	Project string `json:"project,omitempty"`
+
+	// Tags contains the added tags.
+	Tags []string `json:"tags,omitempty"`
+
+	// Billable sets the billable flag if specified.
+	Billable *bool `json:"billable,omitempty"`
+}
+
+// Set contains compiled rules.
+type Set struct {
+	rules []compiledRule
+}
+
+// compiledRule contains a rule with parsed conditions.
+type compiledRule struct {
+	Rule
+	description *regexp.Regexp
+	weekdays    map[time.Weekday]bool
+	after       time.Duration
+	before      time.Duration
+}
+
+// Read reads and compiles JSON rules from the given reader.
+func Read(r io.Reader) (*Set, error) {
+	var rules []Rule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the rules")
+	}
+
+	return Compile(rules)
+}
+
+// Compile validates the given rules and returns them as a set.
+func Compile(rules []Rule) (*Set, error) {
+	set := &Set{}
+	for index, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", index+1)
+			rule.Name = name
+		}
+
+		compiled := compiledRule{Rule: rule, after: -1, before: -1}
+		if rule.Description != "" {
+			expression, err := regexp.Compile(rule.Description)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("Rule %s has an invalid description pattern", name))
+			}
+
+			compiled.description = expression
+		}
+
+		if len(rule.Weekdays) > 0 {
+			compiled.weekdays = make(map[time.Weekday]bool)
+			for _, value := range rule.Weekdays {
+				weekday, err := date.ParseWeekday(value)
+				if err != nil {
+					return nil, errors.Wrap(err, fmt.Sprintf("Rule %s", name))
+				}
+
+				compiled.weekdays[weekday] = true
+			}
+		}
+
+		var err error
+		if compiled.after, err = parseTimeOfDay(rule.After); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Rule %s", name))
+		}
+
+		if compiled.before, err = parseTimeOfDay(rule.Before); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Rule %s", name))
+		}
+
+		if rule.Project == "" && len(rule.Tags) == 0 && rule.Billable == nil {
+			return nil, fmt.Errorf("Rule %s assigns neither a project, tags nor the billable flag", name)
+		}
+
+		set.rules = append(set.rules, compiled)
+	}
+
+	return set, nil
+}
+
+// Len returns the number of rules in the set.
+func (set *Set) Len() int {
+	return len(set.rules)
+}
+
+// Apply returns the time entry with the assignments of all matching
+// rules and the names of these rules. The time of day and the weekday
+// are evaluated in the given location. Project names are resolved with
+// the given projects of the workspace of the time entry.
+func (set *Set) Apply(timeEntry model.TimeEntry, location *time.Location, projects []model.Project) (model.TimeEntry, []string, error) {
+	var matched []string
+	for _, rule := range set.rules {
+		if !rule.matches(timeEntry, location) {
+			continue
+		}
+
+		matched = append(matched, rule.Name)
+		if rule.Project != "" {
+			projectID, err := findProject(projects, rule.Project)
+			if err != nil {
+				return timeEntry, matched, errors.Wrap(err, fmt.Sprintf("Rule %s", rule.Name))
+			}
+
+			timeEntry.Pid = projectID
+		}
+
+		for _, tag := range rule.Tags {
+			if !containsTag(timeEntry.Tags, tag) {
+				timeEntry.Tags = append(append([]string(nil), timeEntry.Tags...), tag)
+			}
+		}
+
+		if rule.Billable != nil {
+			timeEntry.Billable = *rule.Billable
+		}
+	}
+
+	return timeEntry, matched, nil
+}
+
+// matches returns true if the given time entry matches all conditions of the rule.
+func (rule compiledRule) matches(timeEntry model.TimeEntry, location *time.Location) bool {
+	if rule.WithoutProject && timeEntry.Pid != 0 {
+		return false
+	}
+
+	if rule.description != nil && !rule.description.MatchString(timeEntry.Description) {
+		return false
+	}
+
+	start := timeEntry.Start.In(location)
+	if rule.weekdays != nil && !rule.weekdays[start.Weekday()] {
+		return false
+	}
+
+	timeOfDay := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute + time.Duration(start.Second())*time.Second
+	switch {
+	case rule.after >= 0 && rule.before >= 0 && rule.before < rule.after:
+		// the period spans midnight
+		return timeOfDay >= rule.after || timeOfDay < rule.before
+	case rule.after >= 0 && timeOfDay < rule.after:
+		return false
+	case rule.before >= 0 && timeOfDay >= rule.before:
+		return false
+	}
+
+	return true
+}
+
+// parseTimeOfDay parses a time of day like "09:30" into the offset
+// from midnight. Returns -1 for an empty value.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "" {
+		return -1, nil
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return -1, fmt.Errorf("%q is not a valid time of day (e.g. 09:00)", value)
+	}
+
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// findProject returns the ID of the project with the given name or ID.
+func findProject(projects []model.Project, value string) (int, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+
+	for _, project := range projects {
+		if strings.EqualFold(project.Name, value) {
+			return project.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Project %q not found", value)
+}
+
+// containsTag returns true if the given tags contain the given tag.
+func containsTag(tags []string, tag string) bool {
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+
+	return false
+}
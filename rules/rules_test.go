@@ -0,0 +1,230 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+const testRules = `[
+  {
+    "name": "standup",
+    "description": "(?i)stand-?up",
+    "weekdays": ["mon", "Tuesday"],
+    "after": "09:00",
+    "before": "10:00",
+    "without_project": true,
+    "project": "Internal",
+    "tags": ["meeting"],
+    "billable": false
+  },
+  {
+    "name": "night",
+    "after": "22:00",
+    "before": "06:00",
+    "tags": ["overtime"]
+  }
+]`
+
+// tuesday returns the given time on Tuesday, 2016-09-06 (UTC).
+func tuesday(hour, minute int) time.Time {
+	return time.Date(2016, 9, 6, hour, minute, 0, 0, time.UTC)
+}
+
+// mustRead returns the compiled test rules.
+func mustRead(t *testing.T) *Set {
+	set, err := Read(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return set
+}
+
+func Test_Apply_MatchingRule_AssignmentsAreApplied(t *testing.T) {
+	// arrange
+	set := mustRead(t)
+	timeEntry := model.TimeEntry{ID: 1, Description: "Daily Standup", Start: tuesday(9, 15), Billable: true}
+	projects := []model.Project{{ID: 7, Name: "internal"}}
+
+	// act
+	updated, matched, err := set.Apply(timeEntry, time.UTC, projects)
+
+	// assert
+	if err != nil || len(matched) != 1 || updated.Pid != 7 || updated.Billable || len(updated.Tags) != 1 || updated.Tags[0] != "meeting" {
+		t.Fail()
+		t.Logf("Apply should have applied the standup rule (Time entry: %+v, Rules: %v, Error: %v)", updated, matched, err)
+	}
+}
+
+func Test_Apply_ConditionsDoNotMatch_TimeEntryIsUnchanged(t *testing.T) {
+	set := mustRead(t)
+	projects := []model.Project{{ID: 7, Name: "Internal"}}
+
+	inputs := map[string]model.TimeEntry{
+		"description": {Description: "Review", Start: tuesday(9, 15)},
+		"weekday":     {Description: "Standup", Start: tuesday(9, 15).AddDate(0, 0, 1)},
+		"time of day": {Description: "Standup", Start: tuesday(10, 0)},
+		"project":     {Description: "Standup", Start: tuesday(9, 15), Pid: 3},
+	}
+
+	for name, timeEntry := range inputs {
+		// act
+		_, matched, err := set.Apply(timeEntry, time.UTC, projects)
+
+		// assert
+		if err != nil || len(matched) != 0 {
+			t.Fail()
+			t.Logf("Apply should not have matched because of the %s (Rules: %v, Error: %v)", name, matched, err)
+		}
+	}
+}
+
+func Test_Apply_PeriodAcrossMidnight(t *testing.T) {
+	set := mustRead(t)
+	for hour, expected := range map[int]bool{23: true, 2: true, 6: false, 21: false} {
+		// act
+		_, matched, _ := set.Apply(model.TimeEntry{Start: tuesday(hour, 0)}, time.UTC, nil)
+
+		// assert
+		if (len(matched) == 1) != expected {
+			t.Fail()
+			t.Logf("Apply at %d:00 matched %v", hour, matched)
+		}
+	}
+}
+
+func Test_Compile_InvalidRules_ErrorIsReturned(t *testing.T) {
+	invalid := []Rule{
+		{Description: "(", Tags: []string{"a"}},
+		{Weekdays: []string{"someday"}, Tags: []string{"a"}},
+		{After: "9", Tags: []string{"a"}},
+		{Description: "x"},
+	}
+
+	for _, rule := range invalid {
+		if _, err := Compile([]Rule{rule}); err == nil {
+			t.Fail()
+			t.Logf("Compile should have rejected %+v", rule)
+		}
+	}
+}
+
+func Test_Run_MatchingEntriesAreUpdated(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	project, _ := api.CreateProject(model.Project{WorkspaceID: 1, Name: "Internal"})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Standup", Start: tuesday(9, 0), Stop: tuesday(9, 15)})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Review", Start: tuesday(11, 0), Stop: tuesday(12, 0)})
+
+	engine := New(api, mustRead(t), Options{Location: time.UTC})
+
+	// act
+	result, err := engine.Run(tuesday(0, 0), tuesday(23, 59))
+	again, _ := engine.Run(tuesday(0, 0), tuesday(23, 59))
+
+	// assert
+	if err != nil || result.Checked != 2 || len(result.Updates) != 1 || api.TimeEntries[0].Pid != project.ID {
+		t.Fail()
+		t.Logf("Run should have assigned the project to the standup (Result: %+v, Error: %v)", result, err)
+	}
+
+	if len(again.Updates) != 0 {
+		t.Fail()
+		t.Logf("A second run should not have changed anything: %+v", again)
+	}
+}
+
+func Test_Run_DryRun_NothingIsUpdated(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateProject(model.Project{WorkspaceID: 1, Name: "Internal"})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Standup", Start: tuesday(9, 0), Stop: tuesday(9, 15)})
+
+	// act
+	result, err := New(api, mustRead(t), Options{Location: time.UTC, DryRun: true}).Run(tuesday(0, 0), tuesday(23, 59))
+
+	// assert
+	if err != nil || len(result.Updates) != 1 || api.TimeEntries[0].Pid != 0 {
+		t.Fail()
+		t.Logf("Run should only have reported the update (Result: %+v, Error: %v)", result, err)
+	}
+}
+
+func Test_Watch_FirstRunChecksWindowThenStops(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateProject(model.Project{WorkspaceID: 1, Name: "Internal"})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Standup", Start: tuesday(9, 0), Stop: tuesday(9, 15)})
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Yesterday", Start: tuesday(9, 0).AddDate(0, 0, -1), Stop: tuesday(10, 0).AddDate(0, 0, -1)})
+
+	engine := New(api, mustRead(t), Options{Location: time.UTC, Now: func() time.Time { return tuesday(12, 0) }})
+
+	stop := make(chan struct{})
+	close(stop)
+
+	var results []Result
+
+	// act
+	engine.Watch(time.Hour, 12*time.Hour, stop, func(result Result, err error) {
+		results = append(results, result)
+	})
+
+	// assert
+	if len(results) != 1 || results[0].Checked != 1 || len(results[0].Updates) != 1 {
+		t.Fail()
+		t.Logf("Watch should have checked the entries of the window once: %+v", results)
+	}
+}
+
+func Test_run_OnlyEntriesModifiedSinceTheLastRunAreChecked(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateProject(model.Project{WorkspaceID: 1, Name: "Internal"})
+
+	api.Now = func() time.Time { return tuesday(8, 0) }
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Standup", Start: tuesday(7, 0), Stop: tuesday(7, 30)})
+
+	api.Now = func() time.Time { return tuesday(9, 15) }
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Description: "Standup", Start: tuesday(9, 0), Stop: tuesday(9, 15)})
+
+	engine := New(api, mustRead(t), Options{Location: time.UTC})
+
+	// act
+	result, err := engine.run(tuesday(0, 0), tuesday(12, 0), tuesday(9, 0))
+
+	// assert
+	if err != nil || result.Checked != 1 || len(result.Updates) != 1 || result.Updates[0].TimeEntry.ID != api.TimeEntries[1].ID {
+		t.Fail()
+		t.Logf("run should only have checked the entry modified after 09:00 (Result: %+v, Error: %v)", result, err)
+	}
+}
+
+func Test_changed_DifferentTagsOfSameCount_IsChanged(t *testing.T) {
+	// arrange
+	original := model.TimeEntry{Tags: []string{"meeting", "daily"}}
+	inputs := []struct {
+		Tags     []string
+		Expected bool
+	}{
+		{[]string{"daily", "meeting"}, false},
+		{[]string{"meeting", "overtime"}, true},
+		{[]string{"meeting"}, true},
+	}
+
+	for _, input := range inputs {
+		updated := original
+		updated.Tags = input.Tags
+
+		// act
+		result := changed(original, updated)
+
+		// assert
+		if result != input.Expected {
+			t.Errorf("changed(%v, %v) returned %t instead of %t", original.Tags, input.Tags, result, input.Expected)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package syncengine
+
+import (
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// The states of a local record.
+const (
+	// Synced records match the server state of the last sync.
+	Synced = "synced"
+
+	// Created records have been created locally and
+	// have a negative temporary ID until they are pushed.
+	Created = "created"
+
+	// Modified records have been changed locally.
+	Modified = "modified"
+
+	// Deleted records have been deleted locally.
+	Deleted = "deleted"
+)
+
+// ClientRecord contains the local copy of a client.
+type ClientRecord struct {
+	Client     model.Client `json:"client"`
+	State      string       `json:"state"`
+	ModifiedAt time.Time    `json:"modified_at"`
+}
+
+// ProjectRecord contains the local copy of a project.
+type ProjectRecord struct {
+	Project    model.Project `json:"project"`
+	State      string        `json:"state"`
+	ModifiedAt time.Time     `json:"modified_at"`
+}
+
+// TimeEntryRecord contains the local copy of a time entry.
+type TimeEntryRecord struct {
+	TimeEntry  model.TimeEntry `json:"time_entry"`
+	State      string          `json:"state"`
+	ModifiedAt time.Time       `json:"modified_at"`
+}
+
+// Snapshot contains the local store of the sync engine.
+// The records are keyed by their ID.
+type Snapshot struct {
+	// Since contains the server time of the last complete pull.
+	// The next sync only pulls the changes made afterwards.
+	Since time.Time `json:"since"`
+
+	Workspaces  []model.Workspace       `json:"workspaces"`
+	Clients     map[int]ClientRecord    `json:"clients"`
+	Projects    map[int]ProjectRecord   `json:"projects"`
+	TimeEntries map[int]TimeEntryRecord `json:"time_entries"`
+
+	LastTemporaryID int `json:"last_temporary_id"`
+
+	now func() time.Time
+}
+
+// newSnapshot creates a new empty snapshot.
+func newSnapshot() Snapshot {
+	return Snapshot{
+		Clients:     make(map[int]ClientRecord),
+		Projects:    make(map[int]ProjectRecord),
+		TimeEntries: make(map[int]TimeEntryRecord),
+	}
+}
+
+// PutClient stores the given client as a local change.
+// Clients without an ID get a temporary ID. Returns the ID of the client.
+func (snapshot *Snapshot) PutClient(client model.Client) int {
+	existing, exists := snapshot.Clients[client.ID]
+	state := snapshot.changedState(client.ID, exists, existing.State)
+	if state == Created && !exists {
+		client.ID = snapshot.temporaryID()
+	}
+
+	// keep the server modification time the local change is based on
+	client.At = existing.Client.At
+
+	snapshot.Clients[client.ID] = ClientRecord{Client: client, State: state, ModifiedAt: snapshot.now()}
+	return client.ID
+}
+
+// RemoveClient marks the client with the given ID as deleted.
+// Returns false if the client does not exist.
+func (snapshot *Snapshot) RemoveClient(id int) bool {
+	record, exists := snapshot.Clients[id]
+	if !exists || record.State == Deleted {
+		return false
+	}
+
+	if record.State == Created {
+		delete(snapshot.Clients, id)
+		return true
+	}
+
+	snapshot.Clients[id] = ClientRecord{Client: record.Client, State: Deleted, ModifiedAt: snapshot.now()}
+	return true
+}
+
+// PutProject stores the given project as a local change.
+// Projects without an ID get a temporary ID. Returns the ID of the project.
+func (snapshot *Snapshot) PutProject(project model.Project) int {
+	existing, exists := snapshot.Projects[project.ID]
+	state := snapshot.changedState(project.ID, exists, existing.State)
+	if state == Created && !exists {
+		project.ID = snapshot.temporaryID()
+	}
+
+	// keep the server modification time the local change is based on
+	project.At = existing.Project.At
+
+	snapshot.Projects[project.ID] = ProjectRecord{Project: project, State: state, ModifiedAt: snapshot.now()}
+	return project.ID
+}
+
+// RemoveProject marks the project with the given ID as deleted.
+// Returns false if the project does not exist.
+func (snapshot *Snapshot) RemoveProject(id int) bool {
+	record, exists := snapshot.Projects[id]
+	if !exists || record.State == Deleted {
+		return false
+	}
+
+	if record.State == Created {
+		delete(snapshot.Projects, id)
+		return true
+	}
+
+	snapshot.Projects[id] = ProjectRecord{Project: record.Project, State: Deleted, ModifiedAt: snapshot.now()}
+	return true
+}
+
+// PutTimeEntry stores the given time entry as a local change.
+// Time entries without an ID get a temporary ID. Returns the ID of the time entry.
+func (snapshot *Snapshot) PutTimeEntry(timeEntry model.TimeEntry) int {
+	existing, exists := snapshot.TimeEntries[timeEntry.ID]
+	state := snapshot.changedState(timeEntry.ID, exists, existing.State)
+	if state == Created && !exists {
+		timeEntry.ID = snapshot.temporaryID()
+	}
+
+	// keep the server modification time the local change is based on
+	timeEntry.At = existing.TimeEntry.At
+
+	snapshot.TimeEntries[timeEntry.ID] = TimeEntryRecord{TimeEntry: timeEntry, State: state, ModifiedAt: snapshot.now()}
+	return timeEntry.ID
+}
+
+// RemoveTimeEntry marks the time entry with the given ID as deleted.
+// Returns false if the time entry does not exist.
+func (snapshot *Snapshot) RemoveTimeEntry(id int) bool {
+	record, exists := snapshot.TimeEntries[id]
+	if !exists || record.State == Deleted {
+		return false
+	}
+
+	if record.State == Created {
+		delete(snapshot.TimeEntries, id)
+		return true
+	}
+
+	snapshot.TimeEntries[id] = TimeEntryRecord{TimeEntry: record.TimeEntry, State: Deleted, ModifiedAt: snapshot.now()}
+	return true
+}
+
+// changedState returns the state of a record with the given
+// ID after a local change.
+func (snapshot *Snapshot) changedState(id int, exists bool, state string) string {
+	if !exists || id <= 0 || state == Created {
+		return Created
+	}
+
+	return Modified
+}
+
+// temporaryID returns a new negative temporary ID.
+func (snapshot *Snapshot) temporaryID() int {
+	snapshot.LastTemporaryID--
+	return snapshot.LastTemporaryID
+}
@@ -0,0 +1,543 @@
+// Package syncengine provides a two-way sync between the Toggl API
+// and a local snapshot of workspaces, clients, projects and time entries.
+//
+// A sync first pulls the records which have been changed or deleted on
+// the server since the previous sync with a single request, passing the
+// Since timestamp of the previous sync to the API. The new timestamp is
+// only stored once all changes have been applied. Afterwards the local
+// changes are pushed in the order clients, projects, time entries. As
+// soon as a locally created client or project has been pushed, the
+// stored records referencing its temporary ID are rewritten to its
+// server ID, so a failed sync can be retried. Records changed on both
+// sides are resolved by the configured Policy.
+package syncengine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// Policy defines how records changed both locally
+// and on the server are resolved.
+type Policy int
+
+// The available conflict resolution policies.
+const (
+	// ServerWins discards the local change.
+	ServerWins Policy = iota
+
+	// LocalWins overwrites the server change.
+	LocalWins
+
+	// NewestWins keeps the change with the newer modification time.
+	// Local changes win over server-side deletions because the
+	// time of a deletion is unknown.
+	NewestWins
+)
+
+// snapshotKey contains the store key of the snapshot.
+const snapshotKey = "sync/snapshot"
+
+// ParsePolicy returns the policy with the given name
+// ("server", "local" or "newest").
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "server":
+		return ServerWins, nil
+	case "local":
+		return LocalWins, nil
+	case "newest":
+		return NewestWins, nil
+	}
+
+	return ServerWins, fmt.Errorf("Unknown conflict policy %q (available: server, local, newest)", name)
+}
+
+// Options contains the settings of the sync engine.
+type Options struct {
+	// Policy defines how conflicts are resolved.
+	Policy Policy
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Conflict describes a record which has been changed both locally and on the server.
+type Conflict struct {
+	// Kind contains the kind of the record ("client", "project" or "time entry").
+	Kind string
+
+	// ID contains the ID of the record.
+	ID int
+
+	// LocalWon is true if the local change has been kept.
+	LocalWon bool
+}
+
+// Report contains the outcome of a sync.
+type Report struct {
+	// Pulled contains the number of server records applied locally.
+	Pulled int
+
+	// Removed contains the number of records removed
+	// locally because they have been deleted on the server.
+	Removed int
+
+	// Pushed contains the number of local changes sent to the server.
+	Pushed int
+
+	// Conflicts contains all resolved conflicts.
+	Conflicts []Conflict
+}
+
+// New creates a new sync engine which syncs the given API with the
+// snapshot persisted in the given store. The server changes are
+// fetched from the given changes API (e.g. togglapi.NewUserAPI).
+func New(api model.TogglAPI, changes model.ChangesAPI, store cache.Store, options Options) *Engine {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	return &Engine{
+		api:     api,
+		changes: changes,
+		store:   store,
+		options: options,
+	}
+}
+
+// Engine syncs a local snapshot with the Toggl API.
+type Engine struct {
+	api     model.TogglAPI
+	changes model.ChangesAPI
+	store   cache.Store
+	options Options
+
+	mutex sync.Mutex
+}
+
+// Snapshot returns the current local snapshot.
+func (engine *Engine) Snapshot() (Snapshot, error) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	return engine.load()
+}
+
+// Update applies the given local changes to the snapshot and persists
+// it. The snapshot is not persisted if the change returns an error.
+func (engine *Engine) Update(change func(snapshot *Snapshot) error) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	snapshot, loadError := engine.load()
+	if loadError != nil {
+		return loadError
+	}
+
+	if err := change(&snapshot); err != nil {
+		return err
+	}
+
+	return engine.save(snapshot)
+}
+
+// Sync pulls the server changes, pushes the local changes and
+// persists the resulting snapshot. The progress made before an
+// error occurred is persisted as well.
+func (engine *Engine) Sync() (Report, error) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	snapshot, loadError := engine.load()
+	if loadError != nil {
+		return Report{}, loadError
+	}
+
+	report := Report{}
+	syncError := engine.sync(&snapshot, &report)
+
+	if err := engine.save(snapshot); err != nil {
+		return report, err
+	}
+
+	return report, syncError
+}
+
+// sync pulls and pushes all records.
+func (engine *Engine) sync(snapshot *Snapshot, report *Report) error {
+	if err := engine.pull(snapshot, report); err != nil {
+		return err
+	}
+
+	if err := engine.pushClients(snapshot, report); err != nil {
+		return err
+	}
+
+	if err := engine.pushProjects(snapshot, report); err != nil {
+		return err
+	}
+
+	return engine.pushTimeEntries(snapshot, report)
+}
+
+// pull applies the server changes made since the previous sync and
+// advances Since afterwards, so no change is skipped if the pull fails.
+func (engine *Engine) pull(snapshot *Snapshot, report *Report) error {
+	changes, err := engine.changes.GetChanges(snapshot.Since)
+	if err != nil {
+		return errors.Wrap(err, "Failed to pull the changes")
+	}
+
+	pullWorkspaces(snapshot, changes.Workspaces)
+	engine.pullClients(snapshot, changes, report)
+	engine.pullProjects(snapshot, changes, report)
+	engine.pullTimeEntries(snapshot, changes, report)
+
+	snapshot.Since = changes.Since
+	return nil
+}
+
+// pullWorkspaces replaces the local workspaces with the changed server workspaces.
+func pullWorkspaces(snapshot *Snapshot, workspaces []model.Workspace) {
+	for _, workspace := range workspaces {
+		replaced := false
+		for index, local := range snapshot.Workspaces {
+			if local.ID == workspace.ID {
+				snapshot.Workspaces[index] = workspace
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			snapshot.Workspaces = append(snapshot.Workspaces, workspace)
+		}
+	}
+}
+
+// pullClients applies the changed and deleted server clients.
+func (engine *Engine) pullClients(snapshot *Snapshot, changes model.ChangeSet, report *Report) {
+	for _, client := range changes.Clients {
+		local, exists := snapshot.Clients[client.ID]
+		if exists && local.State != Synced && engine.resolve(report, "client", client.ID, local.ModifiedAt, client.At) {
+			continue
+		}
+
+		snapshot.Clients[client.ID] = ClientRecord{Client: client, State: Synced}
+		report.Pulled++
+	}
+
+	for _, id := range changes.DeletedClients {
+		local, exists := snapshot.Clients[id]
+		if !exists {
+			continue
+		}
+
+		delete(snapshot.Clients, id)
+		if engine.keepDeleted(report, "client", id, local.State) {
+			local.Client.ID = snapshot.temporaryID()
+			local.State = Created
+			snapshot.Clients[local.Client.ID] = local
+		}
+	}
+}
+
+// pullProjects applies the changed and deleted server projects.
+func (engine *Engine) pullProjects(snapshot *Snapshot, changes model.ChangeSet, report *Report) {
+	for _, project := range changes.Projects {
+		local, exists := snapshot.Projects[project.ID]
+		if exists && local.State != Synced && engine.resolve(report, "project", project.ID, local.ModifiedAt, project.At) {
+			continue
+		}
+
+		snapshot.Projects[project.ID] = ProjectRecord{Project: project, State: Synced}
+		report.Pulled++
+	}
+
+	for _, id := range changes.DeletedProjects {
+		local, exists := snapshot.Projects[id]
+		if !exists {
+			continue
+		}
+
+		delete(snapshot.Projects, id)
+		if engine.keepDeleted(report, "project", id, local.State) {
+			local.Project.ID = snapshot.temporaryID()
+			local.State = Created
+			snapshot.Projects[local.Project.ID] = local
+		}
+	}
+}
+
+// pullTimeEntries applies the changed and deleted server time entries.
+func (engine *Engine) pullTimeEntries(snapshot *Snapshot, changes model.ChangeSet, report *Report) {
+	for _, timeEntry := range changes.TimeEntries {
+		local, exists := snapshot.TimeEntries[timeEntry.ID]
+		if exists && local.State != Synced && engine.resolve(report, "time entry", timeEntry.ID, local.ModifiedAt, timeEntry.At) {
+			continue
+		}
+
+		snapshot.TimeEntries[timeEntry.ID] = TimeEntryRecord{TimeEntry: timeEntry, State: Synced}
+		report.Pulled++
+	}
+
+	for _, id := range changes.DeletedTimeEntries {
+		local, exists := snapshot.TimeEntries[id]
+		if !exists {
+			continue
+		}
+
+		delete(snapshot.TimeEntries, id)
+		if engine.keepDeleted(report, "time entry", id, local.State) {
+			local.TimeEntry.ID = snapshot.temporaryID()
+			local.State = Created
+			snapshot.TimeEntries[local.TimeEntry.ID] = local
+		}
+	}
+}
+
+// pushClients sends the local client changes to the server. The
+// projects of created clients are moved to the server IDs.
+func (engine *Engine) pushClients(snapshot *Snapshot, report *Report) error {
+	for _, id := range sortedClientIDs(snapshot.Clients) {
+		local := snapshot.Clients[id]
+
+		var client model.Client
+		var err error
+		switch local.State {
+		case Synced:
+			continue
+
+		case Created:
+			local.Client.ID = 0
+			client, err = engine.api.CreateClient(local.Client)
+
+		case Modified:
+			client, err = engine.api.UpdateClient(local.Client)
+
+		case Deleted:
+			err = engine.api.DeleteClient(id)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to push client %d", id))
+		}
+
+		delete(snapshot.Clients, id)
+		if local.State != Deleted {
+			snapshot.Clients[client.ID] = ClientRecord{Client: client, State: Synced}
+		}
+
+		if local.State == Created {
+			replaceClientID(snapshot, id, client.ID)
+		}
+
+		report.Pushed++
+	}
+
+	return nil
+}
+
+// pushProjects sends the local project changes to the server. The
+// time entries of created projects are moved to the server IDs.
+func (engine *Engine) pushProjects(snapshot *Snapshot, report *Report) error {
+	for _, id := range sortedProjectIDs(snapshot.Projects) {
+		local := snapshot.Projects[id]
+
+		var project model.Project
+		var err error
+		switch local.State {
+		case Synced:
+			continue
+
+		case Created:
+			local.Project.ID = 0
+			project, err = engine.api.CreateProject(local.Project)
+
+		case Modified:
+			project, err = engine.api.UpdateProject(local.Project)
+
+		case Deleted:
+			err = engine.api.DeleteProject(id)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to push project %d", id))
+		}
+
+		delete(snapshot.Projects, id)
+		if local.State != Deleted {
+			snapshot.Projects[project.ID] = ProjectRecord{Project: project, State: Synced}
+		}
+
+		if local.State == Created {
+			replaceProjectID(snapshot, id, project.ID)
+		}
+
+		report.Pushed++
+	}
+
+	return nil
+}
+
+// pushTimeEntries sends the local time entry changes to the server.
+func (engine *Engine) pushTimeEntries(snapshot *Snapshot, report *Report) error {
+	for _, id := range sortedTimeEntryIDs(snapshot.TimeEntries) {
+		local := snapshot.TimeEntries[id]
+
+		var timeEntry model.TimeEntry
+		var err error
+		switch local.State {
+		case Synced:
+			continue
+
+		case Created:
+			local.TimeEntry.ID = 0
+			timeEntry, err = engine.api.CreateTimeEntry(local.TimeEntry)
+
+		case Modified:
+			timeEntry, err = engine.api.UpdateTimeEntry(local.TimeEntry)
+
+		case Deleted:
+			err = engine.api.DeleteTimeEntry(id)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to push time entry %d", id))
+		}
+
+		delete(snapshot.TimeEntries, id)
+		if local.State != Deleted {
+			snapshot.TimeEntries[timeEntry.ID] = TimeEntryRecord{TimeEntry: timeEntry, State: Synced}
+		}
+
+		report.Pushed++
+	}
+
+	return nil
+}
+
+// replaceClientID moves the projects of the client with the given
+// temporary ID to the given server ID.
+func replaceClientID(snapshot *Snapshot, temporaryID, serverID int) {
+	for id, local := range snapshot.Projects {
+		if local.Project.ClientID == temporaryID {
+			local.Project.ClientID = serverID
+			snapshot.Projects[id] = local
+		}
+	}
+}
+
+// replaceProjectID moves the time entries of the project with the
+// given temporary ID to the given server ID.
+func replaceProjectID(snapshot *Snapshot, temporaryID, serverID int) {
+	for id, local := range snapshot.TimeEntries {
+		if local.TimeEntry.Pid == temporaryID {
+			local.TimeEntry.Pid = serverID
+			snapshot.TimeEntries[id] = local
+		}
+	}
+}
+
+// resolve records a conflict between a local change and a server
+// change and returns true if the local change is kept.
+func (engine *Engine) resolve(report *Report, kind string, id int, localModifiedAt, serverModifiedAt time.Time) bool {
+	localWins := false
+	switch engine.options.Policy {
+	case LocalWins:
+		localWins = true
+	case NewestWins:
+		localWins = localModifiedAt.After(serverModifiedAt)
+	}
+
+	report.Conflicts = append(report.Conflicts, Conflict{Kind: kind, ID: id, LocalWon: localWins})
+	return localWins
+}
+
+// keepDeleted handles a record which has been deleted on the server
+// and returns true if the local record must be created again.
+func (engine *Engine) keepDeleted(report *Report, kind string, id int, state string) bool {
+	if state != Modified {
+		report.Removed++
+		return false
+	}
+
+	localWins := engine.options.Policy != ServerWins
+	report.Conflicts = append(report.Conflicts, Conflict{Kind: kind, ID: id, LocalWon: localWins})
+	if !localWins {
+		report.Removed++
+	}
+
+	return localWins
+}
+
+// load reads the snapshot from the store.
+func (engine *Engine) load() (Snapshot, error) {
+	snapshot := newSnapshot()
+	if _, _, err := engine.store.Get(snapshotKey, &snapshot); err != nil {
+		return Snapshot{}, errors.Wrap(err, "Failed to read the sync snapshot")
+	}
+
+	if snapshot.Clients == nil {
+		snapshot.Clients = make(map[int]ClientRecord)
+	}
+
+	if snapshot.Projects == nil {
+		snapshot.Projects = make(map[int]ProjectRecord)
+	}
+
+	if snapshot.TimeEntries == nil {
+		snapshot.TimeEntries = make(map[int]TimeEntryRecord)
+	}
+
+	snapshot.now = engine.options.Now
+	return snapshot, nil
+}
+
+// save writes the given snapshot to the store.
+func (engine *Engine) save(snapshot Snapshot) error {
+	if err := engine.store.Set(snapshotKey, snapshot); err != nil {
+		return errors.Wrap(err, "Failed to write the sync snapshot")
+	}
+
+	return nil
+}
+
+// sortedClientIDs returns the IDs of the given clients in ascending order.
+func sortedClientIDs(clients map[int]ClientRecord) []int {
+	var ids []int
+	for id := range clients {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// sortedProjectIDs returns the IDs of the given projects in ascending order.
+func sortedProjectIDs(projects map[int]ProjectRecord) []int {
+	var ids []int
+	for id := range projects {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// sortedTimeEntryIDs returns the IDs of the given time entries in ascending order.
+func sortedTimeEntryIDs(timeEntries map[int]TimeEntryRecord) []int {
+	var ids []int
+	for id := range timeEntries {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,408 @@
+package syncengine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/cache"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+// testClock is a manually advanced clock shared by the test API and the engine.
+type testClock struct {
+	now time.Time
+}
+
+func (clock *testClock) Now() time.Time { return clock.now }
+
+func (clock *testClock) advance() { clock.now = clock.now.Add(time.Minute) }
+
+// newTestEngine creates a sync engine for an in-memory API with a
+// client, a project and a time entry using a file store in a
+// temporary directory.
+func newTestEngine(t *testing.T, policy Policy) (*Engine, *togglapitest.API, *testClock) {
+	directory, err := ioutil.TempDir("", "togglapi-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(directory) })
+
+	clock := &testClock{now: time.Date(2016, 9, 6, 18, 0, 0, 0, time.UTC)}
+
+	api := togglapitest.NewAPI(model.Workspace{ID: 1, Name: "Work"})
+	api.Now = clock.Now
+	client, _ := api.CreateClient(model.Client{WorkspaceID: 1, Name: "Acme"})
+	project, _ := api.CreateProject(model.Project{WorkspaceID: 1, ClientID: client.ID, Name: "Website"})
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+	api.CreateTimeEntry(model.TimeEntry{Wid: 1, Pid: project.ID, Start: start, Stop: start.Add(time.Hour), Description: "Design"})
+
+	clock.advance()
+	store := cache.NewFileStore(filepath.Join(directory, "sync.json"))
+	return New(api, api, store, Options{Policy: policy, Now: clock.Now}), api, clock
+}
+
+// initialSync runs the first sync and fails the test on errors.
+func initialSync(t *testing.T, engine *Engine, clock *testClock) {
+	if _, err := engine.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.advance()
+}
+
+func Test_Sync_EmptySnapshot_AllRecordsArePulled(t *testing.T) {
+	// arrange
+	engine, _, _ := newTestEngine(t, ServerWins)
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	snapshot, _ := engine.Snapshot()
+	if err != nil || report.Pulled != 3 || len(snapshot.Clients) != 1 || len(snapshot.Projects) != 1 || len(snapshot.TimeEntries) != 1 {
+		t.Fail()
+		t.Logf("Sync should have pulled all records (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_NoChanges_NothingIsPulled(t *testing.T) {
+	// arrange
+	engine, _, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	if err != nil || report.Pulled != 0 || report.Pushed != 0 {
+		t.Fail()
+		t.Logf("Sync should not have pulled or pushed anything (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_ServerChange_ChangeIsPulled(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	client := api.Clients[0]
+	client.Name = "Acme Inc."
+	api.UpdateClient(client)
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	snapshot, _ := engine.Snapshot()
+	if err != nil || report.Pulled != 1 || snapshot.Clients[client.ID].Client.Name != "Acme Inc." {
+		t.Fail()
+		t.Logf("Sync should have pulled the changed client (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_ServerDeletion_LocalRecordIsRemoved(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	timeEntryID := api.TimeEntries[0].ID
+	api.DeleteTimeEntry(timeEntryID)
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	snapshot, _ := engine.Snapshot()
+	if _, exists := snapshot.TimeEntries[timeEntryID]; err != nil || exists || report.Removed != 1 {
+		t.Fail()
+		t.Logf("Sync should have removed the time entry deleted on the server (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_OldTimeEntryChanged_ChangeIsPulled(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	clock.now = clock.now.AddDate(1, 0, 0)
+	timeEntry := api.TimeEntries[0]
+	timeEntry.Description = "Design review"
+	api.UpdateTimeEntry(timeEntry)
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	snapshot, _ := engine.Snapshot()
+	if err != nil || report.Pulled != 1 || snapshot.TimeEntries[timeEntry.ID].TimeEntry.Description != "Design review" {
+		t.Fail()
+		t.Logf("Sync should have pulled the change of the year-old time entry (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_PullFails_SinceIsNotAdvanced(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+	before, _ := engine.Snapshot()
+
+	project := api.Projects[0]
+	project.Name = "Website Relaunch"
+	api.UpdateProject(project)
+	api.Errors["GetChanges"] = os.ErrDeadlineExceeded
+	clock.advance()
+
+	// act
+	_, failedError := engine.Sync()
+	after, _ := engine.Snapshot()
+	delete(api.Errors, "GetChanges")
+	report, err := engine.Sync()
+
+	// assert
+	snapshot, _ := engine.Snapshot()
+	if failedError == nil || !after.Since.Equal(before.Since) {
+		t.Errorf("The failed sync should have kept Since at %s but stored %s (Error: %v)", before.Since, after.Since, failedError)
+	}
+
+	if err != nil || report.Pulled != 1 || snapshot.Projects[project.ID].Project.Name != "Website Relaunch" {
+		t.Errorf("The next sync should have pulled the changed project (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_LocallyCreatedRecords_RecordsArePushedWithServerIDs(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	engine.Update(func(snapshot *Snapshot) error {
+		clientID := snapshot.PutClient(model.Client{WorkspaceID: 1, Name: "Globex"})
+		projectID := snapshot.PutProject(model.Project{WorkspaceID: 1, ClientID: clientID, Name: "Shop"})
+		start := time.Date(2016, 9, 6, 10, 0, 0, 0, time.UTC)
+		snapshot.PutTimeEntry(model.TimeEntry{Wid: 1, Pid: projectID, Start: start, Stop: start.Add(time.Hour), Description: "Checkout"})
+		return nil
+	})
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	if err != nil || report.Pushed != 3 || len(api.Clients) != 2 || len(api.Projects) != 2 || len(api.TimeEntries) != 2 {
+		t.Fatalf("Sync should have pushed the created records (Report: %+v, Error: %v)", report, err)
+	}
+
+	if api.Projects[1].ClientID != api.Clients[1].ID || api.TimeEntries[1].Pid != api.Projects[1].ID {
+		t.Fail()
+		t.Logf("Sync should have replaced the temporary IDs with server IDs")
+	}
+
+	snapshot, _ := engine.Snapshot()
+	for id, record := range snapshot.TimeEntries {
+		if id < 0 || record.State != Synced {
+			t.Fail()
+			t.Logf("Sync should have replaced the temporary time entry with the server time entry")
+		}
+	}
+}
+
+func Test_Sync_LocalDeletion_RecordIsDeletedOnServer(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	timeEntryID := api.TimeEntries[0].ID
+	engine.Update(func(snapshot *Snapshot) error {
+		snapshot.RemoveTimeEntry(timeEntryID)
+		return nil
+	})
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	if err != nil || report.Pushed != 1 || len(api.TimeEntries) != 0 {
+		t.Fail()
+		t.Logf("Sync should have deleted the time entry on the server (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_Conflict_PolicyDecidesWinner(t *testing.T) {
+	inputs := []struct {
+		policy            Policy
+		localChangedFirst bool
+		expectedName      string
+	}{
+		{ServerWins, false, "Server"},
+		{LocalWins, true, "Local"},
+		{NewestWins, true, "Server"},
+		{NewestWins, false, "Local"},
+	}
+
+	for _, input := range inputs {
+		// arrange
+		engine, api, clock := newTestEngine(t, input.policy)
+		initialSync(t, engine, clock)
+
+		project := api.Projects[0]
+		changeLocally := func() {
+			engine.Update(func(snapshot *Snapshot) error {
+				local := snapshot.Projects[project.ID].Project
+				local.Name = "Local"
+				snapshot.PutProject(local)
+				return nil
+			})
+		}
+
+		changeOnServer := func() {
+			serverProject := project
+			serverProject.Name = "Server"
+			api.UpdateProject(serverProject)
+		}
+
+		if input.localChangedFirst {
+			changeLocally()
+			clock.advance()
+			changeOnServer()
+		} else {
+			changeOnServer()
+			clock.advance()
+			changeLocally()
+		}
+
+		// act
+		report, err := engine.Sync()
+
+		// assert
+		if err != nil || len(report.Conflicts) != 1 || api.Projects[0].Name != input.expectedName {
+			t.Fail()
+			t.Logf("Sync with policy %d should have resolved the conflict in favor of %q but the server has %q (Report: %+v, Error: %v)",
+				input.policy, input.expectedName, api.Projects[0].Name, report, err)
+		}
+
+		snapshot, _ := engine.Snapshot()
+		if snapshot.Projects[project.ID].Project.Name != input.expectedName {
+			t.Fail()
+			t.Logf("Sync with policy %d should have stored %q locally", input.policy, input.expectedName)
+		}
+	}
+}
+
+func Test_Sync_LocalChangeOfRecordDeletedOnServer_LocalWins_RecordIsCreatedAgain(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, LocalWins)
+	initialSync(t, engine, clock)
+
+	client := api.Clients[0]
+	api.DeleteClient(client.ID)
+	engine.Update(func(snapshot *Snapshot) error {
+		local := snapshot.Clients[client.ID].Client
+		local.Notes = "Key account"
+		snapshot.PutClient(local)
+		return nil
+	})
+
+	// act
+	report, err := engine.Sync()
+
+	// assert
+	if err != nil || len(api.Clients) != 1 || api.Clients[0].Notes != "Key account" || len(report.Conflicts) != 1 {
+		t.Fail()
+		t.Logf("Sync should have created the client again (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Sync_PushFails_ProgressIsPersisted(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	engine.Update(func(snapshot *Snapshot) error {
+		snapshot.PutClient(model.Client{WorkspaceID: 1, Name: "Globex"})
+		snapshot.PutProject(model.Project{WorkspaceID: 1, Name: "Shop"})
+		return nil
+	})
+
+	api.Errors["CreateProject"] = os.ErrPermission
+
+	// act
+	_, err := engine.Sync()
+
+	// assert
+	snapshot, _ := engine.Snapshot()
+	createdClients := 0
+	for id := range snapshot.Clients {
+		if id > 0 {
+			createdClients++
+		}
+	}
+
+	if err == nil || createdClients != 2 {
+		t.Fail()
+		t.Logf("Sync should have returned an error and persisted the pushed client (Error: %v)", err)
+	}
+}
+
+func Test_Sync_ProjectPushFailsThenRetried_ServerIDsAreUsed(t *testing.T) {
+	// arrange
+	engine, api, clock := newTestEngine(t, ServerWins)
+	initialSync(t, engine, clock)
+
+	engine.Update(func(snapshot *Snapshot) error {
+		clientID := snapshot.PutClient(model.Client{WorkspaceID: 1, Name: "Globex"})
+		projectID := snapshot.PutProject(model.Project{WorkspaceID: 1, ClientID: clientID, Name: "Shop"})
+		start := time.Date(2016, 9, 6, 10, 0, 0, 0, time.UTC)
+		snapshot.PutTimeEntry(model.TimeEntry{Wid: 1, Pid: projectID, Start: start, Stop: start.Add(time.Hour), Description: "Checkout"})
+		return nil
+	})
+
+	api.Errors["CreateProject"] = os.ErrPermission
+	if _, err := engine.Sync(); err == nil {
+		t.Fatalf("The first Sync should have failed")
+	}
+
+	api.Errors["CreateTimeEntry"] = os.ErrPermission
+	delete(api.Errors, "CreateProject")
+	if _, err := engine.Sync(); err == nil {
+		t.Fatalf("The second Sync should have failed")
+	}
+
+	delete(api.Errors, "CreateTimeEntry")
+
+	// act
+	_, err := engine.Sync()
+
+	// assert
+	if err != nil || len(api.Projects) != 2 || len(api.TimeEntries) != 2 {
+		t.Fatalf("Sync should have pushed the remaining records (Error: %v)", err)
+	}
+
+	if api.Projects[1].ClientID != api.Clients[1].ID || api.TimeEntries[1].Pid != api.Projects[1].ID {
+		t.Fail()
+		t.Logf("The retried pushes should have used the server IDs (project: %+v, time entry: %+v)", api.Projects[1], api.TimeEntries[1])
+	}
+}
+
+func Test_ParsePolicy(t *testing.T) {
+	inputs := []struct {
+		name     string
+		expected Policy
+		valid    bool
+	}{
+		{"server", ServerWins, true},
+		{"local", LocalWins, true},
+		{"newest", NewestWins, true},
+		{"random", ServerWins, false},
+	}
+
+	for _, input := range inputs {
+		policy, err := ParsePolicy(input.name)
+		if policy != input.expected || (err == nil) != input.valid {
+			t.Fail()
+			t.Logf("ParsePolicy(%q) returned %d (Error: %v)", input.name, policy, err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The supported frequencies of schedules.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// Schedule contains a recurrence rule. It supports the RFC 5545 RRULE
+// parts FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY (with ordinals
+// for monthly rules, e.g. 1MO or -1FR) and BYMONTHDAY.
+type Schedule struct {
+	Frequency string
+	Interval  int
+	Weekdays  []Weekday
+	MonthDays []int
+}
+
+// Weekday contains a BYDAY value. Ordinal selects the n-th (negative:
+// n-th last) weekday of the month; zero selects every such weekday.
+type Weekday struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// weekdayCodes maps the RFC 5545 weekday codes to weekdays.
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ParseSchedule parses a recurrence rule like "FREQ=WEEKLY;BYDAY=MO,WE"
+// (an "RRULE:" prefix is allowed).
+func ParseSchedule(value string) (Schedule, error) {
+	schedule := Schedule{Interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(value), "RRULE:"), ";") {
+		keyAndValue := strings.SplitN(part, "=", 2)
+		if len(keyAndValue) != 2 {
+			return Schedule{}, fmt.Errorf("Invalid rule part %q in schedule %q", part, value)
+		}
+
+		key, partValue := strings.ToUpper(keyAndValue[0]), strings.ToUpper(keyAndValue[1])
+		switch key {
+		case "FREQ":
+			if partValue != Daily && partValue != Weekly && partValue != Monthly {
+				return Schedule{}, fmt.Errorf("Unsupported frequency %q (supported: DAILY, WEEKLY, MONTHLY)", partValue)
+			}
+
+			schedule.Frequency = partValue
+
+		case "INTERVAL":
+			interval, err := strconv.Atoi(partValue)
+			if err != nil || interval < 1 {
+				return Schedule{}, fmt.Errorf("Invalid interval %q", partValue)
+			}
+
+			schedule.Interval = interval
+
+		case "BYDAY":
+			for _, code := range strings.Split(partValue, ",") {
+				weekday, err := parseWeekday(code)
+				if err != nil {
+					return Schedule{}, err
+				}
+
+				schedule.Weekdays = append(schedule.Weekdays, weekday)
+			}
+
+		case "BYMONTHDAY":
+			for _, number := range strings.Split(partValue, ",") {
+				day, err := strconv.Atoi(number)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return Schedule{}, fmt.Errorf("Invalid day of month %q", number)
+				}
+
+				schedule.MonthDays = append(schedule.MonthDays, day)
+			}
+
+		default:
+			return Schedule{}, fmt.Errorf("Unsupported rule part %q in schedule %q", key, value)
+		}
+	}
+
+	if schedule.Frequency == "" {
+		return Schedule{}, fmt.Errorf("The schedule %q has no frequency (e.g. FREQ=WEEKLY)", value)
+	}
+
+	for _, weekday := range schedule.Weekdays {
+		if weekday.Ordinal != 0 && schedule.Frequency != Monthly {
+			return Schedule{}, fmt.Errorf("Ordinal weekdays are only supported for monthly schedules")
+		}
+	}
+
+	if len(schedule.MonthDays) > 0 && schedule.Frequency != Monthly {
+		return Schedule{}, fmt.Errorf("BYMONTHDAY is only supported for monthly schedules")
+	}
+
+	return schedule, nil
+}
+
+// parseWeekday parses a BYDAY value like "MO", "1MO" or "-1FR".
+func parseWeekday(value string) (Weekday, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return Weekday{}, fmt.Errorf("Invalid weekday %q", value)
+	}
+
+	weekday, ok := weekdayCodes[value[len(value)-2:]]
+	if !ok {
+		return Weekday{}, fmt.Errorf("Invalid weekday %q (e.g. MO, 1MO or -1FR)", value)
+	}
+
+	ordinal := 0
+	if prefix := value[:len(value)-2]; prefix != "" {
+		parsed, err := strconv.Atoi(prefix)
+		if err != nil || parsed == 0 || parsed < -5 || parsed > 5 {
+			return Weekday{}, fmt.Errorf("Invalid weekday %q (e.g. MO, 1MO or -1FR)", value)
+		}
+
+		ordinal = parsed
+	}
+
+	return Weekday{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+// needsAnchor returns true if the schedule can only be evaluated relative to a first day.
+func (schedule Schedule) needsAnchor() bool {
+	switch schedule.Frequency {
+	case Weekly:
+		return schedule.Interval > 1 || len(schedule.Weekdays) == 0
+	case Monthly:
+		return schedule.Interval > 1 || (len(schedule.Weekdays) == 0 && len(schedule.MonthDays) == 0)
+	default:
+		return schedule.Interval > 1
+	}
+}
+
+// Occurs returns true if the schedule includes the given day. Both the day
+// and the anchor (the first day of the recurrence) are midnights in UTC.
+func (schedule Schedule) Occurs(day, anchor time.Time) bool {
+	if day.Before(anchor) {
+		return false
+	}
+
+	switch schedule.Frequency {
+	case Daily:
+		days := int(day.Sub(anchor).Hours() / 24)
+		return days%schedule.Interval == 0 && schedule.matchesWeekday(day)
+
+	case Weekly:
+		weeks := int(startOfWeek(day).Sub(startOfWeek(anchor)).Hours() / (24 * 7))
+		if weeks%schedule.Interval != 0 {
+			return false
+		}
+
+		if len(schedule.Weekdays) == 0 {
+			return day.Weekday() == anchor.Weekday()
+		}
+
+		return schedule.matchesWeekday(day)
+
+	case Monthly:
+		months := (day.Year()-anchor.Year())*12 + int(day.Month()) - int(anchor.Month())
+		if months%schedule.Interval != 0 {
+			return false
+		}
+
+		if len(schedule.Weekdays) == 0 && len(schedule.MonthDays) == 0 {
+			return day.Day() == anchor.Day()
+		}
+
+		return schedule.matchesMonthDay(day) && schedule.matchesWeekday(day)
+	}
+
+	return false
+}
+
+// matchesWeekday returns true if the given day matches one of the
+// BYDAY values (or if there are none).
+func (schedule Schedule) matchesWeekday(day time.Time) bool {
+	if len(schedule.Weekdays) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, weekday := range schedule.Weekdays {
+		if weekday.Weekday != day.Weekday() {
+			continue
+		}
+
+		switch {
+		case weekday.Ordinal == 0:
+			return true
+		case weekday.Ordinal > 0 && (day.Day()-1)/7+1 == weekday.Ordinal:
+			return true
+		case weekday.Ordinal < 0 && (daysInMonth-day.Day())/7+1 == -weekday.Ordinal:
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesMonthDay returns true if the given day matches one of the
+// BYMONTHDAY values (or if there are none).
+func (schedule Schedule) matchesMonthDay(day time.Time) bool {
+	if len(schedule.MonthDays) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range schedule.MonthDays {
+		if monthDay == day.Day() || (monthDay < 0 && daysInMonth+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// startOfWeek returns the Monday of the week of the given day.
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
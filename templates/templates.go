@@ -0,0 +1,407 @@
+// Package templates creates recurring time entries (e.g. standups, weekly
+// syncs or on-call shifts) from templates with RRULE-style schedules.
+//
+// Templates are declared in JSON:
+//
+//	[
+//	  {
+//	    "name": "standup",
+//	    "schedule": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+//	    "start": "09:30",
+//	    "duration": "15m",
+//	    "project": "Internal",
+//	    "description": "Standup",
+//	    "tags": ["meeting"]
+//	  }
+//	]
+//
+// Time entries which already exist (same start, description and project)
+// are not created again, so a period can be materialized repeatedly.
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/ical"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// dateLayout contains the layout of the first and last days of templates.
+const dateLayout = "2006-01-02"
+
+// The actions taken for an occurrence.
+const (
+	// Created occurrences have been created as time entries.
+	Created = "created"
+
+	// Existing occurrences already exist as time entries.
+	Existing = "existing"
+
+	// Holiday occurrences fall on a holiday and are skipped.
+	Holiday = "holiday"
+)
+
+// Template defines a recurring time entry.
+type Template struct {
+	Name string `json:"name"`
+
+	// Schedule contains the recurrence rule (e.g. "FREQ=WEEKLY;BYDAY=MO").
+	Schedule string `json:"schedule"`
+
+	// From and Until contain the first and the last day of the
+	// recurrence (e.g. "2016-09-05", optional).
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+
+	// Start contains the time of day (e.g. "09:30").
+	Start string `json:"start"`
+
+	// Duration contains the length of the time entries (e.g. "15m" or "1h30m").
+	Duration string `json:"duration"`
+
+	// Project contains the name or ID of the project.
+	Project     string   `json:"project,omitempty"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+	Billable    bool     `json:"billable,omitempty"`
+
+	// OnHolidays also creates time entries on holidays (e.g. for on-call shifts).
+	OnHolidays bool `json:"on_holidays,omitempty"`
+}
+
+// Set contains compiled templates.
+type Set struct {
+	templates []compiledTemplate
+}
+
+// compiledTemplate contains a template with parsed values.
+type compiledTemplate struct {
+	Template
+	schedule Schedule
+	from     time.Time
+	until    time.Time
+	hour     int
+	minute   int
+	duration time.Duration
+}
+
+// Read reads and compiles JSON templates from the given reader.
+func Read(r io.Reader) (*Set, error) {
+	var templates []Template
+	if err := json.NewDecoder(r).Decode(&templates); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the templates")
+	}
+
+	return Compile(templates)
+}
+
+// Compile validates the given templates and returns them as a set.
+func Compile(templates []Template) (*Set, error) {
+	set := &Set{}
+	for index, template := range templates {
+		if template.Name == "" {
+			template.Name = fmt.Sprintf("#%d", index+1)
+		}
+
+		compiled, err := compile(template)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Template %s", template.Name))
+		}
+
+		set.templates = append(set.templates, compiled)
+	}
+
+	return set, nil
+}
+
+// compile parses the values of the given template.
+func compile(template Template) (compiledTemplate, error) {
+	compiled := compiledTemplate{Template: template}
+
+	schedule, scheduleError := ParseSchedule(template.Schedule)
+	if scheduleError != nil {
+		return compiled, scheduleError
+	}
+
+	compiled.schedule = schedule
+
+	if template.From != "" {
+		from, err := time.Parse(dateLayout, template.From)
+		if err != nil {
+			return compiled, fmt.Errorf("%q is not a valid first day (e.g. 2016-09-05)", template.From)
+		}
+
+		compiled.from = from
+	} else if schedule.needsAnchor() {
+		return compiled, fmt.Errorf("The schedule %q needs a first day (from)", template.Schedule)
+	}
+
+	if template.Until != "" {
+		until, err := time.Parse(dateLayout, template.Until)
+		if err != nil {
+			return compiled, fmt.Errorf("%q is not a valid last day (e.g. 2016-12-31)", template.Until)
+		}
+
+		compiled.until = until
+	}
+
+	start, startError := time.Parse("15:04", template.Start)
+	if startError != nil {
+		return compiled, fmt.Errorf("%q is not a valid start time (e.g. 09:30)", template.Start)
+	}
+
+	compiled.hour, compiled.minute = start.Hour(), start.Minute()
+
+	duration, durationError := time.ParseDuration(template.Duration)
+	if durationError != nil || duration <= 0 {
+		return compiled, fmt.Errorf("%q is not a valid duration (e.g. 15m)", template.Duration)
+	}
+
+	compiled.duration = duration
+
+	if template.Description == "" && template.Project == "" {
+		return compiled, fmt.Errorf("The template has neither a description nor a project")
+	}
+
+	return compiled, nil
+}
+
+// Len returns the number of templates in the set.
+func (set *Set) Len() int {
+	return len(set.templates)
+}
+
+// Options contains the settings for materializing templates.
+type Options struct {
+	// WorkspaceID contains the workspace of the created time entries.
+	WorkspaceID int
+
+	// Start and End define the period of the created time entries.
+	Start time.Time
+	End   time.Time
+
+	// Location is used for the start times of the templates. Defaults to time.Local.
+	Location *time.Location
+
+	// Holidays contains the days on which no time entries are created.
+	Holidays []ical.Holiday
+
+	// DryRun only reports the time entries without creating them.
+	DryRun bool
+}
+
+// Occurrence contains a time entry of a template.
+type Occurrence struct {
+	Template  string
+	TimeEntry model.TimeEntry
+	Action    string
+
+	// Holiday contains the name of the holiday the occurrence falls on.
+	Holiday string
+}
+
+// Report contains the occurrences of a period.
+type Report struct {
+	DryRun      bool
+	Occurrences []Occurrence
+}
+
+// Count returns the number of occurrences with the given action.
+func (report Report) Count(action string) int {
+	count := 0
+	for _, occurrence := range report.Occurrences {
+		if occurrence.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Write prints the occurrences of the report to the given writer.
+func (report Report) Write(w io.Writer) error {
+	for _, occurrence := range report.Occurrences {
+		action := occurrence.Action
+		if report.DryRun && action == Created {
+			action = "would create"
+		}
+
+		if occurrence.Holiday != "" {
+			action += " (" + occurrence.Holiday + ")"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %-10s %q %s\n",
+			occurrence.TimeEntry.Start.Format("2006-01-02 15:04"),
+			occurrence.Template,
+			occurrence.TimeEntry.Description,
+			action,
+		); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d created, %d existing, %d on holidays\n", report.Count(Created), report.Count(Existing), report.Count(Holiday))
+	return err
+}
+
+// Occurrences returns the time entries of all templates in the given
+// period ordered by start. Occurrences on holidays have the Holiday action.
+// Project names are resolved with the given projects.
+func (set *Set) Occurrences(options Options, projects []model.Project) ([]Occurrence, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	holidays := make(map[string]string)
+	for _, holiday := range options.Holidays {
+		holidays[holiday.Date.Format(dateLayout)] = holiday.Name
+	}
+
+	var occurrences []Occurrence
+	first := toDate(options.Start.In(options.Location))
+	for day := first; day.Before(toDate(options.End.In(options.Location)).AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		for _, template := range set.templates {
+			if !template.until.IsZero() && day.After(template.until) {
+				continue
+			}
+
+			anchor := template.from
+			if anchor.IsZero() {
+				anchor = first
+			}
+
+			if !template.schedule.Occurs(day, anchor) {
+				continue
+			}
+
+			// the wall clock time is kept on days with a daylight saving time change
+			start := time.Date(day.Year(), day.Month(), day.Day(), template.hour, template.minute, 0, 0, options.Location)
+			if start.Before(options.Start) || !start.Before(options.End) {
+				continue
+			}
+
+			projectID, projectError := findProject(projects, template.Project)
+			if projectError != nil {
+				return nil, errors.Wrap(projectError, fmt.Sprintf("Template %s", template.Name))
+			}
+
+			stop := start.Add(template.duration)
+			occurrence := Occurrence{
+				Template: template.Name,
+				TimeEntry: model.TimeEntry{
+					Wid:         options.WorkspaceID,
+					Pid:         projectID,
+					Start:       start,
+					Stop:        stop,
+					Duration:    int(template.duration.Seconds()),
+					Description: template.Description,
+					Tags:        template.Tags,
+					Billable:    template.Billable,
+				},
+			}
+
+			if name, isHoliday := holidays[day.Format(dateLayout)]; isHoliday && !template.OnHolidays {
+				occurrence.Action = Holiday
+				occurrence.Holiday = name
+			}
+
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	return occurrences, nil
+}
+
+// Materialize creates the time entries of the templates in the given
+// period which do not exist yet. The report contains the actions taken
+// before an error occurred.
+func Materialize(api model.TogglAPI, set *Set, options Options) (Report, error) {
+	report := Report{DryRun: options.DryRun}
+	if options.WorkspaceID == 0 {
+		return report, fmt.Errorf("No workspace specified")
+	}
+
+	if !options.End.After(options.Start) {
+		return report, fmt.Errorf("The end of the period must be after its start")
+	}
+
+	projects, projectsError := api.GetProjects(options.WorkspaceID)
+	if projectsError != nil {
+		return report, errors.Wrap(projectsError, "Failed to fetch the projects")
+	}
+
+	occurrences, occurrencesError := set.Occurrences(options, projects)
+	if occurrencesError != nil {
+		return report, occurrencesError
+	}
+
+	timeEntries, timeEntriesError := api.GetTimeEntries(options.Start, options.End)
+	if timeEntriesError != nil {
+		return report, errors.Wrap(timeEntriesError, "Failed to fetch the existing time entries")
+	}
+
+	existing := make(map[string]bool)
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Wid == options.WorkspaceID {
+			existing[timeEntryKey(timeEntry)] = true
+		}
+	}
+
+	for _, occurrence := range occurrences {
+		switch {
+		case occurrence.Action == Holiday:
+		case existing[timeEntryKey(occurrence.TimeEntry)]:
+			occurrence.Action = Existing
+		case options.DryRun:
+			occurrence.Action = Created
+		default:
+			created, err := api.CreateTimeEntry(occurrence.TimeEntry)
+			if err != nil {
+				return report, errors.Wrap(err, fmt.Sprintf("Failed to create the time entry of template %s on %s", occurrence.Template, occurrence.TimeEntry.Start.Format(dateLayout)))
+			}
+
+			occurrence.TimeEntry = created
+			occurrence.Action = Created
+		}
+
+		report.Occurrences = append(report.Occurrences, occurrence)
+	}
+
+	return report, nil
+}
+
+// timeEntryKey returns the key used for detecting existing time entries.
+func timeEntryKey(timeEntry model.TimeEntry) string {
+	return fmt.Sprintf("%d|%d|%s", timeEntry.Start.Truncate(time.Minute).Unix(), timeEntry.Pid, timeEntry.Description)
+}
+
+// toDate returns the calendar day of the given time as midnight in UTC.
+func toDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// findProject returns the ID of the project with the given name or ID.
+// Returns zero for an empty value.
+func findProject(projects []model.Project, value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+
+	for _, project := range projects {
+		if strings.EqualFold(project.Name, value) {
+			return project.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Project %q not found", value)
+}
@@ -0,0 +1,228 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/ical"
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/andreaskoch/togglapi/togglapitest"
+)
+
+const testTemplates = `[
+  {
+    "name": "standup",
+    "schedule": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+    "start": "09:30",
+    "duration": "15m",
+    "project": "Internal",
+    "description": "Standup",
+    "tags": ["meeting"]
+  },
+  {
+    "name": "on-call",
+    "schedule": "RRULE:FREQ=WEEKLY;INTERVAL=2",
+    "from": "2016-09-03",
+    "start": "08:00",
+    "duration": "12h",
+    "description": "On-call",
+    "on_holidays": true
+  }
+]`
+
+// date returns the midnight of the given day in UTC.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// mustRead returns the compiled test templates.
+func mustRead(t *testing.T) *Set {
+	set, err := Read(strings.NewReader(testTemplates))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return set
+}
+
+// testOptions returns the options for the two weeks from Monday, 2016-09-05.
+func testOptions() Options {
+	return Options{
+		WorkspaceID: 1,
+		Start:       date(2016, 9, 5),
+		End:         date(2016, 9, 19),
+		Location:    time.UTC,
+		Holidays:    []ical.Holiday{{Date: date(2016, 9, 7), Name: "Company day"}},
+	}
+}
+
+func Test_Schedule_Occurs(t *testing.T) {
+	inputs := []struct {
+		rule     string
+		anchor   time.Time
+		day      time.Time
+		expected bool
+	}{
+		{"FREQ=DAILY", date(2016, 9, 1), date(2016, 9, 4), true},
+		{"FREQ=DAILY;INTERVAL=3", date(2016, 9, 1), date(2016, 9, 4), true},
+		{"FREQ=DAILY;INTERVAL=3", date(2016, 9, 1), date(2016, 9, 5), false},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", date(2016, 9, 1), date(2016, 9, 9), true},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", date(2016, 9, 1), date(2016, 9, 8), false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", date(2016, 9, 5), date(2016, 9, 13), false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", date(2016, 9, 5), date(2016, 9, 20), true},
+		{"FREQ=MONTHLY;BYDAY=1MO", date(2016, 9, 1), date(2016, 10, 3), true},
+		{"FREQ=MONTHLY;BYDAY=-1FR", date(2016, 9, 1), date(2016, 9, 30), true},
+		{"FREQ=MONTHLY;BYDAY=-1FR", date(2016, 9, 1), date(2016, 9, 23), false},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", date(2016, 9, 1), date(2016, 2, 29).AddDate(1, 0, 0), false},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", date(2016, 1, 1), date(2016, 2, 29), true},
+		{"FREQ=MONTHLY", date(2016, 9, 15), date(2016, 10, 15), true},
+		{"FREQ=DAILY", date(2016, 9, 15), date(2016, 9, 14), false},
+	}
+
+	for _, input := range inputs {
+		schedule, err := ParseSchedule(input.rule)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) returned an error: %s", input.rule, err)
+		}
+
+		if result := schedule.Occurs(input.day, input.anchor); result != input.expected {
+			t.Fail()
+			t.Logf("%q (from %s) on %s returned %t but %t was expected", input.rule, input.anchor.Format(dateLayout), input.day.Format(dateLayout), result, input.expected)
+		}
+	}
+}
+
+func Test_ParseSchedule_InvalidRules_ErrorIsReturned(t *testing.T) {
+	for _, rule := range []string{"", "FREQ=YEARLY", "FREQ=DAILY;COUNT=3", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=WEEKLY;BYDAY=XX", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;BYMONTHDAY=1"} {
+		if _, err := ParseSchedule(rule); err == nil {
+			t.Fail()
+			t.Logf("ParseSchedule should have rejected %q", rule)
+		}
+	}
+}
+
+func Test_Compile_InvalidTemplates_ErrorIsReturned(t *testing.T) {
+	invalid := []Template{
+		{Schedule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", Start: "09:00", Duration: "1h", Description: "Needs a first day"},
+		{Schedule: "FREQ=DAILY", Start: "9", Duration: "1h", Description: "Invalid start"},
+		{Schedule: "FREQ=DAILY", Start: "09:00", Duration: "-1h", Description: "Invalid duration"},
+		{Schedule: "FREQ=DAILY", Start: "09:00", Duration: "1h"},
+	}
+
+	for _, template := range invalid {
+		if _, err := Compile([]Template{template}); err == nil {
+			t.Fail()
+			t.Logf("Compile should have rejected %+v", template)
+		}
+	}
+}
+
+func Test_Occurrences_HolidaysAreMarked(t *testing.T) {
+	// act
+	occurrences, err := mustRead(t).Occurrences(testOptions(), []model.Project{{ID: 7, Name: "Internal"}})
+
+	// assert
+	if err != nil || len(occurrences) != 11 {
+		t.Fatalf("Occurrences should have returned ten standups and one on-call shift but returned %d (Error: %v)", len(occurrences), err)
+	}
+
+	standup := occurrences[0].TimeEntry
+	if standup.Start != time.Date(2016, 9, 5, 9, 30, 0, 0, time.UTC) || standup.Duration != 900 || standup.Pid != 7 || standup.Wid != 1 {
+		t.Fail()
+		t.Logf("Occurrences returned the standup %+v", standup)
+	}
+
+	if occurrences[2].Action != Holiday || occurrences[2].Holiday != "Company day" {
+		t.Fail()
+		t.Logf("The standup on the holiday should have been marked: %+v", occurrences[2])
+	}
+
+	onCall := occurrences[len(occurrences)-1]
+	if onCall.Template != "on-call" || onCall.TimeEntry.Start != time.Date(2016, 9, 17, 8, 0, 0, 0, time.UTC) {
+		t.Fail()
+		t.Logf("The on-call shift should be on Saturday of the second week: %+v", onCall)
+	}
+}
+
+func Test_Occurrences_DaylightSavingTimeChange_StartTimeIsKept(t *testing.T) {
+	// arrange
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("The time zone database is not available: %s", err)
+	}
+
+	set, err := Read(strings.NewReader(`[{"name": "standup", "schedule": "FREQ=DAILY", "start": "09:30", "duration": "15m", "description": "Standup"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := Options{
+		WorkspaceID: 1,
+		Start:       time.Date(2016, 10, 30, 0, 0, 0, 0, berlin),
+		End:         time.Date(2016, 10, 31, 0, 0, 0, 0, berlin),
+		Location:    berlin,
+	}
+
+	// act
+	occurrences, err := set.Occurrences(options, nil)
+
+	// assert
+	if err != nil || len(occurrences) != 1 || !occurrences[0].TimeEntry.Start.Equal(time.Date(2016, 10, 30, 9, 30, 0, 0, berlin)) {
+		t.Fail()
+		t.Logf("The standup should start at 09:30 on the day daylight saving time ends (Occurrences: %+v, Error: %v)", occurrences, err)
+	}
+}
+
+func Test_Materialize_ExistingEntriesAreSkipped(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateProject(model.Project{WorkspaceID: 1, Name: "Internal"})
+	set := mustRead(t)
+
+	// act
+	first, firstError := Materialize(api, set, testOptions())
+	second, secondError := Materialize(api, set, testOptions())
+
+	// assert
+	if firstError != nil || first.Count(Created) != 10 || first.Count(Holiday) != 1 || len(api.TimeEntries) != 10 {
+		t.Fail()
+		t.Logf("Materialize should have created nine standups and the on-call shift (Report: %+v, Error: %v)", first, firstError)
+	}
+
+	if secondError != nil || second.Count(Created) != 0 || second.Count(Existing) != 10 {
+		t.Fail()
+		t.Logf("Materialize should not have created anything twice (Report: %+v, Error: %v)", second, secondError)
+	}
+}
+
+func Test_Materialize_DryRun_NothingIsCreated(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+	api.CreateProject(model.Project{WorkspaceID: 1, Name: "Internal"})
+	options := testOptions()
+	options.DryRun = true
+
+	// act
+	report, err := Materialize(api, mustRead(t), options)
+
+	// assert
+	if err != nil || report.Count(Created) != 10 || len(api.TimeEntries) != 0 {
+		t.Fail()
+		t.Logf("Materialize should only have reported the time entries (Report: %+v, Error: %v)", report, err)
+	}
+}
+
+func Test_Materialize_UnknownProject_ErrorIsReturned(t *testing.T) {
+	// arrange
+	api := togglapitest.NewAPI(model.Workspace{ID: 1})
+
+	// act
+	_, err := Materialize(api, mustRead(t), testOptions())
+
+	// assert
+	if err == nil || len(api.TimeEntries) != 0 {
+		t.Fail()
+		t.Logf("Materialize should have failed before creating anything")
+	}
+}
@@ -29,26 +29,36 @@ func NewTimeEntryAPI(baseURL, token string) model.TimeEntryAPI {
 type TimeEntryAPI struct {
 	restClient    RESTRequester
 	dateFormatter date.Formatter
+
+	// location contains the optional reporting time zone. The start, stop
+	// and modification times of returned time entries are converted into it.
+	location *time.Location
 }
 
 // CreateTimeEntry creates a new time entry.
+// Returns a *model.ValidationError without sending a request if the time entry is invalid.
 func (repository *TimeEntryAPI) CreateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	if err := timeEntry.Validate(); err != nil {
+		return model.TimeEntry{}, err
+	}
 
-	duration := int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+	stop, duration := stopAndDuration(timeEntry)
 
 	timeEntryModel := struct {
-		Wid         int       `json:"wid"`
-		Pid         int       `json:"pid"`
-		Start       time.Time `json:"start"`
-		Duration    int       `json:"duration"`
-		Billable    bool      `json:"billable"`
-		Description string    `json:"description"`
-		Tags        []string  `json:"tags"`
-		CreatedWith string    `json:"created_with"`
+		Wid         int        `json:"wid,omitempty"`
+		Pid         int        `json:"pid,omitempty"`
+		Start       time.Time  `json:"start"`
+		Stop        *time.Time `json:"stop,omitempty"`
+		Duration    int        `json:"duration"`
+		Billable    bool       `json:"billable,omitempty"`
+		Description string     `json:"description,omitempty"`
+		Tags        []string   `json:"tags,omitempty"`
+		CreatedWith string     `json:"created_with"`
 	}{
 		Wid:         timeEntry.Wid,
 		Pid:         timeEntry.Pid,
 		Start:       timeEntry.Start,
+		Stop:        stop,
 		Duration:    duration,
 		Billable:    timeEntry.Billable,
 		Description: timeEntry.Description,
@@ -56,32 +66,143 @@ func (repository *TimeEntryAPI) CreateTimeEntry(timeEntry model.TimeEntry) (mode
 		CreatedWith: clientName,
 	}
 
-	// create the request object
-	timeEntryRequest := struct {
-		TimeEntry interface{} `json:"time_entry"`
+	content, err := repository.sendTimeEntry(http.MethodPost, "time_entries", timeEntryModel)
+	if err != nil {
+		return model.TimeEntry{}, errors.Wrap(err, "Failed to create time entry")
+	}
+
+	return repository.decodeTimeEntry(content)
+}
+
+// GetTimeEntry returns the time entry with the given ID.
+func (repository *TimeEntryAPI) GetTimeEntry(id int) (model.TimeEntry, error) {
+	route := fmt.Sprintf("time_entries/%d", id)
+
+	content, err := repository.restClient.Request(http.MethodGet, route, nil)
+	if err != nil {
+		return model.TimeEntry{}, errors.Wrap(err, fmt.Sprintf("Failed to retrieve time entry %d", id))
+	}
+
+	return repository.decodeTimeEntry(content)
+}
+
+// UpdateTimeEntry replaces the time entry with the ID of the given time
+// entry. An unset project and empty tags remove them; use PatchTimeEntry
+// to change single fields.
+func (repository *TimeEntryAPI) UpdateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+
+	stop, duration := stopAndDuration(timeEntry)
+
+	tags := timeEntry.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	timeEntryModel := struct {
+		Wid         int        `json:"wid,omitempty"`
+		Pid         int        `json:"pid"`
+		Start       time.Time  `json:"start"`
+		Stop        *time.Time `json:"stop,omitempty"`
+		Duration    int        `json:"duration"`
+		Billable    bool       `json:"billable"`
+		Description string     `json:"description"`
+		Tags        []string   `json:"tags"`
 	}{
-		TimeEntry: timeEntryModel,
+		Wid:         timeEntry.Wid,
+		Pid:         timeEntry.Pid,
+		Start:       timeEntry.Start,
+		Stop:        stop,
+		Duration:    duration,
+		Billable:    timeEntry.Billable,
+		Description: timeEntry.Description,
+		Tags:        tags,
 	}
 
-	jsonBody, marshalError := json.Marshal(timeEntryRequest)
-	if marshalError != nil {
-		return model.TimeEntry{}, errors.Wrap(marshalError, "Failed to serialize the time entry")
+	route := fmt.Sprintf("time_entries/%d", timeEntry.ID)
+	content, err := repository.sendTimeEntry(http.MethodPut, route, timeEntryModel)
+	if err != nil {
+		return model.TimeEntry{}, errors.Wrap(err, fmt.Sprintf("Failed to update time entry %d", timeEntry.ID))
 	}
 
-	content, err := repository.restClient.Request(http.MethodPost, "time_entries", bytes.NewBuffer(jsonBody))
+	return repository.decodeTimeEntry(content)
+}
+
+// PatchTimeEntry changes only the given fields of the time entry with the
+// given ID. If the start and the stop are changed without a duration, the
+// duration is calculated from them.
+func (repository *TimeEntryAPI) PatchTimeEntry(id int, changes model.TimeEntryChanges) (model.TimeEntry, error) {
+	if changes.Duration == nil && changes.Start != nil && changes.Stop != nil {
+		changes.Duration = model.Int(int(changes.Stop.Sub(*changes.Start).Seconds()))
+	}
+
+	route := fmt.Sprintf("time_entries/%d", id)
+	content, err := repository.sendTimeEntry(http.MethodPut, route, changes)
 	if err != nil {
-		return model.TimeEntry{}, errors.Wrap(err, "Failed to create time entry")
+		return model.TimeEntry{}, errors.Wrap(err, fmt.Sprintf("Failed to update time entry %d", id))
 	}
 
-	var timeEntryResponse struct {
-		TimeEntry model.TimeEntry `json:"data"`
+	return repository.decodeTimeEntry(content)
+}
+
+// DeleteTimeEntry deletes the time entry with the given ID.
+func (repository *TimeEntryAPI) DeleteTimeEntry(id int) error {
+	route := fmt.Sprintf("time_entries/%d", id)
+
+	if _, err := repository.restClient.Request(http.MethodDelete, route, nil); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to delete time entry %d", id))
 	}
 
-	if unmarshalError := json.Unmarshal(content, &timeEntryResponse); unmarshalError != nil {
-		return model.TimeEntry{}, errors.Wrap(unmarshalError, "Failed to deserialize the time entry")
+	return nil
+}
+
+// StartTimeEntry starts a new running time entry.
+func (repository *TimeEntryAPI) StartTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+
+	timeEntryModel := struct {
+		Wid         int      `json:"wid,omitempty"`
+		Pid         int      `json:"pid,omitempty"`
+		Billable    bool     `json:"billable,omitempty"`
+		Description string   `json:"description,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+		CreatedWith string   `json:"created_with"`
+	}{
+		Wid:         timeEntry.Wid,
+		Pid:         timeEntry.Pid,
+		Billable:    timeEntry.Billable,
+		Description: timeEntry.Description,
+		Tags:        timeEntry.Tags,
+		CreatedWith: clientName,
+	}
+
+	content, err := repository.sendTimeEntry(http.MethodPost, "time_entries/start", timeEntryModel)
+	if err != nil {
+		return model.TimeEntry{}, errors.Wrap(err, "Failed to start time entry")
+	}
+
+	return repository.decodeTimeEntry(content)
+}
+
+// StopTimeEntry stops the running time entry with the given ID.
+func (repository *TimeEntryAPI) StopTimeEntry(id int) (model.TimeEntry, error) {
+	route := fmt.Sprintf("time_entries/%d/stop", id)
+
+	content, err := repository.restClient.Request(http.MethodPut, route, nil)
+	if err != nil {
+		return model.TimeEntry{}, errors.Wrap(err, fmt.Sprintf("Failed to stop time entry %d", id))
 	}
 
-	return timeEntryResponse.TimeEntry, nil
+	return repository.decodeTimeEntry(content)
+}
+
+// GetCurrentTimeEntry returns the currently running time entry.
+// Returns an empty time entry if no time entry is running.
+func (repository *TimeEntryAPI) GetCurrentTimeEntry() (model.TimeEntry, error) {
+	content, err := repository.restClient.Request(http.MethodGet, "time_entries/current", nil)
+	if err != nil {
+		return model.TimeEntry{}, errors.Wrap(err, "Failed to retrieve the current time entry")
+	}
+
+	return repository.decodeTimeEntry(content)
 }
 
 // GetTimeEntries returns all time entries created between the given start and end date.
@@ -103,5 +224,69 @@ func (repository *TimeEntryAPI) GetTimeEntries(start, end time.Time) ([]model.Ti
 		return nil, errors.Wrap(unmarshalError, "Failed to deserialize time entries")
 	}
 
+	for index := range timeEntries {
+		timeEntries[index] = repository.localize(timeEntries[index])
+	}
+
 	return timeEntries, nil
 }
+
+// sendTimeEntry wraps the given time entry model into a time entry request
+// and sends it to the given route.
+func (repository *TimeEntryAPI) sendTimeEntry(method, route string, timeEntryModel interface{}) ([]byte, error) {
+
+	// create the request object
+	timeEntryRequest := struct {
+		TimeEntry interface{} `json:"time_entry"`
+	}{
+		TimeEntry: timeEntryModel,
+	}
+
+	jsonBody, marshalError := json.Marshal(timeEntryRequest)
+	if marshalError != nil {
+		return nil, errors.Wrap(marshalError, "Failed to serialize the time entry")
+	}
+
+	return repository.restClient.Request(method, route, bytes.NewBuffer(jsonBody))
+}
+
+// stopAndDuration returns the stop and the duration which are sent for the
+// given time entry. Running time entries have no stop and keep their
+// negative duration.
+func stopAndDuration(timeEntry model.TimeEntry) (*time.Time, int) {
+	if timeEntry.Stop.IsZero() && timeEntry.Duration < 0 {
+		return nil, timeEntry.Duration
+	}
+
+	stop := timeEntry.Stop
+	return &stop, int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+}
+
+// decodeTimeEntry deserializes the time entry contained in the data
+// attribute of the given API response.
+func (repository *TimeEntryAPI) decodeTimeEntry(content []byte) (model.TimeEntry, error) {
+	var timeEntryResponse struct {
+		TimeEntry model.TimeEntry `json:"data"`
+	}
+
+	if unmarshalError := json.Unmarshal(content, &timeEntryResponse); unmarshalError != nil {
+		return model.TimeEntry{}, errors.Wrap(unmarshalError, "Failed to deserialize the time entry")
+	}
+
+	return repository.localize(timeEntryResponse.TimeEntry), nil
+}
+
+// localize converts the times of the given time entry into the reporting time zone.
+func (repository *TimeEntryAPI) localize(timeEntry model.TimeEntry) model.TimeEntry {
+	if repository.location == nil {
+		return timeEntry
+	}
+
+	for _, value := range []*time.Time{&timeEntry.Start, &timeEntry.Stop, &timeEntry.At} {
+		if !value.IsZero() {
+			*value = value.In(repository.location)
+		}
+	}
+
+	return timeEntry
+}
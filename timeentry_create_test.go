@@ -3,7 +3,9 @@ package togglapi
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/andreaskoch/togglapi/date"
 	"github.com/andreaskoch/togglapi/model"
@@ -22,7 +24,7 @@ func Test_CreateTimeEntry_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
 		dateFormatter: date.NewISO8601Formatter(),
 	}
 
-	input := model.TimeEntry{}
+	input := model.TimeEntry{Wid: 1, Start: time.Date(2016, 9, 6, 6, 33, 56, 0, time.UTC), Stop: time.Date(2016, 9, 6, 6, 48, 51, 0, time.UTC)}
 
 	// act
 	_, err := timeEntryAPI.CreateTimeEntry(input)
@@ -52,7 +54,7 @@ func Test_CreateTimeEntry_InvalidJSONIsReturned_ErrorIsReturned(t *testing.T) {
 		dateFormatter: date.NewISO8601Formatter(),
 	}
 
-	input := model.TimeEntry{}
+	input := model.TimeEntry{Wid: 1, Start: time.Date(2016, 9, 6, 6, 33, 56, 0, time.UTC), Stop: time.Date(2016, 9, 6, 6, 48, 51, 0, time.UTC)}
 
 	// act
 	_, err := timeEntryAPI.CreateTimeEntry(input)
@@ -79,7 +81,7 @@ func Test_CreateTimeEntry_NoTimeEntryReturned_EmptyTimeEntryIsReturned(t *testin
 		dateFormatter: date.NewISO8601Formatter(),
 	}
 
-	input := model.TimeEntry{}
+	input := model.TimeEntry{Wid: 1, Start: time.Date(2016, 9, 6, 6, 33, 56, 0, time.UTC), Stop: time.Date(2016, 9, 6, 6, 48, 51, 0, time.UTC)}
 
 	// act
 	_, err := timeEntryAPI.CreateTimeEntry(input)
@@ -111,7 +113,7 @@ func Test_CreateTimeEntry_HTTPMethodIsPOST(t *testing.T) {
 		dateFormatter: date.NewISO8601Formatter(),
 	}
 
-	input := model.TimeEntry{}
+	input := model.TimeEntry{Wid: 1, Start: time.Date(2016, 9, 6, 6, 33, 56, 0, time.UTC), Stop: time.Date(2016, 9, 6, 6, 48, 51, 0, time.UTC)}
 
 	// act
 	timeEntryAPI.CreateTimeEntry(input)
@@ -142,7 +144,7 @@ func Test_CreateTimeEntry_ValidJSONIsReturned_ProjectsAreReturned(t *testing.T)
 		dateFormatter: date.NewISO8601Formatter(),
 	}
 
-	input := model.TimeEntry{}
+	input := model.TimeEntry{Wid: 1, Start: time.Date(2016, 9, 6, 6, 33, 56, 0, time.UTC), Stop: time.Date(2016, 9, 6, 6, 48, 51, 0, time.UTC)}
 
 	// act
 	timeEntry, err := timeEntryAPI.CreateTimeEntry(input)
@@ -158,3 +160,89 @@ func Test_CreateTimeEntry_ValidJSONIsReturned_ProjectsAreReturned(t *testing.T)
 		}
 	}
 }
+
+func Test_CreateTimeEntry_InvalidTimeEntry_NoRequestIsSent(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			t.Errorf("CreateTimeEntry should not send a request for an invalid time entry")
+			return nil, nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	start := time.Date(2016, 9, 6, 9, 0, 0, 0, time.UTC)
+	input := model.TimeEntry{Start: start, Stop: start.Add(-time.Hour)}
+
+	// act
+	_, err := timeEntryAPI.CreateTimeEntry(input)
+
+	// assert
+	validationError, ok := err.(*model.ValidationError)
+	if !ok || len(validationError.Fields) != 2 {
+		t.Fail()
+		t.Logf("CreateTimeEntry should have returned a validation error for the workspace and the stop but returned %v", err)
+	}
+}
+
+func Test_CreateTimeEntry_UnsetFieldsAreOmitted(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 1}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+
+	// act
+	timeEntryAPI.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour)})
+
+	// assert
+	expected := `{"time_entry":{"wid":1,"start":"2016-09-06T06:30:00Z","stop":"2016-09-06T07:30:00Z","duration":3600,"created_with":"github.com/andreaskoch/togglapi"}}`
+	if body != expected {
+		t.Fail()
+		t.Logf("CreateTimeEntry should have sent %s but sent %s", expected, body)
+	}
+}
+
+func Test_CreateTimeEntry_RunningTimeEntry_NegativeStartIsSentAsDuration(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 1}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+
+	// act
+	timeEntryAPI.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start, Duration: -int(start.Unix())})
+
+	// assert
+	expected := `{"time_entry":{"wid":1,"start":"2016-09-06T06:30:00Z","duration":-1473143400,"created_with":"github.com/andreaskoch/togglapi"}}`
+	if body != expected {
+		t.Fail()
+		t.Logf("CreateTimeEntry should have sent %s but sent %s", expected, body)
+	}
+}
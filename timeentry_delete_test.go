@@ -0,0 +1,64 @@
+package togglapi
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/andreaskoch/togglapi/date"
+)
+
+func Test_DeleteTimeEntry_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	err := timeEntryAPI.DeleteTimeEntry(1)
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("DeleteTimeEntry should return an error if the REST client returned an error")
+	}
+}
+
+func Test_DeleteTimeEntry_DELETERequestIsSentToTimeEntryRoute(t *testing.T) {
+	// arrange
+	requestSent := false
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requestSent = true
+
+			// assert
+			if method != "DELETE" || route != "time_entries/436694100" {
+				t.Fail()
+				t.Logf("DeleteTimeEntry should send a DELETE request to time_entries/436694100 but sent %s %s", method, route)
+			}
+
+			return nil, nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	err := timeEntryAPI.DeleteTimeEntry(436694100)
+
+	// assert
+	if err != nil || !requestSent {
+		t.Fail()
+		t.Logf("DeleteTimeEntry should have sent a request and returned no error (Error: %v)", err)
+	}
+}
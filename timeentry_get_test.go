@@ -179,3 +179,76 @@ func Test_GetTimeEntries_ValidJSONIsReturned_ProjectsAreReturned(t *testing.T) {
 		}
 	}
 }
+
+func Test_GetTimeEntries_LocationGiven_DatesAreFormattedInLocationAcrossDSTTransition(t *testing.T) {
+	// arrange
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	var requestedRoute string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requestedRoute = route
+			return []byte(`[]`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601FormatterInLocation(berlin),
+		location:      berlin,
+	}
+
+	// the day of the end of the daylight saving time in Berlin has 25 hours
+	start := time.Date(2016, 10, 29, 22, 0, 0, 0, time.UTC)
+	end := time.Date(2016, 10, 30, 22, 59, 59, 0, time.UTC)
+
+	// act
+	timeEntryAPI.GetTimeEntries(start, end)
+
+	// assert
+	expectedRoute := "time_entries?start_date=2016-10-30T00%3A00%3A00%2B02%3A00&end_date=2016-10-30T23%3A59%3A59%2B01%3A00"
+	if requestedRoute != expectedRoute {
+		t.Fail()
+		t.Logf("GetTimeEntries should have requested %q but requested %q", expectedRoute, requestedRoute)
+	}
+}
+
+func Test_GetTimeEntries_LocationGiven_TimeEntriesAreConvertedIntoLocation(t *testing.T) {
+	// arrange
+	timeEntriesJSON := `[
+	{
+		"id": 1,
+		"start": "2016-03-27T00:30:00Z",
+		"stop": "2016-03-27T01:30:00Z",
+		"duration": 3600
+	}
+]`
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(timeEntriesJSON), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601FormatterInLocation(berlin),
+		location:      berlin,
+	}
+
+	// act
+	timeEntries, err := timeEntryAPI.GetTimeEntries(time.Date(2016, 3, 27, 0, 0, 0, 0, berlin), time.Date(2016, 3, 27, 23, 59, 59, 0, berlin))
+
+	// assert
+	if err != nil || len(timeEntries) != 1 {
+		t.Fatalf("GetTimeEntries should have returned one time entry but returned %d (%v)", len(timeEntries), err)
+	}
+
+	// the daylight saving time in Berlin starts at 02:00 (01:00 UTC)
+	start, stop := timeEntries[0].Start, timeEntries[0].Stop
+	if start.Location() != berlin || start.Format("15:04 -07:00") != "01:30 +01:00" || stop.Format("15:04 -07:00") != "03:30 +02:00" {
+		t.Fail()
+		t.Logf("GetTimeEntries should have returned the time entry from 01:30 +01:00 to 03:30 +02:00 in Berlin but returned %s - %s", start, stop)
+	}
+}
@@ -0,0 +1,143 @@
+package togglapi
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/andreaskoch/togglapi/date"
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_StartTimeEntry_POSTRequestIsSentToStartRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "POST" || route != "time_entries/start" {
+				t.Fail()
+				t.Logf("StartTimeEntry should send a POST request to time_entries/start but sent %s %s", method, route)
+			}
+
+			return []byte(`{"data": {"id": 1, "duration": -1473147236}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	timeEntry, err := timeEntryAPI.StartTimeEntry(model.TimeEntry{Description: "Meeting"})
+
+	// assert
+	if err != nil || timeEntry.Duration >= 0 {
+		t.Fail()
+		t.Logf("StartTimeEntry should have returned a running time entry (Error: %v)", err)
+	}
+}
+
+func Test_StopTimeEntry_PUTRequestIsSentToStopRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "PUT" || route != "time_entries/12/stop" {
+				t.Fail()
+				t.Logf("StopTimeEntry should send a PUT request to time_entries/12/stop but sent %s %s", method, route)
+			}
+
+			return []byte(`{"data": {"id": 12, "duration": 300}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	timeEntry, err := timeEntryAPI.StopTimeEntry(12)
+
+	// assert
+	if err != nil || timeEntry.Duration != 300 {
+		t.Fail()
+		t.Logf("StopTimeEntry should have returned the stopped time entry (Error: %v)", err)
+	}
+}
+
+func Test_StopTimeEntry_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	_, err := timeEntryAPI.StopTimeEntry(12)
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("StopTimeEntry should return an error if the REST client returned an error")
+	}
+}
+
+func Test_GetCurrentTimeEntry_NoTimeEntryIsRunning_EmptyTimeEntryIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(`{"data": null}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	timeEntry, err := timeEntryAPI.GetCurrentTimeEntry()
+
+	// assert
+	if err != nil || timeEntry.ID != 0 {
+		t.Fail()
+		t.Logf("GetCurrentTimeEntry should return an empty time entry if no time entry is running (Error: %v)", err)
+	}
+}
+
+func Test_GetCurrentTimeEntry_TimeEntryIsRunning_TimeEntryIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "GET" || route != "time_entries/current" {
+				t.Fail()
+				t.Logf("GetCurrentTimeEntry should send a GET request to time_entries/current but sent %s %s", method, route)
+			}
+
+			return []byte(`{"data": {"id": 7, "description": "Coding", "duration": -1473147236}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	timeEntry, err := timeEntryAPI.GetCurrentTimeEntry()
+
+	// assert
+	if err != nil || timeEntry.ID != 7 {
+		t.Fail()
+		t.Logf("GetCurrentTimeEntry should have returned the running time entry (Error: %v)", err)
+	}
+}
@@ -0,0 +1,239 @@
+package togglapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/date"
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_UpdateTimeEntry_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	_, err := timeEntryAPI.UpdateTimeEntry(model.TimeEntry{ID: 1})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("UpdateTimeEntry should return an error if the REST client returned an error")
+	}
+}
+
+func Test_UpdateTimeEntry_PUTRequestIsSentToTimeEntryRoute(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			// assert
+			if method != "PUT" || route != "time_entries/436694100" {
+				t.Fail()
+				t.Logf("UpdateTimeEntry should send a PUT request to time_entries/436694100 but sent %s %s", method, route)
+			}
+
+			return []byte(`{"data": {"id": 436694100}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	timeEntryAPI.UpdateTimeEntry(model.TimeEntry{ID: 436694100})
+}
+
+func Test_UpdateTimeEntry_DurationIsCalculatedFromStartAndStop(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			body, _ := ioutil.ReadAll(payload)
+
+			var timeEntryRequest struct {
+				TimeEntry struct {
+					Duration int `json:"duration"`
+				} `json:"time_entry"`
+			}
+
+			json.Unmarshal(body, &timeEntryRequest)
+
+			// assert
+			if timeEntryRequest.TimeEntry.Duration != 900 {
+				t.Fail()
+				t.Logf("UpdateTimeEntry should have sent a duration of 900 seconds but sent %d", timeEntryRequest.TimeEntry.Duration)
+			}
+
+			return []byte(`{"data": {"id": 1}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+	input := model.TimeEntry{
+		ID:    1,
+		Start: start,
+		Stop:  start.Add(time.Minute * 15),
+	}
+
+	// act
+	timeEntryAPI.UpdateTimeEntry(input)
+}
+
+func Test_UpdateTimeEntry_RunningTimeEntry_NoStopIsSent(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 1}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+	input := model.TimeEntry{ID: 1, Wid: 1, Start: start, Duration: -int(start.Unix())}
+
+	// act
+	timeEntryAPI.UpdateTimeEntry(input)
+
+	// assert
+	expected := `"duration":-1473143400`
+	if strings.Contains(body, `"stop"`) || !strings.Contains(body, expected) {
+		t.Fail()
+		t.Logf("UpdateTimeEntry should have sent %s without a stop but sent %s", expected, body)
+	}
+}
+
+func Test_UpdateTimeEntry_UnsetProjectAndTagsAreCleared(t *testing.T) {
+	// arrange
+	var body string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			content, _ := ioutil.ReadAll(payload)
+			body = string(content)
+			return []byte(`{"data": {"id": 1}}`), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+
+	// act
+	timeEntryAPI.UpdateTimeEntry(model.TimeEntry{ID: 1, Wid: 1, Start: start, Stop: start.Add(time.Hour)})
+
+	// assert
+	if !strings.Contains(body, `"pid":0`) || !strings.Contains(body, `"tags":[]`) {
+		t.Fail()
+		t.Logf("UpdateTimeEntry should have sent the unset project and tags but sent %s", body)
+	}
+}
+
+func Test_UpdateTimeEntry_ValidJSONIsReturned_TimeEntryIsReturned(t *testing.T) {
+	// arrange
+	timeEntryJSON := `{
+	"data": {
+		"id": 1,
+		"wid": 1,
+		"pid": 1,
+		"start": "2016-09-06T06:33:56+00:00",
+		"stop": "2016-09-06T06:48:51+00:00",
+		"description": "Updated"
+	}
+}`
+
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(timeEntryJSON), nil
+		},
+	}
+
+	timeEntryAPI := &TimeEntryAPI{
+		restClient:    restClient,
+		dateFormatter: date.NewISO8601Formatter(),
+	}
+
+	// act
+	timeEntry, err := timeEntryAPI.UpdateTimeEntry(model.TimeEntry{ID: 1})
+
+	// assert
+	if err != nil || timeEntry.Description != "Updated" {
+		t.Fail()
+		t.Logf("UpdateTimeEntry should have returned the updated time entry (Error: %v)", err)
+	}
+}
+
+func Test_PatchTimeEntry_OnlyChangedFieldsAreSent(t *testing.T) {
+	// arrange
+	start := time.Date(2016, 9, 6, 6, 30, 0, 0, time.UTC)
+	inputs := []struct {
+		Changes      model.TimeEntryChanges
+		ExpectedBody string
+	}{
+		{
+			Changes:      model.TimeEntryChanges{Pid: model.Int(0), Tags: model.Strings(nil)},
+			ExpectedBody: `{"time_entry":{"pid":0,"tags":[]}}`,
+		},
+		{
+			Changes:      model.TimeEntryChanges{Billable: model.Bool(false), Description: model.String("Review")},
+			ExpectedBody: `{"time_entry":{"billable":false,"description":"Review"}}`,
+		},
+		{
+			Changes:      model.TimeEntryChanges{Start: model.Time(start), Stop: model.Time(start.Add(15 * time.Minute))},
+			ExpectedBody: `{"time_entry":{"start":"2016-09-06T06:30:00Z","stop":"2016-09-06T06:45:00Z","duration":900}}`,
+		},
+	}
+
+	for _, input := range inputs {
+		var body string
+		restClient := &mockRESTRequester{
+			request: func(method, route string, payload io.Reader) ([]byte, error) {
+				content, _ := ioutil.ReadAll(payload)
+				body = string(content)
+				return []byte(`{"data": {"id": 1}}`), nil
+			},
+		}
+
+		timeEntryAPI := &TimeEntryAPI{
+			restClient:    restClient,
+			dateFormatter: date.NewISO8601Formatter(),
+		}
+
+		// act
+		_, err := timeEntryAPI.PatchTimeEntry(1, input.Changes)
+
+		// assert
+		if err != nil || body != input.ExpectedBody {
+			t.Fail()
+			t.Logf("PatchTimeEntry should have sent %s but sent %s (%v)", input.ExpectedBody, body, err)
+		}
+	}
+}
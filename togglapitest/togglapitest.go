@@ -0,0 +1,514 @@
+// Package togglapitest provides an in-memory implementation of the
+// Toggl API for testing code which depends on model.TogglAPI.
+package togglapitest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// NewAPI creates a new in-memory Toggl API with the given workspaces.
+func NewAPI(workspaces ...model.Workspace) *API {
+	return &API{
+		Workspaces: workspaces,
+		Errors:     make(map[string]error),
+		Now:        time.Now,
+		nextID:     1000,
+	}
+}
+
+// API is an in-memory implementation of model.TogglAPI.
+// The exported fields can be used for setting up and inspecting
+// the stored entities; the API methods must not be called
+// concurrently while they are modified.
+type API struct {
+	Workspaces  []model.Workspace
+	Clients     []model.Client
+	Projects    []model.Project
+	TimeEntries []model.TimeEntry
+
+	// Errors contains errors which are returned by the
+	// API method with the given name (e.g. "CreateTimeEntry").
+	Errors map[string]error
+
+	// Calls contains the names of all API methods which have been called.
+	Calls []string
+
+	// Now returns the time which is used as the modification
+	// time (At) of created and updated entities.
+	Now func() time.Time
+
+	mutex  sync.Mutex
+	nextID int
+
+	// deletions contains the deleted records for GetChanges.
+	deletions []deletion
+}
+
+// deletion records the deletion of a client, project or time entry.
+type deletion struct {
+	kind string
+	id   int
+	at   time.Time
+}
+
+// call records the call of the given method and returns the configured error.
+func (api *API) call(method string) error {
+	api.Calls = append(api.Calls, method)
+	return api.Errors[method]
+}
+
+// newID returns a new unique ID.
+func (api *API) newID() int {
+	api.nextID++
+	return api.nextID
+}
+
+// GetWorkspaces returns all workspaces.
+func (api *API) GetWorkspaces() ([]model.Workspace, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetWorkspaces"); err != nil {
+		return nil, err
+	}
+
+	return append([]model.Workspace(nil), api.Workspaces...), nil
+}
+
+// CreateClient stores the given client with a new ID.
+func (api *API) CreateClient(client model.Client) (model.Client, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("CreateClient"); err != nil {
+		return model.Client{}, err
+	}
+
+	client.ID = api.newID()
+	client.At = api.Now()
+	api.Clients = append(api.Clients, client)
+	return client, nil
+}
+
+// UpdateClient replaces the stored client with the given client.
+func (api *API) UpdateClient(client model.Client) (model.Client, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("UpdateClient"); err != nil {
+		return model.Client{}, err
+	}
+
+	index, indexError := api.clientIndex(client.ID)
+	if indexError != nil {
+		return model.Client{}, indexError
+	}
+
+	client.At = api.Now()
+	api.Clients[index] = client
+	return client, nil
+}
+
+// PatchClient applies the given changes to the stored client.
+func (api *API) PatchClient(id int, changes model.ClientChanges) (model.Client, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("PatchClient"); err != nil {
+		return model.Client{}, err
+	}
+
+	index, indexError := api.clientIndex(id)
+	if indexError != nil {
+		return model.Client{}, indexError
+	}
+
+	client := changes.Apply(api.Clients[index])
+	client.At = api.Now()
+	api.Clients[index] = client
+	return client, nil
+}
+
+// DeleteClient removes the client with the given ID.
+func (api *API) DeleteClient(id int) error {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("DeleteClient"); err != nil {
+		return err
+	}
+
+	index, indexError := api.clientIndex(id)
+	if indexError != nil {
+		return indexError
+	}
+
+	api.Clients = append(api.Clients[:index], api.Clients[index+1:]...)
+	api.deletions = append(api.deletions, deletion{"client", id, api.Now()})
+	return nil
+}
+
+// GetClients returns all clients.
+func (api *API) GetClients() ([]model.Client, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetClients"); err != nil {
+		return nil, err
+	}
+
+	return append([]model.Client(nil), api.Clients...), nil
+}
+
+// CreateProject stores the given project with a new ID.
+func (api *API) CreateProject(project model.Project) (model.Project, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("CreateProject"); err != nil {
+		return model.Project{}, err
+	}
+
+	project.ID = api.newID()
+	project.At = api.Now()
+	api.Projects = append(api.Projects, project)
+	return project, nil
+}
+
+// UpdateProject replaces the stored project with the given project.
+func (api *API) UpdateProject(project model.Project) (model.Project, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("UpdateProject"); err != nil {
+		return model.Project{}, err
+	}
+
+	index, indexError := api.projectIndex(project.ID)
+	if indexError != nil {
+		return model.Project{}, indexError
+	}
+
+	project.At = api.Now()
+	api.Projects[index] = project
+	return project, nil
+}
+
+// PatchProject applies the given changes to the stored project.
+func (api *API) PatchProject(id int, changes model.ProjectChanges) (model.Project, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("PatchProject"); err != nil {
+		return model.Project{}, err
+	}
+
+	index, indexError := api.projectIndex(id)
+	if indexError != nil {
+		return model.Project{}, indexError
+	}
+
+	project := changes.Apply(api.Projects[index])
+	project.At = api.Now()
+	api.Projects[index] = project
+	return project, nil
+}
+
+// DeleteProject removes the project with the given ID.
+func (api *API) DeleteProject(id int) error {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("DeleteProject"); err != nil {
+		return err
+	}
+
+	index, indexError := api.projectIndex(id)
+	if indexError != nil {
+		return indexError
+	}
+
+	api.Projects = append(api.Projects[:index], api.Projects[index+1:]...)
+	api.deletions = append(api.deletions, deletion{"project", id, api.Now()})
+	return nil
+}
+
+// GetProjects returns the projects of the given workspace.
+func (api *API) GetProjects(workspaceID int) ([]model.Project, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetProjects"); err != nil {
+		return nil, err
+	}
+
+	var projects []model.Project
+	for _, project := range api.Projects {
+		if project.WorkspaceID == workspaceID {
+			projects = append(projects, project)
+		}
+	}
+
+	return projects, nil
+}
+
+// CreateTimeEntry stores the given time entry with a new ID.
+func (api *API) CreateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("CreateTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	timeEntry.ID = api.newID()
+	timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+	timeEntry.At = api.Now()
+	api.TimeEntries = append(api.TimeEntries, timeEntry)
+	return timeEntry, nil
+}
+
+// GetTimeEntry returns the time entry with the given ID.
+func (api *API) GetTimeEntry(id int) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	index, indexError := api.timeEntryIndex(id)
+	if indexError != nil {
+		return model.TimeEntry{}, indexError
+	}
+
+	return api.TimeEntries[index], nil
+}
+
+// UpdateTimeEntry replaces the stored time entry with the given time entry.
+func (api *API) UpdateTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("UpdateTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	index, indexError := api.timeEntryIndex(timeEntry.ID)
+	if indexError != nil {
+		return model.TimeEntry{}, indexError
+	}
+
+	timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+	timeEntry.At = api.Now()
+	api.TimeEntries[index] = timeEntry
+	return timeEntry, nil
+}
+
+// PatchTimeEntry applies the given changes to the stored time entry.
+func (api *API) PatchTimeEntry(id int, changes model.TimeEntryChanges) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("PatchTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	index, indexError := api.timeEntryIndex(id)
+	if indexError != nil {
+		return model.TimeEntry{}, indexError
+	}
+
+	timeEntry := changes.Apply(api.TimeEntries[index])
+	timeEntry.At = api.Now()
+	api.TimeEntries[index] = timeEntry
+	return timeEntry, nil
+}
+
+// DeleteTimeEntry removes the time entry with the given ID.
+func (api *API) DeleteTimeEntry(id int) error {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("DeleteTimeEntry"); err != nil {
+		return err
+	}
+
+	index, indexError := api.timeEntryIndex(id)
+	if indexError != nil {
+		return indexError
+	}
+
+	api.TimeEntries = append(api.TimeEntries[:index], api.TimeEntries[index+1:]...)
+	api.deletions = append(api.deletions, deletion{"time entry", id, api.Now()})
+	return nil
+}
+
+// StartTimeEntry stores the given time entry as a running time entry.
+func (api *API) StartTimeEntry(timeEntry model.TimeEntry) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("StartTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	timeEntry.ID = api.newID()
+	timeEntry.Start = time.Now().Truncate(time.Second)
+	timeEntry.Stop = time.Time{}
+	timeEntry.Duration = -int(timeEntry.Start.Unix())
+	api.TimeEntries = append(api.TimeEntries, timeEntry)
+	return timeEntry, nil
+}
+
+// StopTimeEntry stops the running time entry with the given ID.
+func (api *API) StopTimeEntry(id int) (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("StopTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	index, indexError := api.timeEntryIndex(id)
+	if indexError != nil {
+		return model.TimeEntry{}, indexError
+	}
+
+	timeEntry := api.TimeEntries[index]
+	if timeEntry.Duration < 0 {
+		timeEntry.Stop = time.Now().Truncate(time.Second)
+		timeEntry.Duration = int(timeEntry.Stop.Sub(timeEntry.Start).Seconds())
+		api.TimeEntries[index] = timeEntry
+	}
+
+	return timeEntry, nil
+}
+
+// GetCurrentTimeEntry returns the running time entry.
+func (api *API) GetCurrentTimeEntry() (model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetCurrentTimeEntry"); err != nil {
+		return model.TimeEntry{}, err
+	}
+
+	for _, timeEntry := range api.TimeEntries {
+		if timeEntry.Duration < 0 {
+			return timeEntry, nil
+		}
+	}
+
+	return model.TimeEntry{}, nil
+}
+
+// GetTimeEntries returns all time entries which started between the given start and end date.
+func (api *API) GetTimeEntries(start, end time.Time) ([]model.TimeEntry, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetTimeEntries"); err != nil {
+		return nil, err
+	}
+
+	var timeEntries []model.TimeEntry
+	for _, timeEntry := range api.TimeEntries {
+		if timeEntry.Start.Before(start) || timeEntry.Start.After(end) {
+			continue
+		}
+
+		timeEntries = append(timeEntries, timeEntry)
+	}
+
+	return timeEntries, nil
+}
+
+// GetChanges returns the workspaces and the clients, projects and time
+// entries which have been stored or deleted after the given time.
+// The Since of the change set is the current time (Now).
+func (api *API) GetChanges(since time.Time) (model.ChangeSet, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if err := api.call("GetChanges"); err != nil {
+		return model.ChangeSet{}, err
+	}
+
+	changes := model.ChangeSet{
+		Since:      api.Now(),
+		Workspaces: append([]model.Workspace(nil), api.Workspaces...),
+	}
+
+	for _, client := range api.Clients {
+		if client.At.After(since) {
+			changes.Clients = append(changes.Clients, client)
+		}
+	}
+
+	for _, project := range api.Projects {
+		if project.At.After(since) {
+			changes.Projects = append(changes.Projects, project)
+		}
+	}
+
+	for _, timeEntry := range api.TimeEntries {
+		if timeEntry.At.After(since) {
+			changes.TimeEntries = append(changes.TimeEntries, timeEntry)
+		}
+	}
+
+	for _, deleted := range api.deletions {
+		if !deleted.at.After(since) {
+			continue
+		}
+
+		switch deleted.kind {
+		case "client":
+			changes.DeletedClients = append(changes.DeletedClients, deleted.id)
+		case "project":
+			changes.DeletedProjects = append(changes.DeletedProjects, deleted.id)
+		case "time entry":
+			changes.DeletedTimeEntries = append(changes.DeletedTimeEntries, deleted.id)
+		}
+	}
+
+	return changes, nil
+}
+
+// timeEntryIndex returns the index of the time entry with the given ID.
+func (api *API) timeEntryIndex(id int) (int, error) {
+	for index, timeEntry := range api.TimeEntries {
+		if timeEntry.ID == id {
+			return index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Time entry %d does not exist", id)
+}
+
+// clientIndex returns the index of the client with the given ID.
+func (api *API) clientIndex(id int) (int, error) {
+	for index, client := range api.Clients {
+		if client.ID == id {
+			return index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Client %d does not exist", id)
+}
+
+// projectIndex returns the index of the project with the given ID.
+func (api *API) projectIndex(id int) (int, error) {
+	for index, project := range api.Projects {
+		if project.ID == id {
+			return index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Project %d does not exist", id)
+}
@@ -0,0 +1,65 @@
+package togglapitest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// the in-memory API must implement the Toggl API
+var _ model.TogglAPI = &API{}
+
+func Test_CreateTimeEntry_TimeEntryIsReturnedByGetTimeEntries(t *testing.T) {
+	// arrange
+	api := NewAPI(model.Workspace{ID: 1, Name: "Acme"})
+	start := time.Date(2016, 9, 6, 8, 0, 0, 0, time.UTC)
+
+	// act
+	created, _ := api.CreateTimeEntry(model.TimeEntry{Wid: 1, Start: start, Stop: start.Add(time.Hour)})
+	timeEntries, err := api.GetTimeEntries(start.Add(-time.Hour), start.Add(time.Hour))
+
+	// assert
+	if err != nil || len(timeEntries) != 1 || timeEntries[0].ID != created.ID || timeEntries[0].Duration != 3600 {
+		t.Fail()
+		t.Logf("GetTimeEntries should have returned the created time entry but returned %#v (Error: %v)", timeEntries, err)
+	}
+}
+
+func Test_StopTimeEntry_RunningTimeEntryIsStopped(t *testing.T) {
+	// arrange
+	api := NewAPI()
+	started, _ := api.StartTimeEntry(model.TimeEntry{Description: "Coding"})
+
+	// act
+	current, _ := api.GetCurrentTimeEntry()
+	stopped, err := api.StopTimeEntry(started.ID)
+	afterStop, _ := api.GetCurrentTimeEntry()
+
+	// assert
+	if err != nil || current.ID != started.ID || stopped.Duration < 0 || afterStop.ID != 0 {
+		t.Fail()
+		t.Logf("StopTimeEntry should have stopped the running time entry (Error: %v)", err)
+	}
+}
+
+func Test_Errors_ConfiguredErrorIsReturned(t *testing.T) {
+	// arrange
+	api := NewAPI()
+	api.Errors["GetClients"] = errTest
+
+	// act
+	_, err := api.GetClients()
+
+	// assert
+	if err != errTest || len(api.Calls) != 1 {
+		t.Fail()
+		t.Logf("GetClients should have returned the configured error but returned %v", err)
+	}
+}
+
+type testError struct{}
+
+func (testError) Error() string { return "test error" }
+
+var errTest = testError{}
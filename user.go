@@ -0,0 +1,144 @@
+package togglapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// NewUserAPI create a new client for the Toggl user API.
+func NewUserAPI(baseURL, token string) model.UserAPI {
+	return &UserAPI{
+		restClient: &togglRESTAPIClient{
+			baseURL:              baseURL,
+			token:                token,
+			pauseBetweenRequests: pauseBetweenRequests,
+		},
+	}
+}
+
+// NewUserAPIWithRequester create a new client for the Toggl user API
+// which sends all requests with the given requester.
+func NewUserAPIWithRequester(requester RESTRequester) model.UserAPI {
+	return &UserAPI{requester}
+}
+
+// UserAPI provides functions for interacting with Toggls' user API.
+type UserAPI struct {
+	restClient RESTRequester
+}
+
+// GetCurrentUser returns the user the API token belongs to.
+func (repository *UserAPI) GetCurrentUser() (model.User, error) {
+	content, err := repository.restClient.Request(http.MethodGet, "me", nil)
+	if err != nil {
+		return model.User{}, errors.Wrap(err, "Failed to retrieve the current user")
+	}
+
+	var userResponse struct {
+		User model.User `json:"data"`
+	}
+
+	if unmarshalError := json.Unmarshal(content, &userResponse); unmarshalError != nil {
+		return model.User{}, errors.Wrap(unmarshalError, "Failed to deserialize the current user")
+	}
+
+	return userResponse.User, nil
+}
+
+// GetChanges returns the workspaces, clients, projects and time entries
+// of the current user which have been changed or deleted since the
+// given time. A zero time returns all records.
+func (repository *UserAPI) GetChanges(since time.Time) (model.ChangeSet, error) {
+	route := "me?with_related_data=true"
+	if !since.IsZero() {
+		route += fmt.Sprintf("&since=%d", since.Unix())
+	}
+
+	content, err := repository.restClient.Request(http.MethodGet, route, nil)
+	if err != nil {
+		return model.ChangeSet{}, errors.Wrap(err, "Failed to retrieve the changes")
+	}
+
+	// deleted records are marked with the time of their deletion
+	var changesResponse struct {
+		Since int64 `json:"since"`
+		Data  struct {
+			Workspaces []model.Workspace `json:"workspaces"`
+			Clients    []struct {
+				model.Client
+				ServerDeletedAt *time.Time `json:"server_deleted_at"`
+			} `json:"clients"`
+			Projects []struct {
+				model.Project
+				ServerDeletedAt *time.Time `json:"server_deleted_at"`
+			} `json:"projects"`
+			TimeEntries []struct {
+				model.TimeEntry
+				ServerDeletedAt *time.Time `json:"server_deleted_at"`
+			} `json:"time_entries"`
+		} `json:"data"`
+	}
+
+	if unmarshalError := json.Unmarshal(content, &changesResponse); unmarshalError != nil {
+		return model.ChangeSet{}, errors.Wrap(unmarshalError, "Failed to deserialize the changes")
+	}
+
+	changes := model.ChangeSet{
+		Since:      time.Unix(changesResponse.Since, 0).UTC(),
+		Workspaces: changesResponse.Data.Workspaces,
+	}
+
+	for _, client := range changesResponse.Data.Clients {
+		if client.ServerDeletedAt != nil {
+			changes.DeletedClients = append(changes.DeletedClients, client.ID)
+			continue
+		}
+
+		changes.Clients = append(changes.Clients, client.Client)
+	}
+
+	for _, project := range changesResponse.Data.Projects {
+		if project.ServerDeletedAt != nil {
+			changes.DeletedProjects = append(changes.DeletedProjects, project.ID)
+			continue
+		}
+
+		changes.Projects = append(changes.Projects, project.Project)
+	}
+
+	for _, timeEntry := range changesResponse.Data.TimeEntries {
+		if timeEntry.ServerDeletedAt != nil {
+			changes.DeletedTimeEntries = append(changes.DeletedTimeEntries, timeEntry.ID)
+			continue
+		}
+
+		changes.TimeEntries = append(changes.TimeEntries, timeEntry.TimeEntry)
+	}
+
+	return changes, nil
+}
+
+// GetUserLocation returns the location of the time zone configured
+// in the Toggl profile of the current user.
+func GetUserLocation(api model.UserAPI) (*time.Location, error) {
+	user, userError := api.GetCurrentUser()
+	if userError != nil {
+		return nil, userError
+	}
+
+	if user.Timezone == "" {
+		return nil, fmt.Errorf("The user %q has no time zone", user.Email)
+	}
+
+	location, locationError := time.LoadLocation(user.Timezone)
+	if locationError != nil {
+		return nil, errors.Wrap(locationError, fmt.Sprintf("Failed to load the time zone %q of the user", user.Timezone))
+	}
+
+	return location, nil
+}
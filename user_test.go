@@ -0,0 +1,176 @@
+package togglapi
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func Test_NewUserAPI(t *testing.T) {
+	// act
+	client := NewUserAPI("http://api.example.com", "sakldjaksljkl312312")
+
+	// assert
+	if client == nil {
+		t.Fail()
+		t.Logf("NewUserAPI should have returned a user API client")
+	}
+}
+
+func Test_GetCurrentUser_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	userAPI := &UserAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := userAPI.GetCurrentUser()
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("GetCurrentUser should return an error if the rest client returns one")
+	}
+}
+
+func Test_GetCurrentUser_ValidJSONIsReturned_UserIsReturned(t *testing.T) {
+	// arrange
+	userJSON := `{
+  "since": 1361780172,
+  "data": {
+    "id": 123,
+    "email": "johnt@swift.com",
+    "fullname": "John Swift",
+    "timezone": "Europe/Berlin",
+    "beginning_of_week": 1
+  }
+}`
+
+	var requestedRoute string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requestedRoute = route
+			return []byte(userJSON), nil
+		},
+	}
+
+	userAPI := &UserAPI{
+		restClient: restClient,
+	}
+
+	// act
+	user, err := userAPI.GetCurrentUser()
+
+	// assert
+	if err != nil || requestedRoute != "me" || user.ID != 123 || user.Timezone != "Europe/Berlin" || user.BeginningOfWeek != 1 {
+		t.Fail()
+		t.Logf("GetCurrentUser should have returned user 123 from the route \"me\" but returned %+v (route: %q, error: %v)", user, requestedRoute, err)
+	}
+}
+
+func Test_GetUserLocation_UnknownTimezone_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(`{"data": {"id": 123, "timezone": "Mars/Olympus_Mons"}}`), nil
+		},
+	}
+
+	// act
+	_, err := GetUserLocation(&UserAPI{restClient})
+
+	// assert
+	if err == nil {
+		t.Fail()
+		t.Logf("GetUserLocation should return an error for an unknown time zone")
+	}
+}
+
+func Test_GetUserLocation_ValidTimezone_LocationIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(`{"data": {"id": 123, "timezone": "America/New_York"}}`), nil
+		},
+	}
+
+	// act
+	location, err := GetUserLocation(&UserAPI{restClient})
+
+	// assert
+	if err != nil || location.String() != "America/New_York" {
+		t.Fail()
+		t.Logf("GetUserLocation should have returned America/New_York but returned %v (%v)", location, err)
+	}
+}
+
+func Test_GetChanges_SinceGiven_ChangedAndDeletedRecordsAreReturned(t *testing.T) {
+	// arrange
+	changesJSON := `{
+  "since": 1473148800,
+  "data": {
+    "id": 123,
+    "workspaces": [{"id": 1, "name": "Work"}],
+    "clients": [{"id": 5, "wid": 1, "name": "Acme"}, {"id": 6, "wid": 1, "name": "Globex", "server_deleted_at": "2016-09-06T07:00:00+00:00"}],
+    "projects": [{"id": 10, "wid": 1, "cid": 5, "name": "Website"}],
+    "time_entries": [{"id": 100, "wid": 1, "pid": 10, "description": "Design"}, {"id": 101, "wid": 1, "server_deleted_at": "2016-09-06T07:30:00+00:00"}]
+  }
+}`
+
+	var requestedRoute string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requestedRoute = route
+			return []byte(changesJSON), nil
+		},
+	}
+
+	userAPI := &UserAPI{
+		restClient: restClient,
+	}
+
+	// act
+	changes, err := userAPI.GetChanges(time.Unix(1473141600, 0))
+
+	// assert
+	if err != nil || requestedRoute != "me?with_related_data=true&since=1473141600" {
+		t.Fatalf("GetChanges requested %q (Error: %v)", requestedRoute, err)
+	}
+
+	if !changes.Since.Equal(time.Unix(1473148800, 0)) || len(changes.Workspaces) != 1 || len(changes.Clients) != 1 || len(changes.Projects) != 1 ||
+		len(changes.TimeEntries) != 1 || fmt.Sprint(changes.DeletedClients) != "[6]" || fmt.Sprint(changes.DeletedTimeEntries) != "[101]" {
+		t.Fail()
+		t.Logf("GetChanges returned an unexpected change set: %+v", changes)
+	}
+}
+
+func Test_GetChanges_ZeroSince_AllRecordsAreRequested(t *testing.T) {
+	// arrange
+	var requestedRoute string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requestedRoute = route
+			return []byte(`{"since": 1473148800, "data": {}}`), nil
+		},
+	}
+
+	userAPI := &UserAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := userAPI.GetChanges(time.Time{})
+
+	// assert
+	if err != nil || requestedRoute != "me?with_related_data=true" {
+		t.Fail()
+		t.Logf("GetChanges should have requested all records but requested %q (Error: %v)", requestedRoute, err)
+	}
+}
@@ -0,0 +1,309 @@
+// Package validation checks time entries for common timesheet problems:
+// overlapping entries, gaps inside the working hours, zero-length entries,
+// entries without project and entries exceeding a maximum length.
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// Kind defines the kind of a finding.
+type Kind string
+
+// The kinds of findings.
+const (
+	Overlap    Kind = "overlap"
+	Gap        Kind = "gap"
+	ZeroLength Kind = "zero-length"
+	NoProject  Kind = "no-project"
+	TooLong    Kind = "too-long"
+)
+
+// Finding describes a single problem.
+type Finding struct {
+	Kind Kind
+
+	// TimeEntryIDs contains the IDs of the affected time entries (none for gaps).
+	TimeEntryIDs []int
+
+	// Start and End contain the affected period.
+	Start time.Time
+	End   time.Time
+
+	Message string
+}
+
+// String returns a single line description of the finding.
+func (finding Finding) String() string {
+	return fmt.Sprintf("%s %s - %s %-11s %s", finding.Start.Format("2006-01-02"), finding.Start.Format("15:04"), finding.End.Format("15:04"), finding.Kind, finding.Message)
+}
+
+// WorkingHours defines the daily period in which gaps are reported.
+type WorkingHours struct {
+	// Start and End contain the offsets from midnight (e.g. 9h and 17h).
+	Start time.Duration
+	End   time.Duration
+
+	// Weekdays contains the working days.
+	Weekdays []time.Weekday
+}
+
+// ParseWorkingHours parses working hours like "09:00-17:00"
+// on the working days Monday to Friday.
+func ParseWorkingHours(value string) (WorkingHours, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return WorkingHours{}, fmt.Errorf("%q are not valid working hours (e.g. 09:00-17:00)", value)
+	}
+
+	var offsets [2]time.Duration
+	for index, part := range parts {
+		parsed, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return WorkingHours{}, fmt.Errorf("%q is not a valid time of day (e.g. 09:00)", part)
+		}
+
+		offsets[index] = time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute
+	}
+
+	if offsets[1] <= offsets[0] {
+		return WorkingHours{}, fmt.Errorf("The working hours %q end before they start", value)
+	}
+
+	return WorkingHours{
+		Start:    offsets[0],
+		End:      offsets[1],
+		Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}, nil
+}
+
+// isWorkingDay returns true if the given weekday is a working day.
+func (workingHours WorkingHours) isWorkingDay(weekday time.Weekday) bool {
+	for _, workingDay := range workingHours.Weekdays {
+		if workingDay == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Options contains the settings of the checks.
+type Options struct {
+	// WorkingHours enables the gap detection. Nil disables it.
+	WorkingHours *WorkingHours
+
+	// MinimumGap contains the shortest gap which is reported.
+	MinimumGap time.Duration
+
+	// MaximumDuration enables the detection of too long entries. Zero disables it.
+	MaximumDuration time.Duration
+
+	// Start and End define the checked period for the gap detection.
+	// If they are not set only the days with time entries are checked.
+	Start time.Time
+	End   time.Time
+
+	// Location is used for the working hours. Defaults to time.Local.
+	Location *time.Location
+
+	// Now is used as the end of running time entries. Defaults to the current time.
+	Now time.Time
+}
+
+// interval contains the period of a time entry.
+type interval struct {
+	id    int
+	start time.Time
+	end   time.Time
+}
+
+// Check returns the findings for the given time entries ordered by start.
+func Check(timeEntries []model.TimeEntry, options Options) []Finding {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	var intervals []interval
+	var findings []Finding
+	for _, timeEntry := range timeEntries {
+		current := interval{id: timeEntry.ID, start: timeEntry.Start.In(options.Location), end: timeEntry.Stop.In(options.Location)}
+		if timeEntry.Duration < 0 || timeEntry.Stop.IsZero() {
+			current.end = options.Now.In(options.Location)
+		}
+
+		intervals = append(intervals, current)
+		findings = append(findings, checkTimeEntry(timeEntry, current, options)...)
+	}
+
+	sort.SliceStable(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	findings = append(findings, findOverlaps(intervals)...)
+	if options.WorkingHours != nil {
+		findings = append(findings, findGaps(intervals, *options.WorkingHours, options)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Start.Before(findings[j].Start) })
+	return findings
+}
+
+// checkTimeEntry returns the findings concerning a single time entry.
+func checkTimeEntry(timeEntry model.TimeEntry, current interval, options Options) []Finding {
+	var findings []Finding
+	duration := current.end.Sub(current.start)
+	description := describe(timeEntry)
+
+	if duration <= 0 {
+		findings = append(findings, Finding{
+			Kind:         ZeroLength,
+			TimeEntryIDs: []int{timeEntry.ID},
+			Start:        current.start,
+			End:          current.end,
+			Message:      fmt.Sprintf("Time entry %d %s has no duration", timeEntry.ID, description),
+		})
+	}
+
+	if timeEntry.Pid == 0 {
+		findings = append(findings, Finding{
+			Kind:         NoProject,
+			TimeEntryIDs: []int{timeEntry.ID},
+			Start:        current.start,
+			End:          current.end,
+			Message:      fmt.Sprintf("Time entry %d %s has no project", timeEntry.ID, description),
+		})
+	}
+
+	if options.MaximumDuration > 0 && duration > options.MaximumDuration {
+		findings = append(findings, Finding{
+			Kind:         TooLong,
+			TimeEntryIDs: []int{timeEntry.ID},
+			Start:        current.start,
+			End:          current.end,
+			Message:      fmt.Sprintf("Time entry %d %s is longer than %s", timeEntry.ID, description, options.MaximumDuration),
+		})
+	}
+
+	return findings
+}
+
+// findOverlaps returns the overlaps of the given intervals sorted by start.
+func findOverlaps(intervals []interval) []Finding {
+	var findings []Finding
+	var latest interval
+	for index, current := range intervals {
+		if index > 0 && current.start.Before(latest.end) {
+			end := current.end
+			if latest.end.Before(end) {
+				end = latest.end
+			}
+
+			findings = append(findings, Finding{
+				Kind:         Overlap,
+				TimeEntryIDs: []int{latest.id, current.id},
+				Start:        current.start,
+				End:          end,
+				Message:      fmt.Sprintf("Time entries %d and %d overlap by %s", latest.id, current.id, end.Sub(current.start)),
+			})
+		}
+
+		if index == 0 || current.end.After(latest.end) {
+			latest = current
+		}
+	}
+
+	return findings
+}
+
+// findGaps returns the untracked periods inside the working hours
+// which are at least as long as the minimum gap.
+func findGaps(intervals []interval, workingHours WorkingHours, options Options) []Finding {
+	var findings []Finding
+	for _, day := range checkedDays(intervals, options) {
+		if !workingHours.isWorkingDay(day.Weekday()) {
+			continue
+		}
+
+		// move the cursor through the working hours of the day
+		cursor := day.Add(workingHours.Start)
+		closing := day.Add(workingHours.End)
+		for _, current := range intervals {
+			if !current.end.After(cursor) || !current.start.Before(closing) {
+				continue
+			}
+
+			if current.start.After(cursor) {
+				findings = appendGap(findings, cursor, current.start, options.MinimumGap)
+			}
+
+			cursor = current.end
+		}
+
+		if cursor.Before(closing) {
+			findings = appendGap(findings, cursor, closing, options.MinimumGap)
+		}
+	}
+
+	return findings
+}
+
+// appendGap adds a gap finding if the gap is long enough.
+func appendGap(findings []Finding, start, end time.Time, minimum time.Duration) []Finding {
+	if end.Sub(start) < minimum || !end.After(start) {
+		return findings
+	}
+
+	return append(findings, Finding{
+		Kind:    Gap,
+		Start:   start,
+		End:     end,
+		Message: fmt.Sprintf("Nothing tracked for %s", end.Sub(start)),
+	})
+}
+
+// checkedDays returns the midnights of the days of the checked period,
+// or of the days with time entries if no period is configured.
+func checkedDays(intervals []interval, options Options) []time.Time {
+	var days []time.Time
+	if !options.Start.IsZero() && !options.End.IsZero() {
+		for day := midnight(options.Start, options.Location); day.Before(options.End); day = day.AddDate(0, 0, 1) {
+			days = append(days, day)
+		}
+
+		return days
+	}
+
+	seen := make(map[time.Time]bool)
+	for _, current := range intervals {
+		day := midnight(current.start, options.Location)
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	return days
+}
+
+// midnight returns the beginning of the day of the given time in the given location.
+func midnight(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// describe returns the quoted description of the given time entry.
+func describe(timeEntry model.TimeEntry) string {
+	if timeEntry.Description == "" {
+		return "(no description)"
+	}
+
+	return fmt.Sprintf("%q", timeEntry.Description)
+}
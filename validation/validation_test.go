@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+// at returns the given time on Tuesday, 2016-09-06 (UTC).
+func at(hour, minute int) time.Time {
+	return time.Date(2016, 9, 6, hour, minute, 0, 0, time.UTC)
+}
+
+// testEntry returns a stopped time entry of project 1.
+func testEntry(id int, start, stop time.Time) model.TimeEntry {
+	return model.TimeEntry{ID: id, Pid: 1, Start: start, Stop: stop, Duration: int(stop.Sub(start).Seconds()), Description: "Work"}
+}
+
+// countKind returns the number of findings of the given kind.
+func countKind(findings []Finding, kind Kind) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
+
+func Test_Check_OverlappingEntries_OverlapIsReported(t *testing.T) {
+	// arrange
+	timeEntries := []model.TimeEntry{
+		testEntry(2, at(9, 30), at(10, 30)),
+		testEntry(1, at(9, 0), at(12, 0)),
+		testEntry(3, at(12, 0), at(13, 0)),
+	}
+
+	// act
+	findings := Check(timeEntries, Options{Location: time.UTC})
+
+	// assert
+	if len(findings) != 1 || findings[0].Kind != Overlap || findings[0].TimeEntryIDs[0] != 1 || findings[0].TimeEntryIDs[1] != 2 || findings[0].End != at(10, 30) {
+		t.Fail()
+		t.Logf("Check should have reported the overlap of entries 1 and 2: %v", findings)
+	}
+}
+
+func Test_Check_GapsInsideWorkingHours_GapsAreReported(t *testing.T) {
+	// arrange
+	workingHours, _ := ParseWorkingHours("09:00-17:00")
+	timeEntries := []model.TimeEntry{
+		testEntry(1, at(8, 0), at(10, 0)),
+		testEntry(2, at(10, 5), at(12, 0)),
+		testEntry(3, at(13, 0), at(16, 0)),
+	}
+
+	// act
+	findings := Check(timeEntries, Options{WorkingHours: &workingHours, MinimumGap: 15 * time.Minute, Location: time.UTC})
+
+	// assert
+	if len(findings) != 2 || findings[0].Start != at(12, 0) || findings[0].End != at(13, 0) || findings[1].Start != at(16, 0) || findings[1].End != at(17, 0) {
+		t.Fail()
+		t.Logf("Check should have reported the lunch break and the end of the day: %v", findings)
+	}
+}
+
+func Test_Check_PeriodWithEmptyWorkingDay_WholeDayIsReported(t *testing.T) {
+	// arrange
+	workingHours, _ := ParseWorkingHours("09:00-17:00")
+	options := Options{
+		WorkingHours: &workingHours,
+		Start:        time.Date(2016, 9, 9, 0, 0, 0, 0, time.UTC), // Friday
+		End:          time.Date(2016, 9, 12, 0, 0, 0, 0, time.UTC),
+		Location:     time.UTC,
+	}
+
+	// act
+	findings := Check(nil, options)
+
+	// assert
+	if len(findings) != 1 || findings[0].End.Sub(findings[0].Start) != 8*time.Hour {
+		t.Fail()
+		t.Logf("Check should have reported Friday but not the weekend: %v", findings)
+	}
+}
+
+func Test_Check_ProblematicEntries_FindingsAreReported(t *testing.T) {
+	// arrange
+	noProject := testEntry(2, at(11, 0), at(12, 0))
+	noProject.Pid = 0
+
+	timeEntries := []model.TimeEntry{
+		testEntry(1, at(10, 0), at(10, 0)),
+		noProject,
+		testEntry(3, at(13, 0), at(23, 0)),
+	}
+
+	// act
+	findings := Check(timeEntries, Options{MaximumDuration: 8 * time.Hour, Location: time.UTC})
+
+	// assert
+	if len(findings) != 3 || countKind(findings, ZeroLength) != 1 || countKind(findings, NoProject) != 1 || countKind(findings, TooLong) != 1 {
+		t.Fail()
+		t.Logf("Check should have reported the zero-length, project-less and too long entries: %v", findings)
+	}
+}
+
+func Test_Check_RunningEntry_RunsUntilNow(t *testing.T) {
+	// arrange
+	running := model.TimeEntry{ID: 1, Pid: 1, Start: at(9, 0), Duration: -int(at(9, 0).Unix())}
+
+	// act
+	findings := Check([]model.TimeEntry{running}, Options{MaximumDuration: 2 * time.Hour, Location: time.UTC, Now: at(12, 0)})
+
+	// assert
+	if len(findings) != 1 || findings[0].Kind != TooLong {
+		t.Fail()
+		t.Logf("Check should have reported the running entry as too long: %v", findings)
+	}
+}
+
+func Test_ParseWorkingHours(t *testing.T) {
+	workingHours, err := ParseWorkingHours("08:30-16:45")
+	if err != nil || workingHours.Start != 8*time.Hour+30*time.Minute || workingHours.End != 16*time.Hour+45*time.Minute || len(workingHours.Weekdays) != 5 {
+		t.Fail()
+		t.Logf("ParseWorkingHours returned %+v (Error: %v)", workingHours, err)
+	}
+
+	for _, value := range []string{"9-17", "17:00-09:00", "09:00"} {
+		if _, err := ParseWorkingHours(value); err == nil {
+			t.Fail()
+			t.Logf("ParseWorkingHours should have rejected %q", value)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package togglapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// DefaultWebhookBaseURL contains the URL of the Toggl webhooks API
+// which differs from the URL of the other APIs.
+const DefaultWebhookBaseURL = "https://api.track.toggl.com/webhooks/api/v1"
+
+// NewWebhookAPI create a new client for the Toggl webhooks API.
+func NewWebhookAPI(baseURL, token string) model.WebhookAPI {
+	return &WebhookAPI{
+		restClient: &togglRESTAPIClient{
+			baseURL:              baseURL,
+			token:                token,
+			pauseBetweenRequests: pauseBetweenRequests,
+		},
+	}
+}
+
+// NewWebhookAPIWithRequester create a new client for the Toggl webhooks
+// API which sends all requests with the given requester.
+func NewWebhookAPIWithRequester(requester RESTRequester) model.WebhookAPI {
+	return &WebhookAPI{requester}
+}
+
+// WebhookAPI provides functions for managing Toggls' webhook subscriptions.
+// The events of the subscriptions can be received with the webhook package.
+type WebhookAPI struct {
+	restClient RESTRequester
+}
+
+// CreateWebhookSubscription creates a new subscription in the workspace of the given subscription.
+// Returns a *model.ValidationError without sending a request if the subscription is invalid.
+func (repository *WebhookAPI) CreateWebhookSubscription(subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	if err := subscription.Validate(); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	subscriptionModel := struct {
+		Description  string                     `json:"description"`
+		URLCallback  string                     `json:"url_callback"`
+		Secret       string                     `json:"secret,omitempty"`
+		Enabled      bool                       `json:"enabled"`
+		EventFilters []model.WebhookEventFilter `json:"event_filters"`
+	}{
+		Description:  subscription.Description,
+		URLCallback:  subscription.URLCallback,
+		Secret:       subscription.Secret,
+		Enabled:      subscription.Enabled,
+		EventFilters: subscription.EventFilters,
+	}
+
+	jsonBody, marshalError := json.Marshal(subscriptionModel)
+	if marshalError != nil {
+		return model.WebhookSubscription{}, errors.Wrap(marshalError, "Failed to serialize the webhook subscription")
+	}
+
+	route := fmt.Sprintf("subscriptions/%d", subscription.WorkspaceID)
+	content, err := repository.restClient.Request(http.MethodPost, route, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return model.WebhookSubscription{}, errors.Wrap(err, "Failed to create webhook subscription")
+	}
+
+	var createdSubscription model.WebhookSubscription
+	if unmarshalError := json.Unmarshal(content, &createdSubscription); unmarshalError != nil {
+		return model.WebhookSubscription{}, errors.Wrap(unmarshalError, "Failed to deserialize the created webhook subscription")
+	}
+
+	return createdSubscription, nil
+}
+
+// GetWebhookSubscriptions returns all subscriptions of the given workspace.
+func (repository *WebhookAPI) GetWebhookSubscriptions(workspaceID int) ([]model.WebhookSubscription, error) {
+	route := fmt.Sprintf("subscriptions/%d", workspaceID)
+	content, err := repository.restClient.Request(http.MethodGet, route, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Failed to retrieve the webhook subscriptions of workspace %d", workspaceID))
+	}
+
+	var subscriptions []model.WebhookSubscription
+	if unmarshalError := json.Unmarshal(content, &subscriptions); unmarshalError != nil {
+		return nil, errors.Wrap(unmarshalError, "Failed to deserialize the webhook subscriptions")
+	}
+
+	return subscriptions, nil
+}
+
+// PingWebhookSubscription asks Toggl to send a ping event to the given subscription.
+func (repository *WebhookAPI) PingWebhookSubscription(workspaceID, subscriptionID int) error {
+	route := fmt.Sprintf("ping/%d/%d", workspaceID, subscriptionID)
+
+	if _, err := repository.restClient.Request(http.MethodPost, route, nil); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to ping webhook subscription %d", subscriptionID))
+	}
+
+	return nil
+}
+
+// DeleteWebhookSubscription deletes the given subscription.
+func (repository *WebhookAPI) DeleteWebhookSubscription(workspaceID, subscriptionID int) error {
+	route := fmt.Sprintf("subscriptions/%d/%d", workspaceID, subscriptionID)
+
+	if _, err := repository.restClient.Request(http.MethodDelete, route, nil); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to delete webhook subscription %d", subscriptionID))
+	}
+
+	return nil
+}
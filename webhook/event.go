@@ -0,0 +1,255 @@
+// Package webhook receives the events of Toggl webhook subscriptions.
+//
+// The Handler checks the signature of every request, answers the
+// validation requests Toggl sends to new subscriptions, decodes the events
+// of time entries, projects, clients and tags into model types and
+// dispatches them to the registered handler functions. The subscriptions
+// themselves are managed with togglapi.WebhookAPI.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/togglapi/model"
+	"github.com/pkg/errors"
+)
+
+// The models of the events which are decoded into typed events.
+const (
+	TimeEntryModel = "time_entry"
+	ProjectModel   = "project"
+	ClientModel    = "client"
+	TagModel       = "tag"
+)
+
+// The actions of the events.
+const (
+	Created = "created"
+	Updated = "updated"
+	Deleted = "deleted"
+)
+
+// Metadata describes the change which triggered an event.
+type Metadata struct {
+	// Action contains the kind of the change (Created, Updated or Deleted).
+	Action string
+
+	// Model contains the kind of the changed record (e.g. TimeEntryModel).
+	Model string
+
+	// Path and RequestType contain the route and method of the API request
+	// which caused the change.
+	Path        string
+	RequestType string
+
+	WorkspaceID int
+
+	// EventUserID contains the ID of the user who made the change.
+	EventUserID int
+}
+
+// UnmarshalJSON decodes the metadata of an event. Toggl sends the IDs as strings.
+func (metadata *Metadata) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Action      string          `json:"action"`
+		Model       string          `json:"model"`
+		Path        string          `json:"path"`
+		RequestType string          `json:"request_type"`
+		WorkspaceID json.RawMessage `json:"workspace_id"`
+		EventUserID json.RawMessage `json:"event_user_id"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	workspaceID, workspaceError := parseID(raw.WorkspaceID)
+	if workspaceError != nil {
+		return errors.Wrap(workspaceError, "Invalid workspace ID")
+	}
+
+	eventUserID, userError := parseID(raw.EventUserID)
+	if userError != nil {
+		return errors.Wrap(userError, "Invalid event user ID")
+	}
+
+	*metadata = Metadata{
+		Action:      raw.Action,
+		Model:       raw.Model,
+		Path:        raw.Path,
+		RequestType: raw.RequestType,
+		WorkspaceID: workspaceID,
+		EventUserID: eventUserID,
+	}
+
+	return nil
+}
+
+// Event contains a single event of a webhook subscription.
+type Event struct {
+	ID             int64     `json:"event_id"`
+	SubscriptionID int       `json:"subscription_id"`
+	CreatorID      int       `json:"creator_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Timestamp      time.Time `json:"timestamp"`
+	Metadata       Metadata  `json:"metadata"`
+
+	// Payload contains the undecoded record of the event
+	// or the string "ping" for ping events.
+	Payload json.RawMessage `json:"payload"`
+
+	// ValidationCode is only set on the request which validates a new subscription.
+	ValidationCode string `json:"validation_code"`
+}
+
+// IsPing returns true if the event has been sent by a ping of the subscription.
+func (event Event) IsPing() bool {
+	var payload string
+	return json.Unmarshal(event.Payload, &payload) == nil && payload == "ping"
+}
+
+// TimeEntryEvent contains an event of a time entry.
+type TimeEntryEvent struct {
+	Event
+	TimeEntry model.TimeEntry
+}
+
+// ProjectEvent contains an event of a project.
+type ProjectEvent struct {
+	Event
+	Project model.Project
+}
+
+// ClientEvent contains an event of a client.
+type ClientEvent struct {
+	Event
+	Client model.Client
+}
+
+// TagEvent contains an event of a tag.
+type TagEvent struct {
+	Event
+	Tag model.Tag
+}
+
+// The payloads use the field names of the current Toggl API
+// which differ from the ones of the models.
+type timeEntryPayload struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"workspace_id"`
+	ProjectID   int       `json:"project_id"`
+	UserID      int       `json:"user_id"`
+	Start       time.Time `json:"start"`
+	Stop        time.Time `json:"stop"`
+	Duration    int       `json:"duration"`
+	Billable    bool      `json:"billable"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	CreatedWith string    `json:"created_with"`
+	At          time.Time `json:"at"`
+}
+
+type projectPayload struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"workspace_id"`
+	ClientID    int       `json:"client_id"`
+	Name        string    `json:"name"`
+	At          time.Time `json:"at"`
+}
+
+type clientPayload struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"wid"`
+	Name        string    `json:"name"`
+	Notes       string    `json:"notes"`
+	At          time.Time `json:"at"`
+}
+
+// decodeTimeEntry returns the time entry contained in the payload of the given event.
+func decodeTimeEntry(event Event) (TimeEntryEvent, error) {
+	var payload timeEntryPayload
+	if err := decodePayload(event, &payload); err != nil {
+		return TimeEntryEvent{}, err
+	}
+
+	return TimeEntryEvent{event, model.TimeEntry{
+		ID:          payload.ID,
+		Wid:         payload.WorkspaceID,
+		Uid:         payload.UserID,
+		Pid:         payload.ProjectID,
+		Start:       payload.Start,
+		Stop:        payload.Stop,
+		Duration:    payload.Duration,
+		Billable:    payload.Billable,
+		Description: payload.Description,
+		Tags:        payload.Tags,
+		CreatedWith: payload.CreatedWith,
+		At:          payload.At,
+	}}, nil
+}
+
+// decodeProject returns the project contained in the payload of the given event.
+func decodeProject(event Event) (ProjectEvent, error) {
+	var payload projectPayload
+	if err := decodePayload(event, &payload); err != nil {
+		return ProjectEvent{}, err
+	}
+
+	return ProjectEvent{event, model.Project{
+		ID:          payload.ID,
+		WorkspaceID: payload.WorkspaceID,
+		ClientID:    payload.ClientID,
+		Name:        payload.Name,
+		At:          payload.At,
+	}}, nil
+}
+
+// decodeClient returns the client contained in the payload of the given event.
+func decodeClient(event Event) (ClientEvent, error) {
+	var payload clientPayload
+	if err := decodePayload(event, &payload); err != nil {
+		return ClientEvent{}, err
+	}
+
+	return ClientEvent{event, model.Client{
+		ID:          payload.ID,
+		WorkspaceID: payload.WorkspaceID,
+		Name:        payload.Name,
+		Notes:       payload.Notes,
+		At:          payload.At,
+	}}, nil
+}
+
+// decodeTag returns the tag contained in the payload of the given event.
+func decodeTag(event Event) (TagEvent, error) {
+	var tag model.Tag
+	if err := decodePayload(event, &tag); err != nil {
+		return TagEvent{}, err
+	}
+
+	return TagEvent{event, tag}, nil
+}
+
+// decodePayload deserializes the payload of the given event into the given value.
+func decodePayload(event Event, value interface{}) error {
+	if err := json.Unmarshal(event.Payload, value); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to deserialize the %s of event %d", strings.Replace(event.Metadata.Model, "_", " ", -1), event.ID))
+	}
+
+	return nil
+}
+
+// parseID returns the ID contained in the given JSON number or string.
+// Returns 0 for missing IDs.
+func parseID(data json.RawMessage) (int, error) {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
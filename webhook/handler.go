@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader contains the name of the header with the signature of the request body.
+const SignatureHeader = "X-Webhook-Signature-256"
+
+// MaxBodySize contains the maximum size of a request body in bytes.
+const MaxBodySize = 1 << 20
+
+// signaturePrefix precedes the hex encoded HMAC in the signature header.
+const signaturePrefix = "sha256="
+
+// Handler receives the events of webhook subscriptions and dispatches
+// them to the registered handler functions. Register all functions
+// before serving requests.
+type Handler struct {
+	secret string
+
+	lock        sync.RWMutex
+	events      []func(Event) error
+	pings       []func(Event) error
+	timeEntries []func(TimeEntryEvent) error
+	projects    []func(ProjectEvent) error
+	clients     []func(ClientEvent) error
+	tags        []func(TagEvent) error
+}
+
+// NewHandler creates a new handler which only accepts requests
+// signed with the secret of the subscription.
+func NewHandler(secret string) *Handler {
+	return &Handler{secret: secret}
+}
+
+// OnEvent registers a function which receives all events except pings,
+// including the ones of models without a typed event.
+func (handler *Handler) OnEvent(fn func(Event) error) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.events = append(handler.events, fn)
+}
+
+// OnPing registers a function which receives the ping events.
+func (handler *Handler) OnPing(fn func(Event) error) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.pings = append(handler.pings, fn)
+}
+
+// OnTimeEntry registers a function which receives the time entry events.
+func (handler *Handler) OnTimeEntry(fn func(TimeEntryEvent) error) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.timeEntries = append(handler.timeEntries, fn)
+}
+
+// OnProject registers a function which receives the project events.
+func (handler *Handler) OnProject(fn func(ProjectEvent) error) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.projects = append(handler.projects, fn)
+}
+
+// OnClient registers a function which receives the client events.
+func (handler *Handler) OnClient(fn func(ClientEvent) error) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.clients = append(handler.clients, fn)
+}
+
+// OnTag registers a function which receives the tag events.
+func (handler *Handler) OnTag(fn func(TagEvent) error) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.tags = append(handler.tags, fn)
+}
+
+// Dispatch passes the given event to the registered functions and stops
+// at the first error. Time entry, project, client and tag events are
+// decoded before they are passed to the typed functions.
+func (handler *Handler) Dispatch(event Event) error {
+	handler.lock.RLock()
+	defer handler.lock.RUnlock()
+
+	if event.IsPing() {
+		return call(event, handler.pings)
+	}
+
+	if err := call(event, handler.events); err != nil {
+		return err
+	}
+
+	switch event.Metadata.Model {
+	case TimeEntryModel:
+		if len(handler.timeEntries) == 0 {
+			return nil
+		}
+
+		timeEntryEvent, err := decodeTimeEntry(event)
+		if err != nil {
+			return err
+		}
+
+		for _, fn := range handler.timeEntries {
+			if err := fn(timeEntryEvent); err != nil {
+				return wrapHandlerError(err, event)
+			}
+		}
+
+	case ProjectModel:
+		if len(handler.projects) == 0 {
+			return nil
+		}
+
+		projectEvent, err := decodeProject(event)
+		if err != nil {
+			return err
+		}
+
+		for _, fn := range handler.projects {
+			if err := fn(projectEvent); err != nil {
+				return wrapHandlerError(err, event)
+			}
+		}
+
+	case ClientModel:
+		if len(handler.clients) == 0 {
+			return nil
+		}
+
+		clientEvent, err := decodeClient(event)
+		if err != nil {
+			return err
+		}
+
+		for _, fn := range handler.clients {
+			if err := fn(clientEvent); err != nil {
+				return wrapHandlerError(err, event)
+			}
+		}
+
+	case TagModel:
+		if len(handler.tags) == 0 {
+			return nil
+		}
+
+		tagEvent, err := decodeTag(event)
+		if err != nil {
+			return err
+		}
+
+		for _, fn := range handler.tags {
+			if err := fn(tagEvent); err != nil {
+				return wrapHandlerError(err, event)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP checks the signature of the request, answers validation
+// requests and dispatches all other events. Requests with an invalid
+// signature are rejected with 401, failed dispatches with 500 so that
+// Toggl delivers the event again.
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, readError := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if readError != nil {
+		http.Error(w, "Failed to read the request body", http.StatusBadRequest)
+		return
+	}
+
+	if !VerifySignature(handler.secret, body, r.Header.Get(SignatureHeader)) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var event Event
+	if unmarshalError := json.Unmarshal(body, &event); unmarshalError != nil {
+		http.Error(w, fmt.Sprintf("Failed to deserialize the event: %s", unmarshalError), http.StatusBadRequest)
+		return
+	}
+
+	// echo the code to validate a new subscription
+	if event.ValidationCode != "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			ValidationCode string `json:"validation_code"`
+		}{event.ValidationCode})
+		return
+	}
+
+	if dispatchError := handler.Dispatch(event); dispatchError != nil {
+		http.Error(w, dispatchError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Sign returns the value of the signature header for the given body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature returns true if the given signature header
+// matches the HMAC-SHA256 of the body with the given secret.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// call passes the given event to the given functions and stops at the first error.
+func call(event Event, fns []func(Event) error) error {
+	for _, fn := range fns {
+		if err := fn(event); err != nil {
+			return wrapHandlerError(err, event)
+		}
+	}
+
+	return nil
+}
+
+// wrapHandlerError adds the event to the error of a handler function.
+func wrapHandlerError(err error, event Event) error {
+	if event.IsPing() {
+		return errors.Wrap(err, fmt.Sprintf("Failed to handle the ping event %d", event.ID))
+	}
+
+	return errors.Wrap(err, fmt.Sprintf("Failed to handle the %s %s event %d", event.Metadata.Model, event.Metadata.Action, event.ID))
+}
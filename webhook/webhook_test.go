@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "s3cr3t"
+
+// timeEntryEventJSON contains a time entry event as sent by Toggl.
+const timeEntryEventJSON = `{
+  "event_id": 4711,
+  "created_at": "2016-09-06T08:00:01Z",
+  "creator_id": 5,
+  "metadata": {
+    "action": "updated",
+    "event_user_id": "5",
+    "model": "time_entry",
+    "path": "/api/v9/workspaces/1/time_entries/436694100",
+    "request_type": "PUT",
+    "workspace_id": "1"
+  },
+  "payload": {
+    "id": 436694100,
+    "workspace_id": 1,
+    "project_id": 10,
+    "user_id": 5,
+    "start": "2016-09-06T08:00:00Z",
+    "stop": "2016-09-06T09:00:00Z",
+    "duration": 3600,
+    "billable": true,
+    "description": "Code review",
+    "tags": ["review"],
+    "at": "2016-09-06T09:00:01Z"
+  },
+  "subscription_id": 17,
+  "timestamp": "2016-09-06T08:00:02Z"
+}`
+
+// post sends the given body with the given signature to the handler.
+func post(handler http.Handler, body, signature string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/toggl", strings.NewReader(body))
+	request.Header.Set(SignatureHeader, signature)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func Test_Handler_SignedTimeEntryEvent_TypedEventIsDispatched(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+
+	var received []TimeEntryEvent
+	handler.OnTimeEntry(func(event TimeEntryEvent) error {
+		received = append(received, event)
+		return nil
+	})
+
+	var projectEvents int
+	handler.OnProject(func(event ProjectEvent) error {
+		projectEvents++
+		return nil
+	})
+
+	// act
+	response := post(handler, timeEntryEventJSON, Sign(testSecret, []byte(timeEntryEventJSON)))
+
+	// assert
+	if response.Code != http.StatusOK || len(received) != 1 || projectEvents != 0 {
+		t.Fatalf("The handler should have dispatched one time entry event but responded %d and dispatched %d time entry and %d project events", response.Code, len(received), projectEvents)
+	}
+
+	event := received[0]
+	if event.ID != 4711 || event.SubscriptionID != 17 || event.Metadata.Action != Updated || event.Metadata.WorkspaceID != 1 || event.Metadata.EventUserID != 5 {
+		t.Errorf("Unexpected event %+v", event.Event)
+	}
+
+	timeEntry := event.TimeEntry
+	if timeEntry.ID != 436694100 || timeEntry.Wid != 1 || timeEntry.Pid != 10 || timeEntry.Uid != 5 || timeEntry.Duration != 3600 ||
+		!timeEntry.Billable || timeEntry.Description != "Code review" || fmt.Sprint(timeEntry.Tags) != "[review]" ||
+		!timeEntry.Stop.Equal(time.Date(2016, 9, 6, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected time entry %+v", timeEntry)
+	}
+}
+
+func Test_Handler_InvalidSignature_RequestIsRejected(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+
+	dispatched := false
+	handler.OnEvent(func(event Event) error {
+		dispatched = true
+		return nil
+	})
+
+	inputs := []string{
+		"",
+		Sign("other secret", []byte(timeEntryEventJSON)),
+		strings.TrimPrefix(Sign(testSecret, []byte(timeEntryEventJSON)), "sha256="),
+	}
+
+	for _, signature := range inputs {
+
+		// act
+		response := post(handler, timeEntryEventJSON, signature)
+
+		// assert
+		if response.Code != http.StatusUnauthorized || dispatched {
+			t.Errorf("The signature %q should have been rejected but the handler responded %d (dispatched: %t)", signature, response.Code, dispatched)
+		}
+	}
+}
+
+func Test_Handler_ValidationRequest_CodeIsEchoed(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+	body := `{"payload": "ping", "subscription_id": 17, "validation_code": "abc123", "validation_code_url": "https://example.com"}`
+
+	pings := 0
+	handler.OnPing(func(event Event) error {
+		pings++
+		return nil
+	})
+
+	// act
+	response := post(handler, body, Sign(testSecret, []byte(body)))
+
+	// assert
+	if response.Code != http.StatusOK || strings.TrimSpace(response.Body.String()) != `{"validation_code":"abc123"}` || pings != 0 {
+		t.Fail()
+		t.Logf("The handler should have echoed the validation code but responded %d %q (pings: %d)", response.Code, response.Body.String(), pings)
+	}
+}
+
+func Test_Handler_Ping_OnlyPingFunctionsAreCalled(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+	body := `{"event_id": 1, "payload": "ping", "subscription_id": 17, "metadata": {"request_type": "POST"}}`
+
+	var calls []string
+	handler.OnPing(func(event Event) error {
+		calls = append(calls, "ping")
+		return nil
+	})
+	handler.OnEvent(func(event Event) error {
+		calls = append(calls, "event")
+		return nil
+	})
+
+	// act
+	response := post(handler, body, Sign(testSecret, []byte(body)))
+
+	// assert
+	if response.Code != http.StatusOK || fmt.Sprint(calls) != "[ping]" {
+		t.Fail()
+		t.Logf("Only the ping function should have been called but the handler responded %d and called %v", response.Code, calls)
+	}
+}
+
+func Test_Dispatch_ProjectClientAndTagEvents_ModelsAreDecoded(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+
+	var received []string
+	handler.OnProject(func(event ProjectEvent) error {
+		received = append(received, fmt.Sprintf("project %d/%d/%d %s", event.Project.ID, event.Project.WorkspaceID, event.Project.ClientID, event.Project.Name))
+		return nil
+	})
+	handler.OnClient(func(event ClientEvent) error {
+		received = append(received, fmt.Sprintf("client %d/%d %s %s", event.Client.ID, event.Client.WorkspaceID, event.Client.Name, event.Client.Notes))
+		return nil
+	})
+	handler.OnTag(func(event TagEvent) error {
+		received = append(received, fmt.Sprintf("tag %d/%d %s %s", event.Tag.ID, event.Tag.WorkspaceID, event.Tag.Name, event.Metadata.Action))
+		return nil
+	})
+
+	events := []Event{
+		{Metadata: Metadata{Model: ProjectModel, Action: Created}, Payload: []byte(`{"id": 10, "workspace_id": 1, "client_id": 5, "name": "Website"}`)},
+		{Metadata: Metadata{Model: ClientModel, Action: Updated}, Payload: []byte(`{"id": 5, "wid": 1, "name": "Acme", "notes": "VIP"}`)},
+		{Metadata: Metadata{Model: TagModel, Action: Deleted}, Payload: []byte(`{"id": 3, "workspace_id": 1, "name": "review"}`)},
+		{Metadata: Metadata{Model: "workspace", Action: Updated}, Payload: []byte(`{"id": 1}`)},
+	}
+
+	// act
+	for _, event := range events {
+		if err := handler.Dispatch(event); err != nil {
+			t.Fatalf("Dispatch(%s) failed: %s", event.Metadata.Model, err)
+		}
+	}
+
+	// assert
+	expected := "[project 10/1/5 Website client 5/1 Acme VIP tag 3/1 review deleted]"
+	if fmt.Sprint(received) != expected {
+		t.Fail()
+		t.Logf("Dispatch should have passed %s but passed %s", expected, received)
+	}
+}
+
+func Test_Handler_FunctionFails_ServerErrorIsReturned(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+
+	handler.OnTimeEntry(func(event TimeEntryEvent) error {
+		return fmt.Errorf("database unavailable")
+	})
+
+	// act
+	response := post(handler, timeEntryEventJSON, Sign(testSecret, []byte(timeEntryEventJSON)))
+
+	// assert
+	expected := "Failed to handle the time_entry updated event 4711: database unavailable"
+	if response.Code != http.StatusInternalServerError || strings.TrimSpace(response.Body.String()) != expected {
+		t.Fail()
+		t.Logf("The handler should have responded 500 %q but responded %d %q", expected, response.Code, response.Body.String())
+	}
+}
+
+func Test_Handler_InvalidRequests_AreRejected(t *testing.T) {
+	// arrange
+	handler := NewHandler(testSecret)
+
+	inputs := []struct {
+		Name         string
+		Method       string
+		Body         string
+		ExpectedCode int
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"invalid JSON", http.MethodPost, "{", http.StatusBadRequest},
+		{"invalid workspace ID", http.MethodPost, `{"metadata": {"workspace_id": "abc"}}`, http.StatusBadRequest},
+		{"too large", http.MethodPost, strings.Repeat(" ", MaxBodySize+1), http.StatusBadRequest},
+	}
+
+	for _, input := range inputs {
+		request := httptest.NewRequest(input.Method, "/toggl", strings.NewReader(input.Body))
+		request.Header.Set(SignatureHeader, Sign(testSecret, []byte(input.Body)))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		// assert
+		if recorder.Code != input.ExpectedCode {
+			t.Errorf("%s: The handler should have responded %d but responded %d", input.Name, input.ExpectedCode, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package togglapi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andreaskoch/togglapi/model"
+)
+
+func Test_NewWebhookAPI(t *testing.T) {
+	// act
+	client := NewWebhookAPI(DefaultWebhookBaseURL, "sakldjaksljkl312312")
+
+	// assert
+	if client == nil {
+		t.Fail()
+		t.Logf("NewWebhookAPI should have returned a webhook API client")
+	}
+}
+
+func Test_CreateWebhookSubscription_ValidSubscription_SubscriptionIsPostedToWorkspace(t *testing.T) {
+	// arrange
+	subscriptionJSON := `{
+  "subscription_id": 17,
+  "workspace_id": 1,
+  "user_id": 5,
+  "description": "sync",
+  "url_callback": "https://example.com/toggl",
+  "secret": "generated",
+  "enabled": true,
+  "event_filters": [{"entity": "time_entry", "action": "*"}],
+  "validated_at": null,
+  "created_at": "2016-09-06T08:00:00Z"
+}`
+
+	var requestedMethod, requestedRoute, requestedBody string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			body, _ := ioutil.ReadAll(payload)
+			requestedMethod, requestedRoute, requestedBody = method, route, string(body)
+			return []byte(subscriptionJSON), nil
+		},
+	}
+
+	webhookAPI := &WebhookAPI{
+		restClient: restClient,
+	}
+
+	input := model.WebhookSubscription{
+		WorkspaceID:  1,
+		Description:  "sync",
+		URLCallback:  "https://example.com/toggl",
+		Enabled:      true,
+		EventFilters: []model.WebhookEventFilter{{Entity: "time_entry", Action: "*"}},
+	}
+
+	// act
+	subscription, err := webhookAPI.CreateWebhookSubscription(input)
+
+	// assert
+	expectedBody := `{"description":"sync","url_callback":"https://example.com/toggl","enabled":true,"event_filters":[{"entity":"time_entry","action":"*"}]}`
+	if requestedMethod != http.MethodPost || requestedRoute != "subscriptions/1" || requestedBody != expectedBody {
+		t.Errorf("CreateWebhookSubscription sent %s %s %s but should have sent POST subscriptions/1 %s", requestedMethod, requestedRoute, requestedBody, expectedBody)
+	}
+
+	if err != nil || subscription.ID != 17 || subscription.Secret != "generated" || subscription.ValidatedAt != nil {
+		t.Errorf("CreateWebhookSubscription returned %+v (error: %v)", subscription, err)
+	}
+}
+
+func Test_CreateWebhookSubscription_InvalidSubscription_NoRequestIsSent(t *testing.T) {
+	// arrange
+	requested := false
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requested = true
+			return nil, nil
+		},
+	}
+
+	webhookAPI := &WebhookAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := webhookAPI.CreateWebhookSubscription(model.WebhookSubscription{WorkspaceID: 1})
+
+	// assert
+	if _, ok := err.(*model.ValidationError); !ok || requested {
+		t.Fail()
+		t.Logf("CreateWebhookSubscription should return a validation error without sending a request but returned %v", err)
+	}
+}
+
+func Test_GetWebhookSubscriptions_InvalidJSONIsReturned_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return []byte(`dsakdlajkl,,d;; jkjk??`), nil
+		},
+	}
+
+	webhookAPI := &WebhookAPI{
+		restClient: restClient,
+	}
+
+	// act
+	_, err := webhookAPI.GetWebhookSubscriptions(1)
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), "Failed to deserialize the webhook subscriptions") {
+		t.Fail()
+		t.Logf("GetWebhookSubscriptions should return an error if the JSON returned by the API is invalid")
+	}
+}
+
+func Test_GetWebhookSubscriptions_ValidJSONIsReturned_SubscriptionsAreReturned(t *testing.T) {
+	// arrange
+	var requestedRoute string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requestedRoute = route
+			return []byte(`[{"subscription_id": 17, "workspace_id": 1, "validated_at": "2016-09-06T08:00:00Z"}, {"subscription_id": 18, "workspace_id": 1}]`), nil
+		},
+	}
+
+	webhookAPI := &WebhookAPI{
+		restClient: restClient,
+	}
+
+	// act
+	subscriptions, err := webhookAPI.GetWebhookSubscriptions(1)
+
+	// assert
+	if err != nil || requestedRoute != "subscriptions/1" || len(subscriptions) != 2 || subscriptions[0].ValidatedAt == nil {
+		t.Fail()
+		t.Logf("GetWebhookSubscriptions returned %+v from %q (error: %v)", subscriptions, requestedRoute, err)
+	}
+}
+
+func Test_PingAndDeleteWebhookSubscription_RoutesContainWorkspaceAndSubscription(t *testing.T) {
+	// arrange
+	var requests []string
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			requests = append(requests, method+" "+route)
+			return []byte(`{}`), nil
+		},
+	}
+
+	webhookAPI := &WebhookAPI{
+		restClient: restClient,
+	}
+
+	// act
+	pingError := webhookAPI.PingWebhookSubscription(1, 17)
+	deleteError := webhookAPI.DeleteWebhookSubscription(1, 17)
+
+	// assert
+	expected := "[POST ping/1/17 DELETE subscriptions/1/17]"
+	if pingError != nil || deleteError != nil || fmt.Sprint(requests) != expected {
+		t.Fail()
+		t.Logf("Expected the requests %s but got %s (errors: %v, %v)", expected, requests, pingError, deleteError)
+	}
+}
+
+func Test_DeleteWebhookSubscription_RestClientReturnsError_ErrorIsReturned(t *testing.T) {
+	// arrange
+	restClient := &mockRESTRequester{
+		request: func(method, route string, payload io.Reader) ([]byte, error) {
+			return nil, fmt.Errorf("Some error")
+		},
+	}
+
+	webhookAPI := &WebhookAPI{
+		restClient: restClient,
+	}
+
+	// act
+	err := webhookAPI.DeleteWebhookSubscription(1, 17)
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), "Failed to delete webhook subscription 17") {
+		t.Fail()
+		t.Logf("DeleteWebhookSubscription should return an error if the rest client returns one")
+	}
+}